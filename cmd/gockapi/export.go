@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/JTGlez/gockapi/internal/manager"
+)
+
+func runExport(args []string, mgr *manager.MockManager) {
+	if len(args) < 2 || args[0] != "har" {
+		log.Fatal("usage: gockapi export har <service> [--out <file>]")
+	}
+
+	flags := flag.NewFlagSet("export har", flag.ExitOnError)
+	out := flags.String("out", "", "HAR file to write (defaults to stdout)")
+
+	serviceName, rest := splitPositional(args[1:])
+	flags.Parse(rest)
+	if serviceName == "" {
+		serviceName = flags.Arg(0)
+	}
+
+	cfg, err := mgr.GetConfigReader().ReadServiceConfig(serviceName)
+	if err != nil {
+		log.Fatalf("❌ Could not read config for %s: %v", serviceName, err)
+	}
+	if cfg.Port == 0 {
+		log.Fatalf("❌ %s uses an ephemeral port and cannot be located; export it with Manager.ExportHAR instead", serviceName)
+	}
+	if cfg.GRPC != nil {
+		log.Fatalf("❌ %s is a gRPC service without an admin API; export it with Manager.ExportHAR instead", serviceName)
+	}
+
+	// The service usually runs in another process, so the journal is read
+	// through its admin API. A generated certificate is signed by that
+	// process's CA, which this one cannot verify.
+	scheme := "http"
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil {
+		scheme = "https"
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	if !cfg.ServesHTTP1() {
+		transport.Protocols = &http.Protocols{}
+		transport.Protocols.SetHTTP2(true)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	resp, err := client.Get(fmt.Sprintf("%s://localhost:%d/_admin/requests?format=har", scheme, cfg.Port))
+	if err != nil {
+		log.Fatalf("❌ Could not reach %s on port %d: %v", serviceName, cfg.Port, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("❌ %s answered the export with status %d", serviceName, resp.StatusCode)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Fatalf("❌ Failed to write HAR: %v", err)
+	}
+
+	if *out != "" {
+		log.Printf("✅ Exported the request journal of %s to %s", serviceName, *out)
+	}
+}
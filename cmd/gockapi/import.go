@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/JTGlez/gockapi/internal/har"
+	"github.com/JTGlez/gockapi/internal/manager"
+	"github.com/JTGlez/gockapi/internal/openapi"
+)
+
+func runImport(args []string, configPath string) {
+	if len(args) < 2 {
+		log.Fatal("usage: gockapi import <openapi|har> <file> [options]")
+	}
+
+	switch args[0] {
+	case "openapi":
+		importOpenAPI(args[1:], configPath)
+	case "har":
+		importHAR(args[1:], configPath)
+	default:
+		log.Fatalf("unknown import format %s", args[0])
+	}
+}
+
+func importOpenAPI(args []string, configPath string) {
+	flags := flag.NewFlagSet("import openapi", flag.ExitOnError)
+	name := flags.String("name", "", "Service name (defaults to the spec title)")
+	port := flags.Int("port", 0, "Service port (0 picks a free port)")
+	basePath := flags.String("base-path", "", "Prefix for every path (defaults to the first server URL path)")
+	out := flags.String("out", "", "Config file to write (defaults to <config-path>/<name>.json)")
+	force := flags.Bool("force", false, "Overwrite an existing config file")
+	serve := flags.Bool("serve", false, "Serve the spec directly instead of writing a config file")
+	validate := flags.Bool("validate", false, "Reject requests that do not conform to the spec")
+
+	specPath, rest := splitPositional(args)
+	flags.Parse(rest)
+	if specPath == "" {
+		specPath = flags.Arg(0)
+	}
+	if specPath == "" {
+		log.Fatal("usage: gockapi import openapi <spec> [options]")
+	}
+
+	absSpecPath, err := filepath.Abs(specPath)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	cfg, err := openapi.Import(absSpecPath, openapi.ImportOptions{
+		ServiceName:      *name,
+		Port:             *port,
+		BasePath:         *basePath,
+		ValidateRequests: *validate,
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to import %s: %v", specPath, err)
+	}
+
+	if *serve {
+		mgr := manager.NewMockManager(configPath)
+		if err := mgr.StartServiceConfig(context.Background(), cfg); err != nil {
+			log.Fatalf("❌ Failed to start %s: %v", cfg.ServiceName, err)
+		}
+		url, _ := mgr.GetServiceURL(cfg.ServiceName)
+		log.Printf("✅ Service %s serving %s at %s", cfg.ServiceName, specPath, url)
+		waitForSignal(mgr)
+		return
+	}
+
+	target := *out
+	if target == "" && configPath != "" {
+		target = filepath.Join(configPath, cfg.ServiceName+".json")
+	}
+
+	// Config files resolve a relative spec path from their own directory.
+	if cfg.RequestValidation != nil && target != "" {
+		targetDir, err := filepath.Abs(filepath.Dir(target))
+		if err == nil {
+			if relative, err := filepath.Rel(targetDir, absSpecPath); err == nil {
+				cfg.RequestValidation.Spec = relative
+			}
+		}
+	}
+
+	if err := writeServiceConfig(cfg, target, *force); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if target != "" {
+		log.Printf("✅ Imported %d endpoints from %s into %s", len(cfg.Endpoints), specPath, target)
+	}
+}
+
+func importHAR(args []string, configPath string) {
+	flags := flag.NewFlagSet("import har", flag.ExitOnError)
+	hosts := flags.String("hosts", "", "Comma-separated hosts to import (defaults to every host)")
+	port := flags.Int("port", 0, "Port of the first service, the others get the following ports (0 picks free ports)")
+	outDir := flags.String("out-dir", "", "Directory for the config files (defaults to <config-path>)")
+	force := flags.Bool("force", false, "Overwrite existing config files")
+	serve := flags.Bool("serve", false, "Serve the recorded hosts directly instead of writing config files")
+
+	harPath, rest := splitPositional(args)
+	flags.Parse(rest)
+	if harPath == "" {
+		harPath = flags.Arg(0)
+	}
+	if harPath == "" {
+		log.Fatal("usage: gockapi import har <file> [options]")
+	}
+
+	options := har.ImportOptions{BasePort: *port}
+	if *hosts != "" {
+		options.Hosts = strings.Split(*hosts, ",")
+	}
+
+	configs, err := har.Import(harPath, options)
+	if err != nil {
+		log.Fatalf("❌ Failed to import %s: %v", harPath, err)
+	}
+	if len(configs) == 0 {
+		log.Fatalf("❌ No entries of %s matched the requested hosts", harPath)
+	}
+
+	if *serve {
+		mgr := manager.NewMockManager(configPath)
+		for _, cfg := range configs {
+			if err := mgr.StartServiceConfig(context.Background(), cfg); err != nil {
+				log.Fatalf("❌ Failed to start %s: %v", cfg.ServiceName, err)
+			}
+			url, _ := mgr.GetServiceURL(cfg.ServiceName)
+			log.Printf("✅ Service %s replaying %s at %s", cfg.ServiceName, harPath, url)
+		}
+		waitForSignal(mgr)
+		return
+	}
+
+	dir := *outDir
+	if dir == "" {
+		dir = configPath
+	}
+	if dir == "" {
+		log.Fatal("❌ --out-dir or --config-path is required to write the imported services")
+	}
+
+	for _, cfg := range configs {
+		target := filepath.Join(dir, cfg.ServiceName+".json")
+		if err := writeServiceConfig(cfg, target, *force); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ Imported %d endpoints for %s into %s", len(cfg.Endpoints), cfg.ServiceName, target)
+	}
+}
+
+// splitPositional lets the file or service name come before the flags.
+func splitPositional(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+
+	return "", args
+}
+
+// writeServiceConfig writes the config as JSON to path, or to stdout when the
+// path is empty.
+func writeServiceConfig(cfg *configReader.ServiceConfig, path string, force bool) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config for %s: %w", cfg.ServiceName, err)
+	}
+
+	data = append(data, '\n')
+
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
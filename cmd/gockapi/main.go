@@ -11,20 +11,23 @@ import (
 	"syscall"
 	"time"
 
-	"path/filepath"
-
 	"github.com/JTGlez/gockapi/internal/manager"
 	"github.com/JTGlez/gockapi/internal/server/process_killer"
 )
 
 func main() {
 	configPath := flag.String("config-path", "", "Path to mock configurations directory")
+	caCert := flag.String("ca-cert", "", "File to write the CA certificate of HTTPS services to")
 	flag.Usage = printUsage
 	flag.Parse()
 
 	if *configPath == "" {
 		*configPath = os.Getenv("MOCK_CONFIG_PATH")
 	}
+	if flag.Arg(0) == "import" {
+		runImport(flag.Args()[1:], *configPath)
+		return
+	}
 	if *configPath == "" {
 		log.Fatal("config path must be provided via --config-path or MOCK_CONFIG_PATH")
 	}
@@ -46,6 +49,7 @@ func main() {
 		if err := mgr.StartAll(ctx); err != nil {
 			log.Fatalf("failed to start services: %v", err)
 		}
+		writeCACertificate(mgr, *caCert)
 		time.Sleep(1 * time.Second)
 		log.Println("🚀 All mock servers are up and running!")
 		waitForSignal(mgr)
@@ -58,32 +62,36 @@ func main() {
 			if err := mgr.StartService(ctx, svc); err != nil {
 				log.Printf("❌ Failed to start %s: %v", svc, err)
 			} else {
-				log.Printf("✅ Service %s started successfully", svc)
+				url, _ := mgr.GetServiceURL(svc)
+				log.Printf("✅ Service %s started successfully at %s", svc, url)
 			}
 		}
+		writeCACertificate(mgr, *caCert)
 		time.Sleep(1 * time.Second)
 		log.Println("🚀 All requested mock servers are up and running!")
 		waitForSignal(mgr)
 	case "stop-all":
 		// Improved logic: statelessly stop all services by scanning config directory
-		files, err := filepath.Glob(filepath.Join(*configPath, "*.json"))
+		services, err := mgr.GetConfigReader().ListServices()
 		if err != nil {
 			log.Fatalf("failed to list config files: %v", err)
 		}
-		if len(files) == 0 {
+		if len(services) == 0 {
 			log.Println("No service configs found to stop.")
 			return
 		}
 		numKilled := 0
 		errors := []string{}
-		for _, file := range files {
-			serviceName := filepath.Base(file)
-			serviceName = serviceName[:len(serviceName)-len(filepath.Ext(serviceName))]
+		for _, serviceName := range services {
 			cfg, cfgErr := mgr.GetConfigReader().ReadServiceConfig(serviceName)
 			if cfgErr != nil {
 				errors = append(errors, "❌ Could not read config for "+serviceName+": "+cfgErr.Error())
 				continue
 			}
+			if cfg.Port == 0 {
+				errors = append(errors, "⚠️  "+serviceName+" uses an ephemeral port and cannot be located by port scanning")
+				continue
+			}
 			killErr := mProcessKiller.KillProcessOnPort(cfg.Port)
 			if killErr != nil {
 				if numKilled == 0 || (killErr.Error() != "could not find process on port "+fmt.Sprint(cfg.Port)+": ") {
@@ -114,6 +122,10 @@ func main() {
 					log.Printf("❌ Could not read config for %s: %v", svc, cfgErr)
 					continue
 				}
+				if cfg.Port == 0 {
+					log.Printf("⚠️  %s uses an ephemeral port and cannot be located by port scanning", svc)
+					continue
+				}
 				if killErr := mProcessKiller.KillProcessOnPort(cfg.Port); killErr != nil {
 					log.Printf("❌ Could not kill process for %s on port %d: %v", svc, cfg.Port, killErr)
 				} else {
@@ -133,21 +145,23 @@ func main() {
 				log.Printf("failed to reload %s: %v", svc, err)
 			}
 		}
+	case "record":
+		runRecord(args, mgr)
+	case "export":
+		runExport(args, mgr)
 	case "status":
-		files, err := filepath.Glob(filepath.Join(*configPath, "*.json"))
+		services, err := mgr.GetConfigReader().ListServices()
 		if err != nil {
 			log.Fatalf("failed to list config files: %v", err)
 		}
-		if len(files) == 0 {
+		if len(services) == 0 {
 			log.Println("No service configs found.")
 			return
 		}
 		running := []string{}
-		for _, file := range files {
-			serviceName := filepath.Base(file)
-			serviceName = serviceName[:len(serviceName)-len(filepath.Ext(serviceName))]
+		for _, serviceName := range services {
 			cfg, cfgErr := mgr.GetConfigReader().ReadServiceConfig(serviceName)
-			if cfgErr != nil {
+			if cfgErr != nil || cfg.Port == 0 {
 				continue
 			}
 			address := net.JoinHostPort("localhost", fmt.Sprint(cfg.Port))
@@ -181,6 +195,27 @@ func waitForSignal(mgr *manager.MockManager) {
 	}
 }
 
+// writeCACertificate saves the CA of the HTTPS services with generated
+// certificates, so clients in other processes can trust them.
+func writeCACertificate(mgr *manager.MockManager, path string) {
+	if path == "" {
+		return
+	}
+
+	caPEM, err := mgr.CACertificate()
+	if err != nil {
+		log.Printf("❌ Failed to generate the CA certificate: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(path, caPEM, 0o644); err != nil {
+		log.Printf("❌ Failed to write the CA certificate: %v", err)
+		return
+	}
+
+	log.Printf("🔐 CA certificate written to %s", path)
+}
+
 func printUsage() {
 	log.Printf(`🔧 Mock Servers CLI Tool
 
@@ -195,8 +230,23 @@ Commands:
   stop <service>...      Stop one or more services
   reload <service>...    Reload configuration for one or more services
 
+  record <service>       Forward unmatched requests upstream and save them as endpoints
+      --target <url>, --port
+
+  import openapi <spec>  Create a service config from an OpenAPI 3 spec
+      --name, --port, --base-path, --out <file>, --force
+      --validate         Reject requests that do not conform to the spec
+      --serve            Serve the spec directly without writing a file
+
+  import har <file>      Create one service config per host from a HAR file
+      --hosts, --port, --out-dir <dir>, --force, --serve
+
+  export har <service>   Write the request journal of a running service as HAR
+      --out <file>
+
 Options:
   --config-path string   Path to mock configurations directory (env MOCK_CONFIG_PATH)
+  --ca-cert string       Write the CA certificate of HTTPS services to this file
 `)
 
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/JTGlez/gockapi/internal/manager"
+)
+
+func runRecord(args []string, mgr *manager.MockManager) {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	target := flags.String("target", "", "Base URL of the upstream to record, e.g. http://localhost:9000")
+	port := flags.Int("port", 0, "Port of a new service without a config file (0 picks a free port)")
+
+	serviceName, rest := splitPositional(args)
+	flags.Parse(rest)
+	if serviceName == "" {
+		serviceName = flags.Arg(0)
+	}
+	if serviceName == "" || *target == "" {
+		log.Fatal("usage: gockapi record <service> --target <url> [--port <port>]")
+	}
+
+	err := mgr.StartRecording(context.Background(), serviceName, manager.RecordOptions{
+		Target: *target,
+		Port:   *port,
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to record %s: %v", serviceName, err)
+	}
+
+	url, _ := mgr.GetServiceURL(serviceName)
+	log.Printf("🔴 Recording %s at %s: unmatched requests go to %s and are saved to %s", serviceName, url, *target, mgr.GetConfigReader().GetConfigPath(serviceName))
+	waitForSignal(mgr)
+}
@@ -2,8 +2,11 @@ package config_reader
 
 type ConfigReader interface {
 	ReadServiceConfig(serviceName string) (*ServiceConfig, error)
+	WriteServiceConfig(config *ServiceConfig) error
 	WatchForChanges(serviceName string, callback func(*ServiceConfig)) error
 	StopWatching(serviceName string) error
 	GetConfigPath(serviceName string) string
+	ListServices() ([]string, error)
 	ValidateConfig(config *ServiceConfig) error
+	RegisterDecoder(decoder ConfigDecoder)
 }
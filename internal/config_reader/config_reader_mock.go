@@ -13,6 +13,21 @@ func (_m *MockConfigReader) GetConfigPath(serviceName string) string {
 	return ret.String(0)
 }
 
+func (_m *MockConfigReader) RegisterDecoder(decoder ConfigDecoder) {
+	_m.Called(decoder)
+}
+
+func (_m *MockConfigReader) ListServices() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]string)
+	}
+
+	return r0, ret.Error(1)
+}
+
 func (_m *MockConfigReader) ReadServiceConfig(serviceName string) (*ServiceConfig, error) {
 	ret := _m.Called(serviceName)
 
@@ -24,6 +39,11 @@ func (_m *MockConfigReader) ReadServiceConfig(serviceName string) (*ServiceConfi
 	return r0, ret.Error(1)
 }
 
+func (_m *MockConfigReader) WriteServiceConfig(config *ServiceConfig) error {
+	ret := _m.Called(config)
+	return ret.Error(0)
+}
+
 func (_m *MockConfigReader) StopWatching(serviceName string) error {
 	ret := _m.Called(serviceName)
 	return ret.Error(0)
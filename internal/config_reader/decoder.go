@@ -0,0 +1,14 @@
+package config_reader
+
+// ConfigDecoder parses service files written in one configuration format.
+// Extensions lists the file extensions it handles, including the dot.
+type ConfigDecoder interface {
+	Extensions() []string
+	Decode(data []byte, config *ServiceConfig) error
+}
+
+// ConfigEncoder is implemented by decoders that can also write their format,
+// which is needed to save recorded endpoints back to a service file.
+type ConfigEncoder interface {
+	Encode(config *ServiceConfig) ([]byte, error)
+}
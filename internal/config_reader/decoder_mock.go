@@ -0,0 +1,19 @@
+package config_reader
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConfigDecoder struct {
+	mock.Mock
+}
+
+func (_m *MockConfigDecoder) Extensions() []string {
+	ret := _m.Called()
+	return ret.Get(0).([]string)
+}
+
+func (_m *MockConfigDecoder) Decode(data []byte, config *ServiceConfig) error {
+	ret := _m.Called(data, config)
+	return ret.Error(0)
+}
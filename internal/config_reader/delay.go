@@ -0,0 +1,322 @@
+package config_reader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delay distributions.
+const (
+	DelayFixed       = "fixed"
+	DelayUniform     = "uniform"
+	DelayNormal      = "normal"
+	DelayLogNormal   = "lognormal"
+	DelayPercentiles = "percentiles"
+)
+
+// DelayConfig is the time to wait before responding. In config files it is a
+// duration such as "250ms", a range such as "100ms-300ms" drawn uniformly, a
+// number of nanoseconds, or an object describing a distribution:
+//
+//	{"distribution": "normal", "mean": "200ms", "stddev": "50ms"}
+//	{"distribution": "lognormal", "median": "120ms", "sigma": 0.6}
+//	{"distribution": "percentiles", "percentiles": {"p50": "80ms", "p99": "1.2s"}}
+//
+// Min and Max bound every sampled delay. Percentiles are interpolated linearly,
+// from Min at p0 to Max, or the highest percentile, at p100.
+type DelayConfig struct {
+	Distribution string
+	Value        time.Duration
+	Min          time.Duration
+	Max          time.Duration
+	Mean         time.Duration
+	StdDev       time.Duration
+	Median       time.Duration
+	Sigma        float64
+	Percentiles  map[string]time.Duration
+}
+
+// FixedDelay returns a delay of exactly d.
+func FixedDelay(d time.Duration) *DelayConfig {
+	return &DelayConfig{Distribution: DelayFixed, Value: d}
+}
+
+// ParseDelay parses a duration such as "250ms" or a range such as
+// "100ms-300ms".
+func ParseDelay(text string) (*DelayConfig, error) {
+	text = strings.TrimSpace(text)
+
+	if low, high, isRange := strings.Cut(text, "-"); isRange && low != "" {
+		minDelay, err := time.ParseDuration(strings.TrimSpace(low))
+		if err != nil {
+			return nil, fmt.Errorf("invalid delay range %q: %w", text, err)
+		}
+
+		maxDelay, err := time.ParseDuration(strings.TrimSpace(high))
+		if err != nil {
+			return nil, fmt.Errorf("invalid delay range %q: %w", text, err)
+		}
+
+		return &DelayConfig{Distribution: DelayUniform, Min: minDelay, Max: maxDelay}, nil
+	}
+
+	value, err := time.ParseDuration(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delay %q: %w", text, err)
+	}
+
+	return FixedDelay(value), nil
+}
+
+// ParsePercentile converts a percentile name such as "p99" or "p99.9" into a
+// quantile between 0 and 1.
+func ParsePercentile(name string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(name), "p"), 64)
+	if err != nil || !strings.HasPrefix(strings.ToLower(name), "p") || percent <= 0 || percent >= 100 {
+		return 0, fmt.Errorf("invalid percentile %q, expected a name such as p50 or p99.9", name)
+	}
+
+	return percent / 100, nil
+}
+
+// Sample draws a delay from the distribution. Invalid configurations, which
+// the validator rejects, sample as no delay.
+func (d *DelayConfig) Sample() time.Duration {
+	if d == nil {
+		return 0
+	}
+
+	var delay time.Duration
+
+	switch d.Distribution {
+	case DelayUniform:
+		delay = d.Min
+		if d.Max > d.Min {
+			delay += time.Duration(rand.Int64N(int64(d.Max-d.Min) + 1))
+		}
+	case DelayNormal:
+		delay = d.Mean + time.Duration(rand.NormFloat64()*float64(d.StdDev))
+	case DelayLogNormal:
+		delay = time.Duration(float64(d.Median) * math.Exp(rand.NormFloat64()*d.Sigma))
+	case DelayPercentiles:
+		delay = d.samplePercentiles(rand.Float64())
+	default:
+		delay = d.Value
+	}
+
+	if d.Max > 0 && delay > d.Max {
+		delay = d.Max
+	}
+
+	return max(delay, d.Min, 0)
+}
+
+// Wait pauses for a delay drawn from the distribution and reports whether ctx
+// was still alive when it ended.
+func (d *DelayConfig) Wait(ctx context.Context) bool {
+	delay := d.Sample()
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+type quantilePoint struct {
+	quantile float64
+	delay    time.Duration
+}
+
+func (d *DelayConfig) samplePercentiles(quantile float64) time.Duration {
+	points := []quantilePoint{{quantile: 0, delay: d.Min}}
+	for name, delay := range d.Percentiles {
+		if q, err := ParsePercentile(name); err == nil {
+			points = append(points, quantilePoint{quantile: q, delay: delay})
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].quantile < points[j].quantile
+	})
+
+	last := points[len(points)-1].delay
+	if d.Max > 0 {
+		last = d.Max
+	}
+
+	points = append(points, quantilePoint{quantile: 1, delay: last})
+
+	for i := 1; i < len(points); i++ {
+		low, high := points[i-1], points[i]
+		if quantile > high.quantile {
+			continue
+		}
+
+		share := (quantile - low.quantile) / (high.quantile - low.quantile)
+
+		return low.delay + time.Duration(share*float64(high.delay-low.delay))
+	}
+
+	return last
+}
+
+// delayDocument is the object form of a DelayConfig in config files.
+type delayDocument struct {
+	Distribution string                  `json:"distribution,omitempty"`
+	Value        *jsonDuration           `json:"value,omitempty"`
+	Min          *jsonDuration           `json:"min,omitempty"`
+	Max          *jsonDuration           `json:"max,omitempty"`
+	Mean         *jsonDuration           `json:"mean,omitempty"`
+	StdDev       *jsonDuration           `json:"stddev,omitempty"`
+	Median       *jsonDuration           `json:"median,omitempty"`
+	Sigma        float64                 `json:"sigma,omitempty"`
+	Percentiles  map[string]jsonDuration `json:"percentiles,omitempty"`
+}
+
+func (d *DelayConfig) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		parsed, err := ParseDelay(text)
+		if err != nil {
+			return err
+		}
+
+		*d = *parsed
+		return nil
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		var nanoseconds jsonDuration
+		if err := json.Unmarshal(trimmed, &nanoseconds); err != nil {
+			return err
+		}
+
+		*d = *FixedDelay(time.Duration(nanoseconds))
+		return nil
+	}
+
+	var document delayDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	*d = DelayConfig{
+		Distribution: document.Distribution,
+		Value:        document.Value.duration(),
+		Min:          document.Min.duration(),
+		Max:          document.Max.duration(),
+		Mean:         document.Mean.duration(),
+		StdDev:       document.StdDev.duration(),
+		Median:       document.Median.duration(),
+		Sigma:        document.Sigma,
+	}
+
+	if d.Distribution == "" {
+		d.Distribution = DelayFixed
+	}
+
+	if len(document.Percentiles) > 0 {
+		d.Percentiles = make(map[string]time.Duration, len(document.Percentiles))
+		for name, delay := range document.Percentiles {
+			d.Percentiles[name] = time.Duration(delay)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON writes fixed delays and plain ranges in their short string form.
+func (d DelayConfig) MarshalJSON() ([]byte, error) {
+	simple := d.Mean == 0 && d.StdDev == 0 && d.Median == 0 && d.Sigma == 0 && len(d.Percentiles) == 0
+
+	switch {
+	case simple && (d.Distribution == "" || d.Distribution == DelayFixed) && d.Min == 0 && d.Max == 0:
+		return json.Marshal(d.Value.String())
+	case simple && d.Distribution == DelayUniform && d.Value == 0:
+		return json.Marshal(d.Min.String() + "-" + d.Max.String())
+	}
+
+	document := delayDocument{
+		Distribution: d.Distribution,
+		Value:        optionalDuration(d.Value),
+		Min:          optionalDuration(d.Min),
+		Max:          optionalDuration(d.Max),
+		Mean:         optionalDuration(d.Mean),
+		StdDev:       optionalDuration(d.StdDev),
+		Median:       optionalDuration(d.Median),
+		Sigma:        d.Sigma,
+	}
+
+	if len(d.Percentiles) > 0 {
+		document.Percentiles = make(map[string]jsonDuration, len(d.Percentiles))
+		for name, delay := range d.Percentiles {
+			document.Percentiles[name] = jsonDuration(delay)
+		}
+	}
+
+	return json.Marshal(document)
+}
+
+// jsonDuration is a duration written as a string such as "250ms", or as a
+// number of nanoseconds.
+type jsonDuration time.Duration
+
+func (j *jsonDuration) UnmarshalJSON(data []byte) error {
+	var nanoseconds float64
+	if err := json.Unmarshal(data, &nanoseconds); err == nil {
+		*j = jsonDuration(nanoseconds)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid duration %s, expected a string such as \"250ms\"", data)
+	}
+
+	value, err := time.ParseDuration(text)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+
+	*j = jsonDuration(value)
+
+	return nil
+}
+
+func (j jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(j).String())
+}
+
+func (j *jsonDuration) duration() time.Duration {
+	if j == nil {
+		return 0
+	}
+
+	return time.Duration(*j)
+}
+
+func optionalDuration(d time.Duration) *jsonDuration {
+	if d == 0 {
+		return nil
+	}
+
+	value := jsonDuration(d)
+
+	return &value
+}
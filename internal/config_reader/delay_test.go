@@ -0,0 +1,194 @@
+package config_reader
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *DelayConfig
+		wantErr  bool
+	}{
+		{name: "fixed", input: "250ms", expected: &DelayConfig{Distribution: DelayFixed, Value: 250 * time.Millisecond}},
+		{name: "fixed with spaces", input: " 1s ", expected: &DelayConfig{Distribution: DelayFixed, Value: time.Second}},
+		{name: "range", input: "100ms-300ms", expected: &DelayConfig{Distribution: DelayUniform, Min: 100 * time.Millisecond, Max: 300 * time.Millisecond}},
+		{name: "range with spaces", input: "1s - 2s", expected: &DelayConfig{Distribution: DelayUniform, Min: time.Second, Max: 2 * time.Second}},
+		{name: "negative duration", input: "-5ms", expected: &DelayConfig{Distribution: DelayFixed, Value: -5 * time.Millisecond}},
+		{name: "invalid", input: "soon", wantErr: true},
+		{name: "invalid range end", input: "100ms-later", wantErr: true},
+		{name: "number without unit", input: "250", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, err := ParseDelay(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, delay)
+		})
+	}
+}
+
+func TestDelayConfigUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected DelayConfig
+		wantErr  bool
+	}{
+		{name: "string", input: `"250ms"`, expected: DelayConfig{Distribution: DelayFixed, Value: 250 * time.Millisecond}},
+		{name: "nanoseconds", input: `1000000`, expected: DelayConfig{Distribution: DelayFixed, Value: time.Millisecond}},
+		{name: "range", input: `"1s-2s"`, expected: DelayConfig{Distribution: DelayUniform, Min: time.Second, Max: 2 * time.Second}},
+		{
+			name:     "normal",
+			input:    `{"distribution": "normal", "mean": "200ms", "stddev": "50ms"}`,
+			expected: DelayConfig{Distribution: DelayNormal, Mean: 200 * time.Millisecond, StdDev: 50 * time.Millisecond},
+		},
+		{
+			name:     "object without distribution",
+			input:    `{"value": "1s"}`,
+			expected: DelayConfig{Distribution: DelayFixed, Value: time.Second},
+		},
+		{
+			name:  "percentiles",
+			input: `{"distribution": "percentiles", "percentiles": {"p50": "80ms", "p99": 1200000000}}`,
+			expected: DelayConfig{Distribution: DelayPercentiles, Percentiles: map[string]time.Duration{
+				"p50": 80 * time.Millisecond,
+				"p99": 1200 * time.Millisecond,
+			}},
+		},
+		{name: "invalid string", input: `"soon"`, wantErr: true},
+		{name: "invalid field", input: `{"mean": "soon"}`, wantErr: true},
+		{name: "boolean", input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delay DelayConfig
+			err := json.Unmarshal([]byte(tt.input), &delay)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, delay)
+		})
+	}
+}
+
+func TestDelayConfigMarshalJSONRoundTrip(t *testing.T) {
+	delays := []DelayConfig{
+		*FixedDelay(250 * time.Millisecond),
+		{Distribution: DelayUniform, Min: time.Second, Max: 2 * time.Second},
+		{Distribution: DelayLogNormal, Median: 120 * time.Millisecond, Sigma: 0.6, Max: 3 * time.Second},
+		{Distribution: DelayPercentiles, Min: time.Millisecond, Percentiles: map[string]time.Duration{"p90": time.Second}},
+	}
+
+	for _, delay := range delays {
+		data, err := json.Marshal(delay)
+		require.NoError(t, err)
+
+		var decoded DelayConfig
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, delay, decoded, string(data))
+	}
+}
+
+func TestDelayConfigSampleBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay *DelayConfig
+		low   time.Duration
+		high  time.Duration
+	}{
+		{name: "nil", delay: nil, low: 0, high: 0},
+		{name: "fixed", delay: FixedDelay(250 * time.Millisecond), low: 250 * time.Millisecond, high: 250 * time.Millisecond},
+		{name: "negative fixed", delay: FixedDelay(-time.Second), low: 0, high: 0},
+		{name: "uniform", delay: &DelayConfig{Distribution: DelayUniform, Min: 100 * time.Millisecond, Max: 300 * time.Millisecond}, low: 100 * time.Millisecond, high: 300 * time.Millisecond},
+		{name: "uniform with equal bounds", delay: &DelayConfig{Distribution: DelayUniform, Min: time.Second, Max: time.Second}, low: time.Second, high: time.Second},
+		{name: "normal clamped", delay: &DelayConfig{Distribution: DelayNormal, Mean: 200 * time.Millisecond, StdDev: time.Second, Min: 50 * time.Millisecond, Max: 400 * time.Millisecond}, low: 50 * time.Millisecond, high: 400 * time.Millisecond},
+		{name: "normal never negative", delay: &DelayConfig{Distribution: DelayNormal, Mean: 0, StdDev: time.Second}, low: 0, high: time.Hour},
+		{name: "lognormal capped", delay: &DelayConfig{Distribution: DelayLogNormal, Median: 100 * time.Millisecond, Sigma: 3, Max: time.Second}, low: 0, high: time.Second},
+		{name: "percentiles", delay: &DelayConfig{Distribution: DelayPercentiles, Percentiles: map[string]time.Duration{"p50": 80 * time.Millisecond, "p99": 1200 * time.Millisecond}}, low: 0, high: 1200 * time.Millisecond},
+		{name: "percentiles with bounds", delay: &DelayConfig{Distribution: DelayPercentiles, Min: 10 * time.Millisecond, Max: 2 * time.Second, Percentiles: map[string]time.Duration{"p50": 80 * time.Millisecond}}, low: 10 * time.Millisecond, high: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 1000 {
+				sample := tt.delay.Sample()
+
+				assert.GreaterOrEqual(t, sample, tt.low)
+				assert.LessOrEqual(t, sample, tt.high)
+			}
+		})
+	}
+}
+
+func TestSamplePercentilesInterpolation(t *testing.T) {
+	delay := &DelayConfig{
+		Distribution: DelayPercentiles,
+		Min:          0,
+		Percentiles: map[string]time.Duration{
+			"p50": 100 * time.Millisecond,
+			"p90": 500 * time.Millisecond,
+		},
+	}
+
+	tests := []struct {
+		quantile float64
+		expected time.Duration
+	}{
+		{quantile: 0, expected: 0},
+		{quantile: 0.25, expected: 50 * time.Millisecond},
+		{quantile: 0.5, expected: 100 * time.Millisecond},
+		{quantile: 0.7, expected: 300 * time.Millisecond},
+		{quantile: 0.9, expected: 500 * time.Millisecond},
+		{quantile: 1, expected: 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, float64(tt.expected), float64(delay.samplePercentiles(tt.quantile)), float64(time.Microsecond), "quantile %v", tt.quantile)
+	}
+
+	delay.Max = time.Second
+	assert.InDelta(t, float64(750*time.Millisecond), float64(delay.samplePercentiles(0.95)), float64(time.Microsecond))
+}
+
+func TestParsePercentile(t *testing.T) {
+	quantile, err := ParsePercentile("p99.9")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.999, quantile, 1e-9)
+
+	for _, invalid := range []string{"99", "p0", "p100", "pmax", ""} {
+		_, err := ParsePercentile(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDelayConfigWait(t *testing.T) {
+	assert.True(t, (*DelayConfig)(nil).Wait(context.Background()))
+	assert.True(t, FixedDelay(time.Millisecond).Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	assert.False(t, FixedDelay(time.Minute).Wait(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+}
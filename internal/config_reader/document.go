@@ -0,0 +1,57 @@
+package config_reader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NormalizeDocument converts maps with non-string keys, which YAML allows,
+// into maps JSON can encode. Response codes or ports written without quotes
+// are such keys.
+func NormalizeDocument(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			typed[key] = NormalizeDocument(child)
+		}
+
+		return typed
+	case map[any]any:
+		normalized := make(map[string]any, len(typed))
+		for key, child := range typed {
+			normalized[fmt.Sprint(key)] = NormalizeDocument(child)
+		}
+
+		return normalized
+	case []any:
+		for i, child := range typed {
+			typed[i] = NormalizeDocument(child)
+		}
+
+		return typed
+	case []map[string]any:
+		normalized := make([]any, len(typed))
+		for i, child := range typed {
+			normalized[i] = NormalizeDocument(child)
+		}
+
+		return normalized
+	}
+
+	return value
+}
+
+var invalidServiceNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// ServiceNameFrom derives a valid service name from free text such as a host
+// or a document title, e.g. "api.example.com:8443" becomes
+// "api-example-com-8443". It returns an empty string when nothing is left.
+func ServiceNameFrom(text string) string {
+	name := strings.Trim(invalidServiceNameChars.ReplaceAllString(strings.TrimSpace(text), "-"), "-")
+	if len(name) > 50 {
+		name = strings.Trim(name[:50], "-")
+	}
+
+	return name
+}
@@ -0,0 +1,53 @@
+package config_reader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDocument(t *testing.T) {
+	document := map[string]any{
+		"paths": map[string]any{
+			"/pets": map[any]any{
+				200:     map[any]any{"description": "ok"},
+				"4XX":   []any{map[any]any{true: "yes"}},
+				"items": []map[string]any{{"port": 8080}},
+			},
+		},
+	}
+
+	assert.Equal(t, map[string]any{
+		"paths": map[string]any{
+			"/pets": map[string]any{
+				"200":   map[string]any{"description": "ok"},
+				"4XX":   []any{map[string]any{"true": "yes"}},
+				"items": []any{map[string]any{"port": 8080}},
+			},
+		},
+	}, NormalizeDocument(document))
+
+	assert.Equal(t, "plain", NormalizeDocument("plain"))
+	assert.Nil(t, NormalizeDocument(nil))
+}
+
+func TestServiceNameFrom(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "Pet Store API", expected: "Pet-Store-API"},
+		{text: "api.example.com:8443", expected: "api-example-com-8443"},
+		{text: "  users_v2  ", expected: "users_v2"},
+		{text: "--Orders (beta)--", expected: "Orders-beta"},
+		{text: "ÁÉÍ", expected: ""},
+		{text: "", expected: ""},
+		{text: strings.Repeat("a", 49) + "-b", expected: strings.Repeat("a", 49)},
+		{text: strings.Repeat("x", 80), expected: strings.Repeat("x", 50)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ServiceNameFrom(tt.text), tt.text)
+	}
+}
@@ -0,0 +1,58 @@
+package config_reader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseEndpointKey splits an endpoint key such as "GET /api/users?status=active"
+// into its method, path pattern and the query conditions written inline.
+func ParseEndpointKey(endpointKey string) (string, string, map[string]ValueMatcher, error) {
+	parts := strings.SplitN(endpointKey, " ", 2)
+	if len(parts) != 2 {
+		return "", "", nil, fmt.Errorf("endpoint key must be in format 'METHOD /path'")
+	}
+
+	method := parts[0]
+	path, rawQuery, hasQuery := strings.Cut(parts[1], "?")
+
+	if !hasQuery {
+		return method, path, nil, nil
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid query string in endpoint key %s: %w", endpointKey, err)
+	}
+
+	query := make(map[string]ValueMatcher, len(values))
+	for name, paramValues := range values {
+		if len(paramValues) == 1 {
+			query[name] = ValueMatcher{EqualTo: paramValues[0]}
+		} else {
+			query[name] = ValueMatcher{Values: paramValues}
+		}
+	}
+
+	return method, path, query, nil
+}
+
+// QueryMatchers merges the query conditions written inline in the endpoint key
+// with the ones declared in the endpoint's query block. The block wins on conflict.
+func (e EndpointConfig) QueryMatchers(keyQuery map[string]ValueMatcher) map[string]ValueMatcher {
+	if len(keyQuery) == 0 {
+		return e.Query
+	}
+
+	merged := make(map[string]ValueMatcher, len(keyQuery)+len(e.Query))
+	for name, matcher := range keyQuery {
+		merged[name] = matcher
+	}
+
+	for name, matcher := range e.Query {
+		merged[name] = matcher
+	}
+
+	return merged
+}
@@ -0,0 +1,79 @@
+package config_reader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpointKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		method  string
+		path    string
+		query   map[string]ValueMatcher
+		wantErr bool
+	}{
+		{name: "plain", key: "GET /api/users", method: "GET", path: "/api/users"},
+		{name: "path parameter", key: "DELETE /api/users/{id}", method: "DELETE", path: "/api/users/{id}"},
+		{
+			name:   "inline query",
+			key:    "GET /api/users?status=active",
+			method: "GET",
+			path:   "/api/users",
+			query:  map[string]ValueMatcher{"status": {EqualTo: "active"}},
+		},
+		{
+			name:   "repeated parameter",
+			key:    "GET /api/users?tag=a&tag=b&page=2",
+			method: "GET",
+			path:   "/api/users",
+			query: map[string]ValueMatcher{
+				"tag":  {Values: []string{"a", "b"}},
+				"page": {EqualTo: "2"},
+			},
+		},
+		{name: "escaped value", key: "GET /search?q=a%20b", method: "GET", path: "/search", query: map[string]ValueMatcher{"q": {EqualTo: "a b"}}},
+		{name: "missing path", key: "GET", wantErr: true},
+		{name: "invalid query", key: "GET /search?q=%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, path, query, err := ParseEndpointKey(tt.key)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.method, method)
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.query, query)
+		})
+	}
+}
+
+func TestQueryMatchers(t *testing.T) {
+	endpoint := EndpointConfig{Query: map[string]ValueMatcher{
+		"status": {Matches: "^act"},
+		"page":   {EqualTo: "1"},
+	}}
+
+	assert.Equal(t, endpoint.Query, endpoint.QueryMatchers(nil))
+
+	merged := endpoint.QueryMatchers(map[string]ValueMatcher{
+		"status": {EqualTo: "active"},
+		"sort":   {EqualTo: "name"},
+	})
+
+	assert.Equal(t, map[string]ValueMatcher{
+		"status": {Matches: "^act"},
+		"page":   {EqualTo: "1"},
+		"sort":   {EqualTo: "name"},
+	}, merged)
+	assert.Len(t, endpoint.Query, 2, "the endpoint's own block is not modified")
+}
@@ -0,0 +1,78 @@
+package config_reader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// GRPCConfig serves a service over gRPC instead of HTTP. DescriptorSet is a
+// protobuf FileDescriptorSet declaring the services, as written by
+// protoc --include_imports --descriptor_set_out. Methods maps full method
+// names such as "payments.v1.Payments/Charge" to their responses, tried in
+// order until one matches the call.
+type GRPCConfig struct {
+	DescriptorSet string                          `json:"descriptor_set"`
+	Methods       map[string][]GRPCResponseConfig `json:"methods"`
+}
+
+// GRPCResponseConfig answers a gRPC call. Match.Headers applies to the request
+// metadata and Match.Body to the request message in JSON, or to the array of
+// messages of a client streaming call. Response is the JSON form of the
+// response message and Stream the messages a server streaming call sends, in
+// order. Code and Message set the status, OK by default, and Headers and
+// Trailers the response metadata.
+//
+// Each message of a bidirectional streaming call is matched on its own and
+// answered with Response or Stream; a status other than OK ends the call.
+type GRPCResponseConfig struct {
+	Match    MatchConfig         `json:"match,omitzero"`
+	Code     string              `json:"code,omitempty"`
+	Message  string              `json:"message,omitempty"`
+	Response any                 `json:"response,omitempty"`
+	Stream   []GRPCMessageConfig `json:"stream,omitempty"`
+	Headers  map[string]string   `json:"headers,omitempty"`
+	Trailers map[string]string   `json:"trailers,omitempty"`
+	Delay    *DelayConfig        `json:"delay,omitempty"`
+}
+
+// StatusCode returns the status code of the response, named like "NOT_FOUND"
+// in any case or given as a number.
+func (g GRPCResponseConfig) StatusCode() (codes.Code, error) {
+	if g.Code == "" {
+		return codes.OK, nil
+	}
+
+	var code codes.Code
+	if _, err := strconv.ParseUint(g.Code, 10, 32); err == nil {
+		if err := code.UnmarshalJSON([]byte(g.Code)); err == nil {
+			return code, nil
+		}
+	}
+
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(g.Code)))); err != nil {
+		return 0, fmt.Errorf("invalid grpc status code: %s", g.Code)
+	}
+
+	return code, nil
+}
+
+// GRPCMessageConfig is a message of a server stream, sent after Delay.
+type GRPCMessageConfig struct {
+	Message any          `json:"message"`
+	Delay   *DelayConfig `json:"delay,omitempty"`
+}
+
+// ParseGRPCMethod splits a full method name such as
+// "payments.v1.Payments/Charge", with or without a leading slash, into the
+// service and method names.
+func ParseGRPCMethod(name string) (service, method string, ok bool) {
+	service, method, ok = strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", false
+	}
+
+	return service, method, true
+}
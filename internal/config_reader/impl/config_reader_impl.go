@@ -1,11 +1,12 @@
 package impl
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,8 +16,10 @@ import (
 type ConfigReaderImpl struct {
 	BasePath  string
 	Validator configReader.ValidatorConfig
+	Decoders  []configReader.ConfigDecoder
 	Watchers  map[string]*FileWatcher
 	mu        sync.RWMutex
+	decoderMu sync.RWMutex
 }
 
 type FileWatcher struct {
@@ -31,10 +34,20 @@ type FileWatcher struct {
 func NewConfigReader(basePath string) configReader.ConfigReader {
 	return &ConfigReaderImpl{
 		BasePath: basePath,
+		Decoders: []configReader.ConfigDecoder{JSONDecoder{}, YAMLDecoder{}, TOMLDecoder{}},
 		Watchers: make(map[string]*FileWatcher),
 	}
 }
 
+// RegisterDecoder adds support for another configuration format. Decoders
+// registered later take precedence for the extensions they share.
+func (c *ConfigReaderImpl) RegisterDecoder(decoder configReader.ConfigDecoder) {
+	c.decoderMu.Lock()
+	defer c.decoderMu.Unlock()
+
+	c.Decoders = append([]configReader.ConfigDecoder{decoder}, c.Decoders...)
+}
+
 func (c *ConfigReaderImpl) ReadServiceConfig(serviceName string) (*configReader.ServiceConfig, error) {
 	configPath := c.GetConfigPath(serviceName)
 
@@ -47,8 +60,13 @@ func (c *ConfigReaderImpl) ReadServiceConfig(serviceName string) (*configReader.
 		return nil, fmt.Errorf("failed to read config file for service %s: %w", serviceName, err)
 	}
 
+	decoder := c.decoderFor(configPath)
+	if decoder == nil {
+		return nil, fmt.Errorf("unsupported config format for service %s: %s", serviceName, filepath.Ext(configPath))
+	}
+
 	var config configReader.ServiceConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := decoder.Decode(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config for service %s: %w", serviceName, err)
 	}
 
@@ -56,11 +74,115 @@ func (c *ConfigReaderImpl) ReadServiceConfig(serviceName string) (*configReader.
 		return nil, fmt.Errorf("config validation failed for service %s: %w", serviceName, err)
 	}
 
+	for _, path := range filePaths(&config) {
+		if *path == "" || filepath.IsAbs(*path) {
+			continue
+		}
+
+		resolved, err := filepath.Abs(filepath.Join(c.BasePath, *path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s for service %s: %w", *path, serviceName, err)
+		}
+
+		*path = resolved
+	}
+
 	log.Printf("Config for %s loaded\n", serviceName)
 
 	return &config, nil
 }
 
+// WriteServiceConfig saves the config to the service file, in the format of
+// the existing file or as JSON for a new service. File paths are written
+// relative to the base path, the way ReadServiceConfig resolves them.
+func (c *ConfigReaderImpl) WriteServiceConfig(config *configReader.ServiceConfig) error {
+	if err := c.ValidateConfig(config); err != nil {
+		return fmt.Errorf("config validation failed for service %s: %w", config.ServiceName, err)
+	}
+
+	configPath := c.GetConfigPath(config.ServiceName)
+
+	encoder, ok := c.decoderFor(configPath).(configReader.ConfigEncoder)
+	if !ok {
+		return fmt.Errorf("cannot write %s configs for service %s", filepath.Ext(configPath), config.ServiceName)
+	}
+
+	toWrite := *config
+	if config.RequestValidation != nil {
+		validation := *config.RequestValidation
+		toWrite.RequestValidation = &validation
+	}
+
+	if config.GRPC != nil {
+		grpcConfig := *config.GRPC
+		toWrite.GRPC = &grpcConfig
+	}
+
+	if config.TLS != nil {
+		tlsConfig := *config.TLS
+		toWrite.TLS = &tlsConfig
+
+		if config.TLS.ClientAuth != nil {
+			clientAuth := *config.TLS.ClientAuth
+			tlsConfig.ClientAuth = &clientAuth
+		}
+	}
+
+	for _, path := range filePaths(&toWrite) {
+		if !filepath.IsAbs(*path) {
+			continue
+		}
+
+		if relative, err := relativeTo(c.BasePath, *path); err == nil {
+			*path = relative
+		}
+	}
+
+	data, err := encoder.Encode(&toWrite)
+	if err != nil {
+		return fmt.Errorf("failed to encode config for service %s: %w", config.ServiceName, err)
+	}
+
+	if err := os.WriteFile(configPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write config file for service %s: %w", config.ServiceName, err)
+	}
+
+	return nil
+}
+
+// filePaths returns the paths in config that are relative to the config
+// directory in files.
+func filePaths(config *configReader.ServiceConfig) []*string {
+	paths := []*string{}
+
+	if config.RequestValidation != nil {
+		paths = append(paths, &config.RequestValidation.Spec)
+	}
+
+	if config.TLS != nil {
+		paths = append(paths, &config.TLS.CertFile, &config.TLS.KeyFile, &config.TLS.CAFile)
+
+		if config.TLS.ClientAuth != nil {
+			paths = append(paths, &config.TLS.ClientAuth.CAFile)
+		}
+	}
+
+	if config.GRPC != nil {
+		paths = append(paths, &config.GRPC.DescriptorSet)
+	}
+
+	return paths
+}
+
+func relativeTo(basePath, target string) (string, error) {
+	absBasePath, err := filepath.Abs(basePath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Rel(absBasePath, target)
+}
+
 func (c *ConfigReaderImpl) WatchForChanges(serviceName string, callback func(*configReader.ServiceConfig)) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -107,10 +229,63 @@ func (c *ConfigReaderImpl) StopWatching(serviceName string) error {
 	return nil
 }
 
+// GetConfigPath returns the file that configures the service, trying every
+// supported extension in decoder order. Extensions match in any case, as in
+// ListServices. When none exists it returns the JSON path.
 func (c *ConfigReaderImpl) GetConfigPath(serviceName string) string {
+	extensions := c.extensions()
+
+	for _, extension := range extensions {
+		configPath := filepath.Join(c.BasePath, serviceName+extension)
+		if _, err := os.Stat(configPath); err == nil {
+			return configPath
+		}
+	}
+
+	if entries, err := os.ReadDir(c.BasePath); err == nil {
+		for _, extension := range extensions {
+			for _, entry := range entries {
+				entryExtension := filepath.Ext(entry.Name())
+				if !entry.IsDir() && strings.ToLower(entryExtension) == extension && strings.TrimSuffix(entry.Name(), entryExtension) == serviceName {
+					return filepath.Join(c.BasePath, entry.Name())
+				}
+			}
+		}
+	}
+
 	return filepath.Join(c.BasePath, serviceName+".json")
 }
 
+// ListServices returns the names of the services configured in the base path,
+// in any supported format.
+func (c *ConfigReaderImpl) ListServices() ([]string, error) {
+	entries, err := os.ReadDir(c.BasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config files: %w", err)
+	}
+
+	extensions := c.extensions()
+	services := []string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if !slices.Contains(extensions, extension) {
+			continue
+		}
+
+		serviceName := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if !slices.Contains(services, serviceName) {
+			services = append(services, serviceName)
+		}
+	}
+
+	return services, nil
+}
+
 func (c *ConfigReaderImpl) ValidateConfig(config *configReader.ServiceConfig) error {
 	if c.Validator == nil {
 		c.Validator = NewConfigValidator()
@@ -119,6 +294,32 @@ func (c *ConfigReaderImpl) ValidateConfig(config *configReader.ServiceConfig) er
 	return c.Validator.Validate(config)
 }
 
+func (c *ConfigReaderImpl) extensions() []string {
+	c.decoderMu.RLock()
+	defer c.decoderMu.RUnlock()
+
+	extensions := []string{}
+	for _, decoder := range c.Decoders {
+		extensions = append(extensions, decoder.Extensions()...)
+	}
+
+	return extensions
+}
+
+func (c *ConfigReaderImpl) decoderFor(configPath string) configReader.ConfigDecoder {
+	c.decoderMu.RLock()
+	defer c.decoderMu.RUnlock()
+
+	extension := strings.ToLower(filepath.Ext(configPath))
+	for _, decoder := range c.Decoders {
+		if slices.Contains(decoder.Extensions(), extension) {
+			return decoder
+		}
+	}
+
+	return nil
+}
+
 func (c *ConfigReaderImpl) watchFile(watcher *FileWatcher) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -0,0 +1,52 @@
+package impl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tabDecoder struct{}
+
+func (d tabDecoder) Extensions() []string {
+	return []string{".tab"}
+}
+
+func (d tabDecoder) Decode(data []byte, config *configReader.ServiceConfig) error {
+	return json.Unmarshal(data, config)
+}
+
+func TestConfigPathsMatchListedServices(t *testing.T) {
+	basePath := t.TempDir()
+
+	files := map[string]string{
+		"upper.YAML":  "service_name: upper\nport: 55001\nendpoints:\n  GET /a:\n    status_code: 200\n",
+		"lower.json":  `{"service_name": "lower", "port": 55002, "endpoints": {"GET /a": {"status_code": 200}}}`,
+		"custom.Tab":  `{"service_name": "custom", "port": 55003, "endpoints": {"GET /a": {"status_code": 200}}}`,
+		"ignored.txt": "not a config",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(basePath, name), []byte(content), 0o644))
+	}
+
+	var reader configReader.ConfigReader = NewConfigReader(basePath)
+	reader.RegisterDecoder(tabDecoder{})
+
+	services, err := reader.ListServices()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"upper", "lower", "custom"}, services)
+
+	for _, serviceName := range services {
+		cfg, err := reader.ReadServiceConfig(serviceName)
+		require.NoError(t, err, serviceName)
+		assert.Equal(t, serviceName, cfg.ServiceName)
+	}
+
+	assert.Equal(t, filepath.Join(basePath, "upper.YAML"), reader.GetConfigPath("upper"))
+	assert.Equal(t, filepath.Join(basePath, "missing.json"), reader.GetConfigPath("missing"))
+}
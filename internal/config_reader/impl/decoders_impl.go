@@ -0,0 +1,143 @@
+package impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"gopkg.in/yaml.v3"
+)
+
+type JSONDecoder struct{}
+
+func (d JSONDecoder) Extensions() []string {
+	return []string{".json"}
+}
+
+func (d JSONDecoder) Decode(data []byte, config *configReader.ServiceConfig) error {
+	return json.Unmarshal(data, config)
+}
+
+func (d JSONDecoder) Encode(config *configReader.ServiceConfig) ([]byte, error) {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+type YAMLDecoder struct{}
+
+func (d YAMLDecoder) Extensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+func (d YAMLDecoder) Decode(data []byte, config *configReader.ServiceConfig) error {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	return decodeDocument(document, config)
+}
+
+func (d YAMLDecoder) Encode(config *configReader.ServiceConfig) ([]byte, error) {
+	document, err := encodeDocument(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(document)
+}
+
+type TOMLDecoder struct{}
+
+func (d TOMLDecoder) Extensions() []string {
+	return []string{".toml"}
+}
+
+func (d TOMLDecoder) Decode(data []byte, config *configReader.ServiceConfig) error {
+	var document map[string]any
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	return decodeDocument(document, config)
+}
+
+func (d TOMLDecoder) Encode(config *configReader.ServiceConfig) ([]byte, error) {
+	document, err := encodeDocument(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := toml.NewEncoder(&buffer).Encode(document); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// encodeDocument converts a ServiceConfig into a generic document through its
+// JSON form, the inverse of decodeDocument.
+func encodeDocument(config *configReader.ServiceConfig) (any, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return restoreNumbers(document), nil
+}
+
+// restoreNumbers turns JSON numbers into integers where possible, so formats
+// with distinct integer and float types keep "status_code = 200".
+func restoreNumbers(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			typed[key] = restoreNumbers(child)
+		}
+
+		return typed
+	case []any:
+		for i, child := range typed {
+			typed[i] = restoreNumbers(child)
+		}
+
+		return typed
+	case json.Number:
+		if integer, err := typed.Int64(); err == nil {
+			return integer
+		}
+
+		float, _ := typed.Float64()
+
+		return float
+	}
+
+	return value
+}
+
+// decodeDocument converts a generic document into a ServiceConfig through its
+// JSON form, so every format shares the JSON field names and custom decoding
+// rules such as single-object endpoints and string query matchers.
+func decodeDocument(document any, config *configReader.ServiceConfig) error {
+	data, err := json.Marshal(configReader.NormalizeDocument(document))
+	if err != nil {
+		return fmt.Errorf("unsupported value: %w", err)
+	}
+
+	return json.Unmarshal(data, config)
+}
@@ -0,0 +1,156 @@
+package impl
+
+import (
+	"testing"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const decoderTestJSON = `{
+  "service_name": "users",
+  "port": 55001,
+  "endpoints": {
+    "GET /users": {"status_code": 200, "body": [{"id": 1, "name": "Ada"}], "query": {"page": "1"}},
+    "POST /users": [
+      {"status_code": 409, "match": {"headers": {"X-Conflict": {"present": true}}}},
+      {"status_code": 201, "headers": {"Content-Type": "application/json"}, "delay": "20ms"}
+    ]
+  }
+}`
+
+const decoderTestYAML = `
+service_name: users
+port: 55001
+endpoints:
+  GET /users:
+    status_code: 200
+    body:
+      - id: 1
+        name: Ada
+    query:
+      page: "1"
+  POST /users:
+    - status_code: 409
+      match:
+        headers:
+          X-Conflict:
+            present: true
+    - status_code: 201
+      headers:
+        Content-Type: application/json
+      delay: 20ms
+`
+
+const decoderTestTOML = `
+service_name = "users"
+port = 55001
+
+[endpoints."GET /users"]
+status_code = 200
+body = [{ id = 1, name = "Ada" }]
+query = { page = "1" }
+
+[[endpoints."POST /users"]]
+status_code = 409
+match = { headers = { X-Conflict = { present = true } } }
+
+[[endpoints."POST /users"]]
+status_code = 201
+headers = { Content-Type = "application/json" }
+delay = "20ms"
+`
+
+func TestDecodersAgree(t *testing.T) {
+	var want configReader.ServiceConfig
+	require.NoError(t, JSONDecoder{}.Decode([]byte(decoderTestJSON), &want))
+	require.Len(t, want.Endpoints["POST /users"], 2)
+	require.NotNil(t, want.Endpoints["POST /users"][1].Delay)
+	require.Equal(t, 20*time.Millisecond, want.Endpoints["POST /users"][1].Delay.Value)
+
+	tests := []struct {
+		name    string
+		decoder configReader.ConfigDecoder
+		data    string
+	}{
+		{name: "yaml", decoder: YAMLDecoder{}, data: decoderTestYAML},
+		{name: "toml", decoder: TOMLDecoder{}, data: decoderTestTOML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got configReader.ServiceConfig
+			require.NoError(t, tt.decoder.Decode([]byte(tt.data), &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestYAMLDecoderNonStringKeys(t *testing.T) {
+	data := `
+service_name: users
+port: 55001
+endpoints:
+  GET /users:
+    status_code: 200
+    headers:
+      200: ok
+`
+
+	var cfg configReader.ServiceConfig
+	require.NoError(t, YAMLDecoder{}.Decode([]byte(data), &cfg))
+	assert.Equal(t, map[string]string{"200": "ok"}, cfg.Endpoints["GET /users"][0].Headers)
+}
+
+func TestDecoderRoundTrip(t *testing.T) {
+	var want configReader.ServiceConfig
+	require.NoError(t, JSONDecoder{}.Decode([]byte(decoderTestJSON), &want))
+
+	tests := []struct {
+		name         string
+		decoder      configReader.ConfigDecoder
+		wantContains string
+	}{
+		{name: "json", decoder: JSONDecoder{}, wantContains: `"status_code": 201`},
+		{name: "yaml", decoder: YAMLDecoder{}, wantContains: "status_code: 201"},
+		{name: "toml", decoder: TOMLDecoder{}, wantContains: "status_code = 201"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, ok := tt.decoder.(configReader.ConfigEncoder)
+			require.True(t, ok)
+
+			data, err := encoder.Encode(&want)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), tt.wantContains)
+
+			var got configReader.ServiceConfig
+			require.NoError(t, tt.decoder.Decode(data, &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoder configReader.ConfigDecoder
+		data    string
+	}{
+		{name: "json syntax", decoder: JSONDecoder{}, data: `{"service_name": `},
+		{name: "yaml syntax", decoder: YAMLDecoder{}, data: "service_name: [users"},
+		{name: "toml syntax", decoder: TOMLDecoder{}, data: `service_name = `},
+		{name: "yaml wrong type", decoder: YAMLDecoder{}, data: "port: fifty"},
+		{name: "toml wrong type", decoder: TOMLDecoder{}, data: `port = "fifty"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg configReader.ServiceConfig
+			assert.Error(t, tt.decoder.Decode([]byte(tt.data), &cfg))
+		})
+	}
+}
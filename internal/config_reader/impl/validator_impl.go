@@ -2,9 +2,14 @@ package impl
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	jsonPath "github.com/JTGlez/gockapi/internal/json_path"
 )
 
 type ValidatorConfigImpl struct {
@@ -48,21 +53,75 @@ func (v ValidatorConfigImpl) Validate(config *configReader.ServiceConfig) error
 		return err
 	}
 
-	if len(config.Endpoints) == 0 {
-		return fmt.Errorf("at least one endpoint must be configured")
+	if config.GRPC != nil {
+		return v.validateGRPC(config)
 	}
 
-	for endpointKey, endpointConfig := range config.Endpoints {
-		parts := strings.SplitN(endpointKey, " ", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("endpoint key must be in format 'METHOD /path'")
+	if len(config.Endpoints) == 0 && config.Fallback == nil {
+		return fmt.Errorf("at least one endpoint or a fallback must be configured")
+	}
+
+	for scenarioName, scenario := range config.Scenarios {
+		if scenarioName == "" {
+			return fmt.Errorf("scenario name cannot be empty")
 		}
 
-		method, path := parts[0], parts[1]
+		if scenario.InitialState == "" {
+			return fmt.Errorf("scenario %s must declare an initial_state", scenarioName)
+		}
+
+		if !scenario.AllowsState(scenario.InitialState) {
+			return fmt.Errorf("initial state %s is not declared for scenario %s", scenario.InitialState, scenarioName)
+		}
+	}
+
+	if err := v.validateRequestValidation(config.RequestValidation); err != nil {
+		return err
+	}
+
+	if err := v.validateFallback(config.Fallback); err != nil {
+		return err
+	}
+
+	if err := v.validateFault(config.Fault); err != nil {
+		return err
+	}
+
+	if err := v.validateDelay(config.Delay); err != nil {
+		return err
+	}
+
+	if err := v.validateTLS(config.TLS); err != nil {
+		return err
+	}
+
+	if err := v.validateProtocols(config.Protocols, config.TLS != nil); err != nil {
+		return err
+	}
+
+	for endpointKey, variants := range config.Endpoints {
+		method, path, keyQuery, err := configReader.ParseEndpointKey(endpointKey)
+		if err != nil {
+			return err
+		}
 
-		if err := v.ValidateEndpoint(method, path, endpointConfig); err != nil {
+		if err := v.validateValueMatchers("query parameter", keyQuery); err != nil {
 			return fmt.Errorf("invalid endpoint %s: %w", endpointKey, err)
 		}
+
+		if len(variants) == 0 {
+			return fmt.Errorf("invalid endpoint %s: at least one response must be configured", endpointKey)
+		}
+
+		for _, endpointConfig := range variants {
+			if err := v.ValidateEndpoint(method, path, endpointConfig); err != nil {
+				return fmt.Errorf("invalid endpoint %s: %w", endpointKey, err)
+			}
+
+			if err := v.validateScenarioStep(config.Scenarios, endpointConfig); err != nil {
+				return fmt.Errorf("invalid endpoint %s: %w", endpointKey, err)
+			}
+		}
 	}
 
 	return nil
@@ -86,9 +145,15 @@ func (v ValidatorConfigImpl) ValidateServiceName(serviceName string) error {
 	return nil
 }
 
+// ValidatePort accepts ports within the configured range, or 0 to let the port
+// manager pick a free port.
 func (v ValidatorConfigImpl) ValidatePort(port int) error {
-	if port <= 0 {
-		return fmt.Errorf("port must be positive")
+	if port < 0 {
+		return fmt.Errorf("port cannot be negative")
+	}
+
+	if port == 0 {
+		return nil
 	}
 
 	if port < v.validPortRange.minPort || port > v.validPortRange.maxPort {
@@ -111,15 +176,73 @@ func (v ValidatorConfigImpl) ValidateEndpoint(method, path string, endpoint conf
 		return fmt.Errorf("path must start with /")
 	}
 
-	if endpoint.StatusCode <= 0 {
+	if len(endpoint.Responses) == 0 {
+		if err := v.validateStatusCode(endpoint.StatusCode); err != nil {
+			return err
+		}
+	} else if err := v.validateResponseSequence(endpoint); err != nil {
+		return err
+	}
+
+	if err := v.validateResponseHeaders(endpoint.Headers); err != nil {
+		return err
+	}
+
+	if err := v.validateFault(endpoint.Fault); err != nil {
+		return err
+	}
+
+	if err := v.validateDelay(endpoint.Delay); err != nil {
+		return err
+	}
+
+	if err := v.validateValueMatchers("query parameter", endpoint.Query); err != nil {
+		return err
+	}
+
+	if err := v.validateValueMatchers("header", endpoint.Match.Headers); err != nil {
+		return err
+	}
+
+	for field := range endpoint.Match.ClientCert {
+		if !slices.Contains(configReader.ClientCertFields, field) {
+			return fmt.Errorf("invalid client certificate field: %s", field)
+		}
+	}
+
+	if err := v.validateValueMatchers("client certificate field", endpoint.Match.ClientCert); err != nil {
+		return err
+	}
+
+	if protocol := endpoint.Match.Protocol; protocol != nil {
+		if err := v.validateValueMatchers("request", map[string]configReader.ValueMatcher{"protocol": *protocol}); err != nil {
+			return err
+		}
+	}
+
+	for _, bodyMatcher := range endpoint.Match.Body {
+		if err := v.validateBodyMatcher(bodyMatcher); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateStatusCode(statusCode int) error {
+	if statusCode <= 0 {
 		return fmt.Errorf("status code must be positive")
 	}
 
-	if endpoint.StatusCode < 100 || endpoint.StatusCode >= 600 {
+	if statusCode < 100 || statusCode >= 600 {
 		return fmt.Errorf("status code must be between 100 and 599")
 	}
 
-	for headerName, headerValue := range endpoint.Headers {
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateResponseHeaders(headers map[string]string) error {
+	for headerName := range headers {
 		if headerName == "" {
 			return fmt.Errorf("header name cannot be empty")
 		}
@@ -127,8 +250,433 @@ func (v ValidatorConfigImpl) ValidateEndpoint(method, path string, endpoint conf
 		if strings.ContainsAny(headerName, " \t\n\r") {
 			return fmt.Errorf("header name cannot contain whitespace: %s", headerName)
 		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateResponseSequence(endpoint configReader.EndpointConfig) error {
+	switch endpoint.SequenceMode {
+	case "", configReader.SequenceStickOnLast, configReader.SequenceCycle, configReader.SequenceRandom:
+	case configReader.SequenceWeighted:
+		totalWeight := 0
+		for _, response := range endpoint.Responses {
+			if response.Weight < 0 {
+				return fmt.Errorf("response weight cannot be negative")
+			}
+
+			totalWeight += response.Weight
+		}
+
+		if totalWeight == 0 {
+			return fmt.Errorf("weighted sequence needs at least one response with a positive weight")
+		}
+	default:
+		return fmt.Errorf("invalid sequence mode: %s", endpoint.SequenceMode)
+	}
+
+	for i, response := range endpoint.Responses {
+		if err := v.validateStatusCode(response.StatusCode); err != nil {
+			return fmt.Errorf("response %d: %w", i, err)
+		}
+
+		if err := v.validateResponseHeaders(response.Headers); err != nil {
+			return fmt.Errorf("response %d: %w", i, err)
+		}
+
+		if err := v.validateFault(response.Fault); err != nil {
+			return fmt.Errorf("response %d: %w", i, err)
+		}
+
+		if err := v.validateDelay(response.Delay); err != nil {
+			return fmt.Errorf("response %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateFault(fault *configReader.FaultConfig) error {
+	if fault == nil {
+		return nil
+	}
+
+	switch fault.Type {
+	case configReader.FaultNone, configReader.FaultConnectionReset, configReader.FaultEmptyReply,
+		configReader.FaultCloseMidBody, configReader.FaultMalformedChunk, configReader.FaultRandomGarbage,
+		configReader.FaultSlowBody:
+	case "":
+		return fmt.Errorf("fault must set a type")
+	default:
+		return fmt.Errorf("invalid fault type: %s", fault.Type)
+	}
+
+	if fault.Probability < 0 || fault.Probability > 1 {
+		return fmt.Errorf("fault probability must be between 0 and 1")
+	}
+
+	if fault.BytesPerSecond < 0 {
+		return fmt.Errorf("fault bytes_per_second cannot be negative")
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateDelay(delay *configReader.DelayConfig) error {
+	if delay == nil {
+		return nil
+	}
+
+	for _, value := range []time.Duration{delay.Value, delay.Min, delay.Max, delay.Mean, delay.StdDev, delay.Median} {
+		if value < 0 {
+			return fmt.Errorf("delay cannot be negative")
+		}
+	}
+
+	if delay.Max > 0 && delay.Max < delay.Min {
+		return fmt.Errorf("delay max must not be lower than min")
+	}
+
+	switch delay.Distribution {
+	case "", configReader.DelayFixed:
+	case configReader.DelayUniform:
+		if delay.Max == 0 {
+			return fmt.Errorf("uniform delay must set a max")
+		}
+	case configReader.DelayNormal:
+		if delay.Mean == 0 {
+			return fmt.Errorf("normal delay must set a mean")
+		}
+	case configReader.DelayLogNormal:
+		if delay.Median == 0 {
+			return fmt.Errorf("lognormal delay must set a median")
+		}
+
+		if delay.Sigma < 0 {
+			return fmt.Errorf("lognormal delay sigma cannot be negative")
+		}
+	case configReader.DelayPercentiles:
+		return v.validateDelayPercentiles(delay)
+	default:
+		return fmt.Errorf("invalid delay distribution: %s", delay.Distribution)
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateDelayPercentiles(delay *configReader.DelayConfig) error {
+	if len(delay.Percentiles) == 0 {
+		return fmt.Errorf("percentiles delay must set at least one percentile")
+	}
+
+	quantiles := make(map[float64]time.Duration, len(delay.Percentiles))
+	for name, value := range delay.Percentiles {
+		quantile, err := configReader.ParsePercentile(name)
+		if err != nil {
+			return err
+		}
+
+		if value < delay.Min || (delay.Max > 0 && value > delay.Max) {
+			return fmt.Errorf("percentile %s must be between the delay min and max", name)
+		}
+
+		quantiles[quantile] = value
+	}
+
+	for quantile, value := range quantiles {
+		for other, otherValue := range quantiles {
+			if other > quantile && otherValue < value {
+				return fmt.Errorf("delay percentiles must not decrease")
+			}
+		}
+	}
+
+	return nil
+}
 
-		_ = headerValue
+func (v ValidatorConfigImpl) validateTLS(tlsConfig *configReader.TLSConfig) error {
+	if tlsConfig == nil {
+		return nil
+	}
+
+	if (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		return fmt.Errorf("tls must set both cert_file and key_file, or neither")
+	}
+
+	if tlsConfig.CAFile != "" && tlsConfig.CertFile == "" {
+		return fmt.Errorf("tls ca_file needs a cert_file")
+	}
+
+	for _, host := range tlsConfig.Hosts {
+		if host == "" || strings.ContainsAny(host, " \t/") {
+			return fmt.Errorf("invalid tls host: %q", host)
+		}
+	}
+
+	if clientAuth := tlsConfig.ClientAuth; clientAuth != nil {
+		for _, response := range []*configReader.ErrorResponseConfig{clientAuth.MissingResponse, clientAuth.InvalidResponse} {
+			if response == nil {
+				continue
+			}
+
+			if clientAuth.RejectHandshake {
+				return fmt.Errorf("tls client_auth cannot set error responses when it rejects the handshake")
+			}
+
+			if err := v.validateStatusCode(response.StatusCode); err != nil {
+				return fmt.Errorf("tls client_auth: %w", err)
+			}
+
+			if err := v.validateResponseHeaders(response.Headers); err != nil {
+				return fmt.Errorf("tls client_auth: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateProtocols(protocols []string, usesTLS bool) error {
+	for _, protocol := range protocols {
+		switch protocol {
+		case configReader.ProtocolHTTP1:
+		case configReader.ProtocolHTTP2:
+			if !usesTLS {
+				return fmt.Errorf("protocol http2 needs tls, use h2c for HTTP/2 over cleartext")
+			}
+		case configReader.ProtocolH2C:
+			if usesTLS {
+				return fmt.Errorf("protocol h2c cannot be served over tls, use http2")
+			}
+		default:
+			return fmt.Errorf("invalid protocol: %s", protocol)
+		}
+	}
+
+	return nil
+}
+
+// validateGRPC checks a gRPC service, which supports none of the HTTP
+// settings. Method names and messages are checked against the descriptor set
+// when the service starts.
+func (v ValidatorConfigImpl) validateGRPC(config *configReader.ServiceConfig) error {
+	unsupported := []struct {
+		field string
+		set   bool
+	}{
+		{"endpoints", len(config.Endpoints) > 0},
+		{"scenarios", len(config.Scenarios) > 0},
+		{"request_validation", config.RequestValidation != nil},
+		{"fallback", config.Fallback != nil},
+		{"fault", config.Fault != nil},
+		{"tls", config.TLS != nil},
+		{"protocols", len(config.Protocols) > 0},
+	}
+
+	for _, setting := range unsupported {
+		if setting.set {
+			return fmt.Errorf("grpc services do not support %s", setting.field)
+		}
+	}
+
+	if err := v.validateDelay(config.Delay); err != nil {
+		return err
+	}
+
+	if config.GRPC.DescriptorSet == "" {
+		return fmt.Errorf("grpc descriptor_set cannot be empty")
+	}
+
+	if len(config.GRPC.Methods) == 0 {
+		return fmt.Errorf("grpc must configure at least one method")
+	}
+
+	for name, responses := range config.GRPC.Methods {
+		if _, _, ok := configReader.ParseGRPCMethod(name); !ok {
+			return fmt.Errorf("invalid grpc method %s, expected a name such as package.Service/Method", name)
+		}
+
+		if len(responses) == 0 {
+			return fmt.Errorf("invalid grpc method %s: at least one response must be configured", name)
+		}
+
+		for _, response := range responses {
+			if err := v.validateGRPCResponse(response); err != nil {
+				return fmt.Errorf("invalid grpc method %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateGRPCResponse(response configReader.GRPCResponseConfig) error {
+	if _, err := response.StatusCode(); err != nil {
+		return err
+	}
+
+	if response.Response != nil && len(response.Stream) > 0 {
+		return fmt.Errorf("cannot set both response and stream")
+	}
+
+	if err := v.validateResponseHeaders(response.Headers); err != nil {
+		return err
+	}
+
+	if err := v.validateResponseHeaders(response.Trailers); err != nil {
+		return err
+	}
+
+	if err := v.validateDelay(response.Delay); err != nil {
+		return err
+	}
+
+	for _, message := range response.Stream {
+		if err := v.validateDelay(message.Delay); err != nil {
+			return err
+		}
+	}
+
+	if len(response.Match.ClientCert) > 0 || response.Match.Protocol != nil {
+		return fmt.Errorf("grpc responses can only match metadata and the request body")
+	}
+
+	if err := v.validateValueMatchers("metadata", response.Match.Headers); err != nil {
+		return err
+	}
+
+	for _, bodyMatcher := range response.Match.Body {
+		if err := v.validateBodyMatcher(bodyMatcher); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateValueMatchers(kind string, matchers map[string]configReader.ValueMatcher) error {
+	for name, matcher := range matchers {
+		if name == "" {
+			return fmt.Errorf("%s name cannot be empty", kind)
+		}
+
+		if matcher.Matches != "" {
+			if _, err := regexp.Compile(matcher.Matches); err != nil {
+				return fmt.Errorf("invalid regex for %s %s: %w", kind, name, err)
+			}
+		}
+
+		if matcher.RequiresAbsence() && (matcher.EqualTo != "" || matcher.Matches != "" || len(matcher.Values) > 0) {
+			return fmt.Errorf("%s %s cannot require absence and a value at the same time", kind, name)
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateScenarioStep(scenarios map[string]configReader.ScenarioConfig, endpoint configReader.EndpointConfig) error {
+	if endpoint.Scenario == "" {
+		if endpoint.RequiredState != "" || endpoint.NewState != "" {
+			return fmt.Errorf("required_state and new_state need a scenario")
+		}
+
+		return nil
+	}
+
+	scenario, exists := scenarios[endpoint.Scenario]
+	if !exists {
+		return fmt.Errorf("scenario %s is not declared", endpoint.Scenario)
+	}
+
+	for _, state := range []string{endpoint.RequiredState, endpoint.NewState} {
+		if state != "" && !scenario.AllowsState(state) {
+			return fmt.Errorf("state %s is not declared for scenario %s", state, endpoint.Scenario)
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateRequestValidation(validation *configReader.RequestValidationConfig) error {
+	if validation == nil {
+		return nil
+	}
+
+	if validation.Spec == "" {
+		return fmt.Errorf("request_validation must set a spec")
+	}
+
+	switch validation.Mode {
+	case "", configReader.ValidationEnforce, configReader.ValidationReport:
+	default:
+		return fmt.Errorf("invalid request_validation mode: %s", validation.Mode)
+	}
+
+	if validation.StatusCode != 0 {
+		if err := v.validateStatusCode(validation.StatusCode); err != nil {
+			return fmt.Errorf("invalid request_validation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateFallback(fallback *configReader.FallbackConfig) error {
+	if fallback == nil {
+		return nil
+	}
+
+	if fallback.Proxy == nil {
+		if err := v.validateStatusCode(fallback.StatusCode); err != nil {
+			return fmt.Errorf("invalid fallback: %w", err)
+		}
+
+		return v.validateResponseHeaders(fallback.Headers)
+	}
+
+	if fallback.StatusCode != 0 || fallback.Body != nil || len(fallback.Headers) > 0 || fallback.Template {
+		return fmt.Errorf("fallback must set either a response or a proxy, not both")
+	}
+
+	target, err := url.Parse(fallback.Proxy.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("invalid fallback proxy url %q, expected http(s)://host[:port][/path]", fallback.Proxy.URL)
+	}
+
+	if err := v.validateResponseHeaders(fallback.Proxy.SetHeaders); err != nil {
+		return fmt.Errorf("invalid fallback proxy: %w", err)
+	}
+
+	return nil
+}
+
+func (v ValidatorConfigImpl) validateBodyMatcher(matcher configReader.BodyMatcher) error {
+	configured := 0
+	for _, set := range []bool{
+		matcher.EqualToJSON != nil,
+		matcher.ContainsJSON != nil,
+		matcher.JSONPath != "",
+		matcher.Matches != "",
+	} {
+		if set {
+			configured++
+		}
+	}
+
+	if configured != 1 {
+		return fmt.Errorf("body matcher must set exactly one of equal_to_json, contains_json, json_path or matches")
+	}
+
+	if matcher.JSONPath != "" {
+		if _, err := jsonPath.Compile(matcher.JSONPath); err != nil {
+			return err
+		}
+	}
+
+	if matcher.Matches != "" {
+		if _, err := regexp.Compile(matcher.Matches); err != nil {
+			return fmt.Errorf("invalid body regex: %w", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,145 @@
+package impl
+
+import (
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints map[string]configReader.EndpointList
+		fallback  *configReader.FallbackConfig
+		wantErr   string
+	}{
+		{name: "no endpoints and no fallback", wantErr: "at least one endpoint or a fallback must be configured"},
+		{name: "response only", fallback: &configReader.FallbackConfig{StatusCode: 418, Body: "teapot"}},
+		{
+			name:     "proxy only",
+			fallback: &configReader.FallbackConfig{Proxy: &configReader.ProxyConfig{URL: "https://api.example.com/v1", SetHeaders: map[string]string{"X-Env": "mock"}}},
+		},
+		{
+			name:      "alongside endpoints",
+			endpoints: map[string]configReader.EndpointList{"GET /a": {{StatusCode: 200}}},
+			fallback:  &configReader.FallbackConfig{StatusCode: 503},
+		},
+		{name: "invalid status", fallback: &configReader.FallbackConfig{StatusCode: 99}, wantErr: "invalid fallback"},
+		{name: "missing status", fallback: &configReader.FallbackConfig{Body: "x"}, wantErr: "invalid fallback"},
+		{
+			name:     "response and proxy",
+			fallback: &configReader.FallbackConfig{StatusCode: 200, Proxy: &configReader.ProxyConfig{URL: "http://localhost:8080"}},
+			wantErr:  "either a response or a proxy",
+		},
+		{
+			name:     "template and proxy",
+			fallback: &configReader.FallbackConfig{Template: true, Proxy: &configReader.ProxyConfig{URL: "http://localhost:8080"}},
+			wantErr:  "either a response or a proxy",
+		},
+		{name: "proxy without scheme", fallback: &configReader.FallbackConfig{Proxy: &configReader.ProxyConfig{URL: "localhost:8080"}}, wantErr: "invalid fallback proxy url"},
+		{name: "proxy with other scheme", fallback: &configReader.FallbackConfig{Proxy: &configReader.ProxyConfig{URL: "ftp://example.com"}}, wantErr: "invalid fallback proxy url"},
+		{name: "proxy without host", fallback: &configReader.FallbackConfig{Proxy: &configReader.ProxyConfig{URL: "http:///path"}}, wantErr: "invalid fallback proxy url"},
+		{
+			name:     "invalid proxy header",
+			fallback: &configReader.FallbackConfig{Proxy: &configReader.ProxyConfig{URL: "http://localhost:8080", SetHeaders: map[string]string{"Bad Name": "x"}}},
+			wantErr:  "invalid fallback proxy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewConfigValidator().Validate(&configReader.ServiceConfig{
+				ServiceName: "fallbackService",
+				Port:        55010,
+				Endpoints:   tt.endpoints,
+				Fallback:    tt.fallback,
+			})
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateFault(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   configReader.FaultConfig
+		wantErr string
+	}{
+		{name: "connection reset", fault: configReader.FaultConfig{Type: configReader.FaultConnectionReset}},
+		{name: "probability", fault: configReader.FaultConfig{Type: configReader.FaultEmptyReply, Probability: 0.25}},
+		{name: "slow body", fault: configReader.FaultConfig{Type: configReader.FaultSlowBody, BytesPerSecond: 10}},
+		{name: "none", fault: configReader.FaultConfig{Type: configReader.FaultNone}},
+		{name: "missing type", fault: configReader.FaultConfig{}, wantErr: "fault must set a type"},
+		{name: "unknown type", fault: configReader.FaultConfig{Type: "timeout"}, wantErr: "invalid fault type: timeout"},
+		{name: "negative probability", fault: configReader.FaultConfig{Type: configReader.FaultEmptyReply, Probability: -0.1}, wantErr: "between 0 and 1"},
+		{name: "probability above 1", fault: configReader.FaultConfig{Type: configReader.FaultEmptyReply, Probability: 1.5}, wantErr: "between 0 and 1"},
+		{name: "negative pace", fault: configReader.FaultConfig{Type: configReader.FaultSlowBody, BytesPerSecond: -1}, wantErr: "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fault := tt.fault
+
+			for _, config := range []*configReader.ServiceConfig{
+				{ServiceName: "faultService", Port: 55010, Fault: &fault, Endpoints: map[string]configReader.EndpointList{"GET /a": {{StatusCode: 200}}}},
+				{ServiceName: "faultService", Port: 55010, Endpoints: map[string]configReader.EndpointList{"GET /a": {{StatusCode: 200, Fault: &fault}}}},
+			} {
+				err := NewConfigValidator().Validate(config)
+
+				if tt.wantErr != "" {
+					assert.ErrorContains(t, err, tt.wantErr)
+					continue
+				}
+
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateProtocols(t *testing.T) {
+	tests := []struct {
+		name      string
+		protocols []string
+		tls       bool
+		wantErr   string
+	}{
+		{name: "defaults"},
+		{name: "http1 and h2c", protocols: []string{configReader.ProtocolHTTP1, configReader.ProtocolH2C}},
+		{name: "http2 over tls", protocols: []string{configReader.ProtocolHTTP2}, tls: true},
+		{name: "http1 over tls", protocols: []string{configReader.ProtocolHTTP1}, tls: true},
+		{name: "http2 without tls", protocols: []string{configReader.ProtocolHTTP2}, wantErr: "protocol http2 needs tls"},
+		{name: "h2c over tls", protocols: []string{configReader.ProtocolH2C}, tls: true, wantErr: "protocol h2c cannot be served over tls"},
+		{name: "unknown", protocols: []string{"http3"}, wantErr: "invalid protocol: http3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configReader.ServiceConfig{
+				ServiceName: "protocolService",
+				Port:        55010,
+				Protocols:   tt.protocols,
+				Endpoints:   map[string]configReader.EndpointList{"GET /a": {{StatusCode: 200}}},
+			}
+			if tt.tls {
+				config.TLS = &configReader.TLSConfig{}
+			}
+
+			err := NewConfigValidator().Validate(config)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package config_reader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Route is a single endpoint variant resolved from an endpoint key. Handler
+// routes are answered by a Go handler registered at runtime instead of the
+// endpoint configuration.
+type Route struct {
+	Key      string
+	Index    int
+	Method   string
+	Path     string
+	Query    map[string]ValueMatcher
+	Endpoint EndpointConfig
+	Handler  bool
+}
+
+// ID identifies the variant within its service, e.g. "GET /api/users#0", or
+// "GET /api/users#handler" for a handler route.
+func (r Route) ID() string {
+	if r.Handler {
+		return r.Key + "#handler"
+	}
+
+	return fmt.Sprintf("%s#%d", r.Key, r.Index)
+}
+
+// Conditions returns how many request conditions, besides method and path,
+// the route requires. A required scenario state counts as one condition.
+func (r Route) Conditions() int {
+	conditions := len(r.Query) + len(r.Endpoint.Match.Headers) + len(r.Endpoint.Match.Body) + len(r.Endpoint.Match.ClientCert)
+
+	if r.Endpoint.Match.Protocol != nil {
+		conditions++
+	}
+
+	if r.Endpoint.RequiredState != "" {
+		conditions++
+	}
+
+	return conditions
+}
+
+// OrderedRoutes flattens the endpoint map into the order requests are matched
+// against: higher priority first, then the most specific path, then routes with
+// more conditions, and finally by key and declaration order. Keys that cannot
+// be parsed are skipped.
+func OrderedRoutes(endpoints map[string]EndpointList) []Route {
+	routes := []Route{}
+
+	for endpointKey, variants := range endpoints {
+		method, path, keyQuery, err := ParseEndpointKey(endpointKey)
+		if err != nil {
+			continue
+		}
+
+		for index, endpoint := range variants {
+			routes = append(routes, Route{
+				Key:      endpointKey,
+				Index:    index,
+				Method:   method,
+				Path:     path,
+				Query:    endpoint.QueryMatchers(keyQuery),
+				Endpoint: endpoint,
+			})
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Endpoint.Priority != routes[j].Endpoint.Priority {
+			return routes[i].Endpoint.Priority > routes[j].Endpoint.Priority
+		}
+
+		if specificity := comparePathSpecificity(routes[i].Path, routes[j].Path); specificity != 0 {
+			return specificity > 0
+		}
+
+		if routes[i].Conditions() != routes[j].Conditions() {
+			return routes[i].Conditions() > routes[j].Conditions()
+		}
+
+		if routes[i].Key != routes[j].Key {
+			return routes[i].Key < routes[j].Key
+		}
+
+		return routes[i].Index < routes[j].Index
+	})
+
+	return routes
+}
+
+// comparePathSpecificity returns a positive value when a is more specific than
+// b, negative when b is, and zero when neither is. Paths with more segments
+// come first, which keeps the order strict even though such paths never match
+// the same request; paths with as many segments are compared at the first
+// segment where one is literal and the other a {param}, the literal winning.
+func comparePathSpecificity(a, b string) int {
+	segmentsA := strings.Split(strings.Trim(a, "/"), "/")
+	segmentsB := strings.Split(strings.Trim(b, "/"), "/")
+
+	if len(segmentsA) != len(segmentsB) {
+		return len(segmentsA) - len(segmentsB)
+	}
+
+	for i := range segmentsA {
+		literalA := !strings.Contains(segmentsA[i], "{")
+		literalB := !strings.Contains(segmentsB[i], "{")
+
+		if literalA != literalB {
+			if literalA {
+				return 1
+			}
+
+			return -1
+		}
+	}
+
+	return 0
+}
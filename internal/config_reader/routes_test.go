@@ -0,0 +1,112 @@
+package config_reader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparePathSpecificity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected int
+	}{
+		{name: "same literal path", a: "/api/users", b: "/api/users", expected: 0},
+		{name: "different literals", a: "/api/users", b: "/api/orders", expected: 0},
+		{name: "literal beats param", a: "/api/users/me", b: "/api/users/{id}", expected: 1},
+		{name: "param loses to literal", a: "/api/users/{id}", b: "/api/users/me", expected: -1},
+		{name: "first differing segment decides", a: "/api/{kind}/me", b: "/api/users/{id}", expected: -1},
+		{name: "same params", a: "/api/users/{id}", b: "/api/users/{userId}", expected: 0},
+		{name: "more segments first", a: "/x/y", b: "/x", expected: 1},
+		{name: "fewer segments last", a: "/x", b: "/x/{p}", expected: -1},
+		{name: "more segments beat literal", a: "/x/{p}", b: "/x", expected: 1},
+		{name: "root", a: "/", b: "/", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, sign(tt.expected), sign(comparePathSpecificity(tt.a, tt.b)))
+			assert.Equal(t, -sign(tt.expected), sign(comparePathSpecificity(tt.b, tt.a)))
+		})
+	}
+}
+
+func TestComparePathSpecificityIsTransitive(t *testing.T) {
+	paths := []string{"/x", "/x/y", "/x/{p}", "/{a}", "/{a}/y", "/x/y/z", "/x/{p}/z", "/{a}/{b}/{c}"}
+
+	for _, a := range paths {
+		for _, b := range paths {
+			for _, c := range paths {
+				ab := sign(comparePathSpecificity(a, b))
+				bc := sign(comparePathSpecificity(b, c))
+				ac := sign(comparePathSpecificity(a, c))
+
+				if ab >= 0 && bc >= 0 {
+					assert.GreaterOrEqual(t, ac, 0, "%s >= %s >= %s", a, b, c)
+				}
+
+				if ab == 0 && bc == 0 {
+					assert.Equal(t, 0, ac, "%s ~ %s ~ %s", a, b, c)
+				}
+			}
+		}
+	}
+}
+
+func TestOrderedRoutes(t *testing.T) {
+	present := true
+
+	endpoints := map[string]EndpointList{
+		"GET /x":            {{StatusCode: 200}},
+		"GET /x/{p}":        {{StatusCode: 200}},
+		"GET /x/y":          {{StatusCode: 200}},
+		"GET /api/users/me": {{StatusCode: 200}},
+		"GET /api/users/{id}": {
+			{StatusCode: 200},
+			{StatusCode: 503, Priority: 10},
+			{StatusCode: 401, Match: MatchConfig{Headers: map[string]ValueMatcher{"Authorization": {Present: &present}}}},
+		},
+		"GET /api/users?status=active": {{StatusCode: 200}},
+		"GET /api/users":               {{StatusCode: 200}, {StatusCode: 204}},
+		"GET /low":                     {{StatusCode: 200, Priority: -1}},
+		"GET":                          {{StatusCode: 200}},
+	}
+
+	expected := []string{
+		"GET /api/users/{id}#1",
+		"GET /api/users/me#0",
+		"GET /api/users/{id}#2",
+		"GET /api/users/{id}#0",
+		"GET /api/users?status=active#0",
+		"GET /api/users#0",
+		"GET /api/users#1",
+		"GET /x/y#0",
+		"GET /x/{p}#0",
+		"GET /x#0",
+		"GET /low#0",
+	}
+
+	for range 20 {
+		routes := OrderedRoutes(endpoints)
+
+		ids := make([]string, 0, len(routes))
+		for _, route := range routes {
+			ids = append(ids, route.ID())
+		}
+
+		assert.Equal(t, expected, ids)
+	}
+}
+
+func sign(value int) int {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+
+	return 0
+}
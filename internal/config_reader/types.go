@@ -1,16 +1,333 @@
 package config_reader
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
 
 type ServiceConfig struct {
-	ServiceName string                    `json:"service_name"`
-	Port        int                       `json:"port"`
-	Endpoints   map[string]EndpointConfig `json:"endpoints"`
+	ServiceName       string                    `json:"service_name"`
+	Port              int                       `json:"port"`
+	Endpoints         map[string]EndpointList   `json:"endpoints"`
+	Scenarios         map[string]ScenarioConfig `json:"scenarios,omitempty"`
+	RequestValidation *RequestValidationConfig  `json:"request_validation,omitempty"`
+	Fallback          *FallbackConfig           `json:"fallback,omitempty"`
+	Fault             *FaultConfig              `json:"fault,omitempty"`
+	Delay             *DelayConfig              `json:"delay,omitempty"`
+	TLS               *TLSConfig                `json:"tls,omitempty"`
+	Protocols         []string                  `json:"protocols,omitempty"`
+	GRPC              *GRPCConfig               `json:"grpc,omitempty"`
+}
+
+// Protocols a service can serve. HTTP/2 needs TLS and h2c, HTTP/2 over
+// cleartext with prior knowledge, needs plain HTTP. A service without
+// protocols serves HTTP/1.1, and HTTP/2 too when it uses TLS.
+const (
+	ProtocolHTTP1 = "http1"
+	ProtocolHTTP2 = "http2"
+	ProtocolH2C   = "h2c"
+)
+
+// ServesHTTP1 tells whether clients can reach the service over HTTP/1.1.
+func (s *ServiceConfig) ServesHTTP1() bool {
+	return len(s.Protocols) == 0 || slices.Contains(s.Protocols, ProtocolHTTP1)
+}
+
+// TLSConfig serves a service over HTTPS. CertFile and KeyFile are a PEM
+// certificate chain and its key, and CAFile the PEM certificate clients should
+// trust, the certificate itself when omitted. Without files, a certificate for
+// localhost and Hosts is issued at startup by a CA generated in memory.
+type TLSConfig struct {
+	CertFile   string            `json:"cert_file,omitempty"`
+	KeyFile    string            `json:"key_file,omitempty"`
+	CAFile     string            `json:"ca_file,omitempty"`
+	Hosts      []string          `json:"hosts,omitempty"`
+	ClientAuth *ClientAuthConfig `json:"client_auth,omitempty"`
+}
+
+// UsesGeneratedCA tells whether the service needs the generated CA, to issue
+// its certificate or to verify client certificates.
+func (t *TLSConfig) UsesGeneratedCA() bool {
+	return t != nil && (t.CertFile == "" || (t.ClientAuth != nil && t.ClientAuth.CAFile == ""))
+}
+
+// GetClientAuth returns the client certificate settings, nil when the
+// service does not ask for client certificates.
+func (t *TLSConfig) GetClientAuth() *ClientAuthConfig {
+	if t == nil {
+		return nil
+	}
+
+	return t.ClientAuth
+}
+
+// ClientAuthConfig asks HTTPS clients for a certificate signed by CAFile, or
+// by the generated CA when omitted. Requests without a certificate get
+// MissingResponse unless Optional is set, and requests whose certificate the CA
+// did not sign get InvalidResponse, a 401 and a 403 JSON error by default.
+// RejectHandshake fails the TLS handshake instead of answering.
+type ClientAuthConfig struct {
+	CAFile          string               `json:"ca_file,omitempty"`
+	Optional        bool                 `json:"optional,omitempty"`
+	RejectHandshake bool                 `json:"reject_handshake,omitempty"`
+	MissingResponse *ErrorResponseConfig `json:"missing_response,omitempty"`
+	InvalidResponse *ErrorResponseConfig `json:"invalid_response,omitempty"`
+}
+
+// ErrorResponseConfig is a fixed response to requests rejected before they
+// are matched against the endpoints.
+type ErrorResponseConfig struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       any               `json:"body,omitempty"`
+}
+
+// Fault types. FaultNone disables a service-level fault for one endpoint.
+const (
+	FaultNone            = "none"
+	FaultConnectionReset = "connection_reset"
+	FaultEmptyReply      = "empty_reply"
+	FaultCloseMidBody    = "close_mid_body"
+	FaultMalformedChunk  = "malformed_chunk"
+	FaultRandomGarbage   = "random_garbage"
+	FaultSlowBody        = "slow_body"
+)
+
+// FaultConfig makes a response fail at the connection level. Probability,
+// greater than 0 and up to 1, is the share of requests the fault fires on; the
+// zero value, left when it is omitted, fires on every request like 1. Config
+// files cannot set it to 0. BytesPerSecond sets the pace of slow_body
+// responses.
+type FaultConfig struct {
+	Type           string  `json:"type"`
+	Probability    float64 `json:"probability,omitempty"`
+	BytesPerSecond int     `json:"bytes_per_second,omitempty"`
+}
+
+func (f *FaultConfig) UnmarshalJSON(data []byte) error {
+	type plain FaultConfig
+	var fault struct {
+		plain
+		Probability *float64 `json:"probability"`
+	}
+	if err := json.Unmarshal(data, &fault); err != nil {
+		return err
+	}
+
+	if fault.Probability != nil {
+		if *fault.Probability == 0 {
+			return fmt.Errorf("fault probability must be greater than 0; omit it or set 1 to fault every request")
+		}
+
+		fault.plain.Probability = *fault.Probability
+	}
+
+	*f = FaultConfig(fault.plain)
+
+	return nil
+}
+
+// FallbackConfig answers the requests no endpoint matches, instead of the
+// default 404. It either sets a response, rendered like an endpoint response,
+// or a Proxy forwarding the requests to an upstream.
+type FallbackConfig struct {
+	StatusCode int               `json:"status_code,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       any               `json:"body,omitempty"`
+	Template   bool              `json:"template,omitempty"`
+	Proxy      *ProxyConfig      `json:"proxy,omitempty"`
+}
+
+// ProxyConfig forwards requests to the upstream at URL, prepending its path
+// to the request path. SetHeaders adds or replaces request headers and
+// RemoveHeaders drops them before forwarding. The Host header is rewritten to
+// the upstream host unless PreserveHost is set.
+type ProxyConfig struct {
+	URL           string            `json:"url"`
+	SetHeaders    map[string]string `json:"set_headers,omitempty"`
+	RemoveHeaders []string          `json:"remove_headers,omitempty"`
+	PreserveHost  bool              `json:"preserve_host,omitempty"`
+}
+
+// Request validation modes. Enforce answers invalid requests with the error
+// status; report only records the violations in the journal.
+const (
+	ValidationEnforce = "enforce"
+	ValidationReport  = "report"
+)
+
+// RequestValidationConfig checks incoming requests against an OpenAPI
+// document. A relative Spec path is resolved from the config directory.
+// BasePath defaults to the path of the document's first server URL.
+type RequestValidationConfig struct {
+	Spec       string `json:"spec"`
+	BasePath   string `json:"base_path,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+// ScenarioConfig declares a named state machine shared by the endpoints of a
+// service. When States is empty any state name is accepted.
+type ScenarioConfig struct {
+	InitialState string   `json:"initial_state"`
+	States       []string `json:"states,omitempty"`
+}
+
+func (s ScenarioConfig) AllowsState(state string) bool {
+	return len(s.States) == 0 || slices.Contains(s.States, state)
 }
 
 type EndpointConfig struct {
+	StatusCode int                     `json:"status_code"`
+	Headers    map[string]string       `json:"headers,omitempty"`
+	Body       any                     `json:"body,omitempty"`
+	Delay      *DelayConfig            `json:"delay,omitempty"`
+	Query      map[string]ValueMatcher `json:"query,omitempty"`
+	Match      MatchConfig             `json:"match,omitzero"`
+	Priority   int                     `json:"priority,omitempty"`
+	Template   bool                    `json:"template,omitempty"`
+
+	Scenario      string `json:"scenario,omitempty"`
+	RequiredState string `json:"required_state,omitempty"`
+	NewState      string `json:"new_state,omitempty"`
+
+	Responses    []ResponseConfig `json:"responses,omitempty"`
+	SequenceMode string           `json:"sequence_mode,omitempty"`
+
+	Fault *FaultConfig `json:"fault,omitempty"`
+}
+
+// Sequence modes select which entry of EndpointConfig.Responses answers a call.
+const (
+	SequenceStickOnLast = "stick_on_last"
+	SequenceCycle       = "cycle"
+	SequenceRandom      = "random"
+	SequenceWeighted    = "weighted"
+)
+
+// ResponseConfig is one entry of an endpoint's response sequence.
+type ResponseConfig struct {
 	StatusCode int               `json:"status_code"`
 	Headers    map[string]string `json:"headers,omitempty"`
 	Body       any               `json:"body,omitempty"`
-	Delay      time.Duration     `json:"delay,omitempty"`
+	Delay      *DelayConfig      `json:"delay,omitempty"`
+	Weight     int               `json:"weight,omitempty"`
+	Fault      *FaultConfig      `json:"fault,omitempty"`
+}
+
+// EndpointList holds the variants configured for one endpoint key. It can be
+// written as a single endpoint object or as an array of conditional variants.
+type EndpointList []EndpointConfig
+
+func (l *EndpointList) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var endpoints []EndpointConfig
+		if err := json.Unmarshal(trimmed, &endpoints); err != nil {
+			return err
+		}
+
+		*l = endpoints
+		return nil
+	}
+
+	var endpoint EndpointConfig
+	if err := json.Unmarshal(trimmed, &endpoint); err != nil {
+		return err
+	}
+
+	*l = EndpointList{endpoint}
+
+	return nil
+}
+
+func (l EndpointList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+
+	return json.Marshal([]EndpointConfig(l))
+}
+
+// MatchConfig groups the request conditions, besides method, path and query,
+// that an endpoint variant requires. ClientCert matches fields of the client
+// certificate, named in ClientCertFields, and Protocol the request protocol,
+// such as "HTTP/1.1" or "HTTP/2.0", which "HTTP/2" also matches.
+type MatchConfig struct {
+	Headers    map[string]ValueMatcher `json:"headers,omitempty"`
+	Body       []BodyMatcher           `json:"body,omitempty"`
+	ClientCert map[string]ValueMatcher `json:"client_cert,omitempty"`
+	Protocol   *ValueMatcher           `json:"protocol,omitempty"`
+}
+
+// ClientCertFields are the client certificate fields endpoints can match. The
+// "san" field holds every subject alternative name, "dns", "email", "uri" and
+// "ip" one kind each.
+var ClientCertFields = []string{
+	"subject", "common_name", "organization", "organizational_unit", "issuer",
+	"san", "dns", "email", "uri", "ip",
+}
+
+// BodyMatcher describes a condition on the request body. Exactly one field is
+// expected to be set: full JSON equality, a partial JSON subset, a JSONPath
+// expression such as "$.amount > 1000", or a regex over the raw body.
+type BodyMatcher struct {
+	EqualToJSON  any    `json:"equal_to_json,omitempty"`
+	ContainsJSON any    `json:"contains_json,omitempty"`
+	JSONPath     string `json:"json_path,omitempty"`
+	Matches      string `json:"matches,omitempty"`
+}
+
+// ValueMatcher describes the condition a named request value (a query parameter
+// or a header) must satisfy. It can be written as a plain string for an exact
+// match or as a list of strings for a multi-value match. `{"absent": true}` is
+// accepted as another spelling of `{"present": false}`.
+type ValueMatcher struct {
+	EqualTo string   `json:"equal_to,omitempty"`
+	Matches string   `json:"matches,omitempty"`
+	Present *bool    `json:"present,omitempty"`
+	Values  []string `json:"values,omitempty"`
+}
+
+// RequiresAbsence reports whether the matcher requires the value to be absent.
+func (v ValueMatcher) RequiresAbsence() bool {
+	return v.Present != nil && !*v.Present
+}
+
+func (v *ValueMatcher) UnmarshalJSON(data []byte) error {
+	var exact string
+	if err := json.Unmarshal(data, &exact); err == nil {
+		*v = ValueMatcher{EqualTo: exact}
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err == nil {
+		*v = ValueMatcher{Values: values}
+		return nil
+	}
+
+	type plain ValueMatcher
+	var matcher struct {
+		plain
+		Absent *bool `json:"absent"`
+	}
+	if err := json.Unmarshal(data, &matcher); err != nil {
+		return err
+	}
+
+	if matcher.Absent != nil {
+		if matcher.Present != nil {
+			return fmt.Errorf("present and absent cannot be set at the same time")
+		}
+
+		present := !*matcher.Absent
+		matcher.Present = &present
+	}
+
+	*v = ValueMatcher(matcher.plain)
+
+	return nil
 }
@@ -0,0 +1,75 @@
+package config_reader
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueMatcherUnmarshalJSON(t *testing.T) {
+	present := true
+	absent := false
+
+	tests := []struct {
+		name     string
+		input    string
+		expected ValueMatcher
+		wantErr  bool
+	}{
+		{name: "exact value", input: `"active"`, expected: ValueMatcher{EqualTo: "active"}},
+		{name: "multiple values", input: `["a", "b"]`, expected: ValueMatcher{Values: []string{"a", "b"}}},
+		{name: "regex", input: `{"matches": "^v2"}`, expected: ValueMatcher{Matches: "^v2"}},
+		{name: "present", input: `{"present": true}`, expected: ValueMatcher{Present: &present}},
+		{name: "present false", input: `{"present": false}`, expected: ValueMatcher{Present: &absent}},
+		{name: "absent", input: `{"absent": true}`, expected: ValueMatcher{Present: &absent}},
+		{name: "absent false", input: `{"absent": false}`, expected: ValueMatcher{Present: &present}},
+		{name: "present and absent", input: `{"present": false, "absent": true}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matcher ValueMatcher
+			err := json.Unmarshal([]byte(tt.input), &matcher)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matcher)
+		})
+	}
+}
+
+func TestFaultConfigUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected FaultConfig
+		wantErr  bool
+	}{
+		{name: "omitted probability", input: `{"type": "empty_reply"}`, expected: FaultConfig{Type: FaultEmptyReply}},
+		{name: "probability", input: `{"type": "connection_reset", "probability": 0.3}`, expected: FaultConfig{Type: FaultConnectionReset, Probability: 0.3}},
+		{name: "always", input: `{"type": "connection_reset", "probability": 1}`, expected: FaultConfig{Type: FaultConnectionReset, Probability: 1}},
+		{name: "slow body", input: `{"type": "slow_body", "bytes_per_second": 10}`, expected: FaultConfig{Type: FaultSlowBody, BytesPerSecond: 10}},
+		{name: "zero probability", input: `{"type": "connection_reset", "probability": 0}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fault FaultConfig
+			err := json.Unmarshal([]byte(tt.input), &fault)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fault)
+		})
+	}
+}
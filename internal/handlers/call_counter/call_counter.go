@@ -0,0 +1,8 @@
+package call_counter
+
+type CallCounter interface {
+	Increment(id string) int
+	Get(id string) int
+	Reset(ids ...string)
+	Counts() map[string]int
+}
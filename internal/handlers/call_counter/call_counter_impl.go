@@ -0,0 +1,57 @@
+package call_counter
+
+import "sync"
+
+type CallCounterImpl struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewCallCounter() CallCounter {
+	return &CallCounterImpl{
+		counts: make(map[string]int),
+	}
+}
+
+func (c *CallCounterImpl) Increment(id string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[id]++
+
+	return c.counts[id]
+}
+
+func (c *CallCounterImpl) Get(id string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts[id]
+}
+
+// Reset clears the given counters, or every counter when no id is given.
+func (c *CallCounterImpl) Reset(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(ids) == 0 {
+		c.counts = make(map[string]int)
+		return
+	}
+
+	for _, id := range ids {
+		delete(c.counts, id)
+	}
+}
+
+func (c *CallCounterImpl) Counts() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]int, len(c.counts))
+	for id, count := range c.counts {
+		result[id] = count
+	}
+
+	return result
+}
@@ -0,0 +1,26 @@
+package call_counter
+
+import "github.com/stretchr/testify/mock"
+
+type MockCallCounter struct {
+	mock.Mock
+}
+
+func (m *MockCallCounter) Increment(id string) int {
+	args := m.Called(id)
+	return args.Int(0)
+}
+
+func (m *MockCallCounter) Get(id string) int {
+	args := m.Called(id)
+	return args.Int(0)
+}
+
+func (m *MockCallCounter) Reset(ids ...string) {
+	m.Called(ids)
+}
+
+func (m *MockCallCounter) Counts() map[string]int {
+	args := m.Called()
+	return args.Get(0).(map[string]int)
+}
@@ -0,0 +1,14 @@
+package handler_registry
+
+import (
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+type HandlerRegistry interface {
+	Register(method, path string, handler http.HandlerFunc) error
+	Remove(method, path string) bool
+	Lookup(endpointKey string) (http.HandlerFunc, bool)
+	Routes() []configReader.Route
+}
@@ -0,0 +1,99 @@
+package handler_registry
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+type HandlerRegistryImpl struct {
+	mu       sync.RWMutex
+	handlers map[string]http.HandlerFunc
+}
+
+func NewHandlerRegistry() HandlerRegistry {
+	return &HandlerRegistryImpl{
+		handlers: make(map[string]http.HandlerFunc),
+	}
+}
+
+// Register adds a handler for the method and path, replacing any handler
+// already registered for them.
+func (h *HandlerRegistryImpl) Register(method, path string, handler http.HandlerFunc) error {
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+
+	key, err := handlerKey(method, path)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handlers[key] = handler
+
+	return nil
+}
+
+func (h *HandlerRegistryImpl) Remove(method, path string) bool {
+	key, err := handlerKey(method, path)
+	if err != nil {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, exists := h.handlers[key]
+	delete(h.handlers, key)
+
+	return exists
+}
+
+func (h *HandlerRegistryImpl) Lookup(endpointKey string) (http.HandlerFunc, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	handler, exists := h.handlers[endpointKey]
+
+	return handler, exists
+}
+
+// Routes returns the registered handlers ordered from the most specific path
+// to the least specific one.
+func (h *HandlerRegistryImpl) Routes() []configReader.Route {
+	h.mu.RLock()
+	endpoints := make(map[string]configReader.EndpointList, len(h.handlers))
+	for key := range h.handlers {
+		endpoints[key] = configReader.EndpointList{{}}
+	}
+	h.mu.RUnlock()
+
+	routes := configReader.OrderedRoutes(endpoints)
+	for i := range routes {
+		routes[i].Handler = true
+	}
+
+	return routes
+}
+
+func handlerKey(method, path string) (string, error) {
+	if method == "" {
+		return "", fmt.Errorf("method cannot be empty")
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("path must start with /")
+	}
+
+	if strings.Contains(path, "?") {
+		return "", fmt.Errorf("handler path cannot contain a query string")
+	}
+
+	return strings.ToUpper(method) + " " + path, nil
+}
@@ -0,0 +1,79 @@
+package handler_registry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noop(http.ResponseWriter, *http.Request) {}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		handler http.HandlerFunc
+		key     string
+		wantErr bool
+	}{
+		{name: "valid", method: "GET", path: "/users/{id}", handler: noop, key: "GET /users/{id}"},
+		{name: "lowercase method", method: "post", path: "/users", handler: noop, key: "POST /users"},
+		{name: "nil handler", method: "GET", path: "/users", wantErr: true},
+		{name: "empty method", path: "/users", handler: noop, wantErr: true},
+		{name: "relative path", method: "GET", path: "users", handler: noop, wantErr: true},
+		{name: "query string", method: "GET", path: "/users?active=true", handler: noop, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewHandlerRegistry()
+			err := registry.Register(tt.method, tt.path, tt.handler)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, registry.Routes())
+				return
+			}
+
+			require.NoError(t, err)
+
+			_, exists := registry.Lookup(tt.key)
+			assert.True(t, exists)
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	registry := NewHandlerRegistry()
+	require.NoError(t, registry.Register("GET", "/users", noop))
+
+	assert.True(t, registry.Remove("get", "/users"))
+	assert.False(t, registry.Remove("GET", "/users"))
+	assert.False(t, registry.Remove("", "/users"))
+
+	_, exists := registry.Lookup("GET /users")
+	assert.False(t, exists)
+}
+
+func TestRoutesOrderedBySpecificity(t *testing.T) {
+	registry := NewHandlerRegistry()
+	for _, path := range []string{"/users/{id}", "/users/me", "/users/{id}/orders", "/health"} {
+		require.NoError(t, registry.Register("GET", path, noop))
+	}
+
+	ids := []string{}
+	for _, route := range registry.Routes() {
+		assert.True(t, route.Handler)
+		ids = append(ids, route.ID())
+	}
+
+	assert.Equal(t, []string{
+		"GET /users/{id}/orders#handler",
+		"GET /users/me#handler",
+		"GET /users/{id}#handler",
+		"GET /health#handler",
+	}, ids)
+}
@@ -0,0 +1,38 @@
+package handler_registry
+
+import (
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHandlerRegistry struct {
+	mock.Mock
+}
+
+func (m *MockHandlerRegistry) Register(method, path string, handler http.HandlerFunc) error {
+	args := m.Called(method, path, handler)
+	return args.Error(0)
+}
+
+func (m *MockHandlerRegistry) Remove(method, path string) bool {
+	args := m.Called(method, path)
+	return args.Bool(0)
+}
+
+func (m *MockHandlerRegistry) Lookup(endpointKey string) (http.HandlerFunc, bool) {
+	args := m.Called(endpointKey)
+
+	var handler http.HandlerFunc
+	if args.Get(0) != nil {
+		handler = args.Get(0).(http.HandlerFunc)
+	}
+
+	return handler, args.Bool(1)
+}
+
+func (m *MockHandlerRegistry) Routes() []configReader.Route {
+	args := m.Called()
+	return args.Get(0).([]configReader.Route)
+}
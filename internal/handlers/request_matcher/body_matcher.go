@@ -0,0 +1,144 @@
+package request_matcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	jsonPath "github.com/JTGlez/gockapi/internal/json_path"
+)
+
+var jsonPathCache sync.Map
+
+func (rm *RequestMatcherImpl) MatchBody(r *http.Request, matchers []configReader.BodyMatcher) (bool, error) {
+	if len(matchers) == 0 {
+		return true, nil
+	}
+
+	body, err := ReadBody(r)
+	if err != nil {
+		return false, err
+	}
+
+	var document any
+	isJSON := json.Unmarshal(body, &document) == nil
+
+	for _, matcher := range matchers {
+		matches, err := matchBody(body, document, isJSON, matcher)
+		if err != nil {
+			return false, err
+		}
+
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ReadBody returns the request body and restores it so it can be read again.
+func ReadBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func matchBody(body []byte, document any, isJSON bool, matcher configReader.BodyMatcher) (bool, error) {
+	switch {
+	case matcher.EqualToJSON != nil:
+		return isJSON && reflect.DeepEqual(document, matcher.EqualToJSON), nil
+	case matcher.ContainsJSON != nil:
+		return isJSON && containsJSON(document, matcher.ContainsJSON), nil
+	case matcher.JSONPath != "":
+		expression, err := compileJSONPath(matcher.JSONPath)
+		if err != nil {
+			return false, err
+		}
+
+		return isJSON && expression.Evaluate(document), nil
+	case matcher.Matches != "":
+		compiled, err := compileRegex(matcher.Matches)
+		if err != nil {
+			return false, fmt.Errorf("invalid body regex: %w", err)
+		}
+
+		return compiled.Match(body), nil
+	}
+
+	return true, nil
+}
+
+// containsJSON reports whether actual includes every field and array element of
+// expected. Extra fields and elements in actual are ignored.
+func containsJSON(actual, expected any) bool {
+	switch expectedValue := expected.(type) {
+	case map[string]any:
+		actualObject, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		for key, value := range expectedValue {
+			actualField, exists := actualObject[key]
+			if !exists || !containsJSON(actualField, value) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		actualList, ok := actual.([]any)
+		if !ok {
+			return false
+		}
+
+		for _, value := range expectedValue {
+			found := false
+			for _, actualElement := range actualList {
+				if containsJSON(actualElement, value) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+func compileJSONPath(expression string) (*jsonPath.Expression, error) {
+	if cached, ok := jsonPathCache.Load(expression); ok {
+		return cached.(*jsonPath.Expression), nil
+	}
+
+	compiled, err := jsonPath.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonPathCache.Store(expression, compiled)
+
+	return compiled, nil
+}
@@ -0,0 +1,109 @@
+package request_matcher
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchBody(t *testing.T) {
+	const body = `{"amount": 1500, "items": [{"sku": "A-1"}, {"sku": "B-2"}], "customer": {"tier": "gold"}}`
+
+	tests := []struct {
+		name     string
+		body     string
+		matchers []configReader.BodyMatcher
+		expected bool
+		wantErr  bool
+	}{
+		{name: "no matchers", body: body, expected: true},
+		{
+			name:     "equal json",
+			body:     `{"b": 2, "a": 1}`,
+			matchers: []configReader.BodyMatcher{{EqualToJSON: map[string]any{"a": 1.0, "b": 2.0}}},
+			expected: true,
+		},
+		{
+			name:     "equal json with extra field",
+			body:     `{"a": 1, "b": 2}`,
+			matchers: []configReader.BodyMatcher{{EqualToJSON: map[string]any{"a": 1.0}}},
+			expected: false,
+		},
+		{
+			name:     "contains json subset",
+			body:     body,
+			matchers: []configReader.BodyMatcher{{ContainsJSON: map[string]any{"customer": map[string]any{"tier": "gold"}}}},
+			expected: true,
+		},
+		{
+			name:     "contains json array element",
+			body:     body,
+			matchers: []configReader.BodyMatcher{{ContainsJSON: map[string]any{"items": []any{map[string]any{"sku": "B-2"}}}}},
+			expected: true,
+		},
+		{
+			name:     "contains json missing element",
+			body:     body,
+			matchers: []configReader.BodyMatcher{{ContainsJSON: map[string]any{"items": []any{map[string]any{"sku": "C-3"}}}}},
+			expected: false,
+		},
+		{
+			name:     "json path",
+			body:     body,
+			matchers: []configReader.BodyMatcher{{JSONPath: "$.amount > 1000"}},
+			expected: true,
+		},
+		{
+			name:     "json path on non json body",
+			body:     "amount=1500",
+			matchers: []configReader.BodyMatcher{{JSONPath: "$.amount"}},
+			expected: false,
+		},
+		{
+			name:     "invalid json path",
+			body:     body,
+			matchers: []configReader.BodyMatcher{{JSONPath: "amount"}},
+			wantErr:  true,
+		},
+		{
+			name:     "regex on raw body",
+			body:     "amount=1500",
+			matchers: []configReader.BodyMatcher{{Matches: `amount=\d{4}`}},
+			expected: true,
+		},
+		{
+			name: "every matcher must hold",
+			body: body,
+			matchers: []configReader.BodyMatcher{
+				{JSONPath: "$.amount > 1000"},
+				{JSONPath: "$.customer.tier == 'silver'"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/payments", strings.NewReader(tt.body))
+
+			matches, err := (&RequestMatcherImpl{}).MatchBody(r, tt.matchers)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+
+			restored, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(restored), "the body can be read again")
+		})
+	}
+}
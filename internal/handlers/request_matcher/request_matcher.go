@@ -1,8 +1,17 @@
 package request_matcher
 
-import "net/http"
+import (
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
 
 type RequestMatcher interface {
 	Match(r *http.Request, method, pathPattern string) (bool, map[string]string, error)
 	ExtractPathParameters(requestPath, pathPattern string) (map[string]string, error)
+	MatchQuery(r *http.Request, query map[string]configReader.ValueMatcher) (bool, error)
+	MatchHeaders(r *http.Request, headers map[string]configReader.ValueMatcher) (bool, error)
+	MatchBody(r *http.Request, matchers []configReader.BodyMatcher) (bool, error)
+	MatchClientCert(r *http.Request, fields map[string]configReader.ValueMatcher) (bool, error)
+	MatchProtocol(r *http.Request, matcher *configReader.ValueMatcher) (bool, error)
 }
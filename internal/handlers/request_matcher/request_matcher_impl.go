@@ -1,14 +1,23 @@
 package request_matcher
 
 import (
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
 )
 
 type RequestMatcherImpl struct{}
 
+var regexCache sync.Map
+
+var pathParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
 func (rm *RequestMatcherImpl) Match(r *http.Request, method, pathPattern string) (bool, map[string]string, error) {
 	if !strings.EqualFold(r.Method, method) {
 		return false, nil, nil
@@ -35,26 +44,30 @@ func (rm *RequestMatcherImpl) ExtractPathParameters(requestPath, pathPattern str
 		return nil, nil
 	}
 
-	regexPattern := pathPattern
 	paramNames := []string{}
 
-	paramRegex := regexp.MustCompile(`\{([^}]+)\}`)
-	matches := paramRegex.FindAllStringSubmatch(pathPattern, -1)
+	// Literal parts are quoted so paths such as /v1.0/{file}.json match only
+	// themselves.
+	var regexPattern strings.Builder
+	regexPattern.WriteString("^")
 
-	for _, match := range matches {
-		paramName := match[1]
-		paramNames = append(paramNames, paramName)
-		regexPattern = strings.Replace(regexPattern, match[0], `([^/]+)`, 1)
+	last := 0
+	for _, location := range pathParamRegex.FindAllStringSubmatchIndex(pathPattern, -1) {
+		regexPattern.WriteString(regexp.QuoteMeta(pathPattern[last:location[0]]))
+		regexPattern.WriteString(`([^/]+)`)
+		paramNames = append(paramNames, pathPattern[location[2]:location[3]])
+		last = location[1]
 	}
 
-	regexPattern = "^" + regexPattern + "$"
+	regexPattern.WriteString(regexp.QuoteMeta(pathPattern[last:]))
+	regexPattern.WriteString("$")
 
-	compiled, err := regexp.Compile(regexPattern)
+	compiled, err := compileRegex(regexPattern.String())
 	if err != nil {
 		return nil, fmt.Errorf("invalid path pattern %s: %w", pathPattern, err)
 	}
 
-	matches = compiled.FindAllStringSubmatch(requestPath, -1)
+	matches := compiled.FindAllStringSubmatch(requestPath, -1)
 	if len(matches) == 0 {
 		return nil, nil
 	}
@@ -69,3 +82,180 @@ func (rm *RequestMatcherImpl) ExtractPathParameters(requestPath, pathPattern str
 
 	return pathParams, nil
 }
+
+func (rm *RequestMatcherImpl) MatchQuery(r *http.Request, query map[string]configReader.ValueMatcher) (bool, error) {
+	if len(query) == 0 {
+		return true, nil
+	}
+
+	values := r.URL.Query()
+
+	for name, matcher := range query {
+		paramValues, present := values[name]
+
+		matches, err := matchValues(paramValues, present, matcher)
+		if err != nil {
+			return false, fmt.Errorf("invalid matcher for query parameter %s: %w", name, err)
+		}
+
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (rm *RequestMatcherImpl) MatchHeaders(r *http.Request, headers map[string]configReader.ValueMatcher) (bool, error) {
+	for name, matcher := range headers {
+		headerValues := r.Header.Values(name)
+
+		matches, err := matchValues(headerValues, len(headerValues) > 0, matcher)
+		if err != nil {
+			return false, fmt.Errorf("invalid matcher for header %s: %w", name, err)
+		}
+
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// MatchClientCert matches fields of the certificate the client presented over
+// TLS. A request without one has none of the fields.
+func (rm *RequestMatcherImpl) MatchClientCert(r *http.Request, fields map[string]configReader.ValueMatcher) (bool, error) {
+	var cert *x509.Certificate
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		cert = r.TLS.PeerCertificates[0]
+	}
+
+	for field, matcher := range fields {
+		values := clientCertValues(cert, field)
+
+		matches, err := matchValues(values, len(values) > 0, matcher)
+		if err != nil {
+			return false, fmt.Errorf("invalid matcher for client certificate field %s: %w", field, err)
+		}
+
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// MatchProtocol matches the request protocol, such as "HTTP/1.1". HTTP/2
+// requests match "HTTP/2" as well as "HTTP/2.0".
+func (rm *RequestMatcherImpl) MatchProtocol(r *http.Request, matcher *configReader.ValueMatcher) (bool, error) {
+	if matcher == nil {
+		return true, nil
+	}
+
+	values := []string{r.Proto}
+	if r.ProtoMajor == 2 {
+		values = append(values, "HTTP/2")
+	}
+
+	matches, err := matchValues(values, true, *matcher)
+	if err != nil {
+		return false, fmt.Errorf("invalid matcher for protocol: %w", err)
+	}
+
+	return matches, nil
+}
+
+func clientCertValues(cert *x509.Certificate, field string) []string {
+	if cert == nil {
+		return nil
+	}
+
+	ips := []string{}
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+
+	uris := []string{}
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	switch field {
+	case "subject":
+		return []string{cert.Subject.String()}
+	case "common_name":
+		if cert.Subject.CommonName == "" {
+			return nil
+		}
+
+		return []string{cert.Subject.CommonName}
+	case "organization":
+		return cert.Subject.Organization
+	case "organizational_unit":
+		return cert.Subject.OrganizationalUnit
+	case "issuer":
+		return []string{cert.Issuer.String()}
+	case "san":
+		return slices.Concat(cert.DNSNames, cert.EmailAddresses, uris, ips)
+	case "dns":
+		return cert.DNSNames
+	case "email":
+		return cert.EmailAddresses
+	case "uri":
+		return uris
+	case "ip":
+		return ips
+	}
+
+	return nil
+}
+
+func matchValues(values []string, present bool, matcher configReader.ValueMatcher) (bool, error) {
+	if matcher.RequiresAbsence() {
+		return !present, nil
+	}
+
+	if !present {
+		return false, nil
+	}
+
+	if matcher.EqualTo != "" && !slices.Contains(values, matcher.EqualTo) {
+		return false, nil
+	}
+
+	if matcher.Matches != "" {
+		compiled, err := compileRegex(matcher.Matches)
+		if err != nil {
+			return false, err
+		}
+
+		if !slices.ContainsFunc(values, compiled.MatchString) {
+			return false, nil
+		}
+	}
+
+	for _, expected := range matcher.Values {
+		if !slices.Contains(values, expected) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Store(pattern, compiled)
+
+	return compiled, nil
+}
@@ -0,0 +1,217 @@
+package request_matcher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchValues(t *testing.T) {
+	present := true
+	absent := false
+
+	tests := []struct {
+		name     string
+		values   []string
+		matcher  configReader.ValueMatcher
+		expected bool
+		wantErr  bool
+	}{
+		{name: "exact", values: []string{"active"}, matcher: configReader.ValueMatcher{EqualTo: "active"}, expected: true},
+		{name: "exact among several", values: []string{"a", "active"}, matcher: configReader.ValueMatcher{EqualTo: "active"}, expected: true},
+		{name: "exact mismatch", values: []string{"banned"}, matcher: configReader.ValueMatcher{EqualTo: "active"}, expected: false},
+		{name: "exact missing", matcher: configReader.ValueMatcher{EqualTo: "active"}, expected: false},
+		{name: "regex", values: []string{"v2.1"}, matcher: configReader.ValueMatcher{Matches: `^v2`}, expected: true},
+		{name: "regex mismatch", values: []string{"v1"}, matcher: configReader.ValueMatcher{Matches: `^v2`}, expected: false},
+		{name: "invalid regex", values: []string{"v1"}, matcher: configReader.ValueMatcher{Matches: `(`}, wantErr: true},
+		{name: "present", values: []string{""}, matcher: configReader.ValueMatcher{Present: &present}, expected: true},
+		{name: "present missing", matcher: configReader.ValueMatcher{Present: &present}, expected: false},
+		{name: "absent", matcher: configReader.ValueMatcher{Present: &absent}, expected: true},
+		{name: "absent but sent", values: []string{"x"}, matcher: configReader.ValueMatcher{Present: &absent}, expected: false},
+		{name: "all values", values: []string{"a", "b", "c"}, matcher: configReader.ValueMatcher{Values: []string{"a", "c"}}, expected: true},
+		{name: "missing one value", values: []string{"a"}, matcher: configReader.ValueMatcher{Values: []string{"a", "c"}}, expected: false},
+		{name: "exact and regex", values: []string{"v2"}, matcher: configReader.ValueMatcher{EqualTo: "v2", Matches: `^v`}, expected: true},
+		{name: "empty matcher requires presence", values: []string{"x"}, matcher: configReader.ValueMatcher{}, expected: true},
+		{name: "empty matcher missing", matcher: configReader.ValueMatcher{}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := matchValues(tt.values, len(tt.values) > 0, tt.matcher)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
+func TestMatchQueryAndHeaders(t *testing.T) {
+	matcher := &RequestMatcherImpl{}
+
+	r := httptest.NewRequest("GET", "/api/users?status=active&tag=a&tag=b", nil)
+	r.Header.Set("X-Tenant-ID", "acme")
+
+	matches, err := matcher.MatchQuery(r, map[string]configReader.ValueMatcher{
+		"status": {EqualTo: "active"},
+		"tag":    {Values: []string{"a", "b"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = matcher.MatchQuery(r, map[string]configReader.ValueMatcher{"status": {EqualTo: "banned"}})
+	require.NoError(t, err)
+	assert.False(t, matches)
+
+	matches, err = matcher.MatchHeaders(r, map[string]configReader.ValueMatcher{"x-tenant-id": {EqualTo: "acme"}})
+	require.NoError(t, err)
+	assert.True(t, matches, "header names are case-insensitive")
+
+	absent := false
+	matches, err = matcher.MatchHeaders(r, map[string]configReader.ValueMatcher{"Authorization": {Present: &absent}})
+	require.NoError(t, err)
+	assert.True(t, matches)
+}
+
+func TestMatchQuery(t *testing.T) {
+	absent := false
+
+	tests := []struct {
+		name     string
+		target   string
+		query    map[string]configReader.ValueMatcher
+		expected bool
+	}{
+		{name: "no conditions", target: "/api/users?status=active", expected: true},
+		{name: "exact", target: "/api/users?status=active", query: map[string]configReader.ValueMatcher{"status": {EqualTo: "active"}}, expected: true},
+		{name: "exact mismatch", target: "/api/users?status=banned", query: map[string]configReader.ValueMatcher{"status": {EqualTo: "active"}}, expected: false},
+		{name: "missing parameter", target: "/api/users", query: map[string]configReader.ValueMatcher{"status": {EqualTo: "active"}}, expected: false},
+		{name: "extra parameters allowed", target: "/api/users?status=active&page=2", query: map[string]configReader.ValueMatcher{"status": {EqualTo: "active"}}, expected: true},
+		{name: "decoded value", target: "/search?q=a%20b", query: map[string]configReader.ValueMatcher{"q": {EqualTo: "a b"}}, expected: true},
+		{name: "empty value is present", target: "/api/users?debug", query: map[string]configReader.ValueMatcher{"debug": {}}, expected: true},
+		{name: "absent", target: "/api/users", query: map[string]configReader.ValueMatcher{"debug": {Present: &absent}}, expected: true},
+		{name: "absent but sent", target: "/api/users?debug=1", query: map[string]configReader.ValueMatcher{"debug": {Present: &absent}}, expected: false},
+		{name: "every condition", target: "/api/users?status=active", query: map[string]configReader.ValueMatcher{"status": {EqualTo: "active"}, "page": {EqualTo: "1"}}, expected: false},
+	}
+
+	matcher := &RequestMatcherImpl{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := matcher.MatchQuery(httptest.NewRequest("GET", tt.target, nil), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+
+	_, err := matcher.MatchQuery(httptest.NewRequest("GET", "/?a=1", nil), map[string]configReader.ValueMatcher{"a": {Matches: "("}})
+	assert.Error(t, err)
+}
+
+func TestMatchClientCert(t *testing.T) {
+	spiffe, err := url.Parse("spiffe://corp/billing")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "billing", Organization: []string{"Corp"}, OrganizationalUnit: []string{"Payments"}},
+		Issuer:         pkix.Name{CommonName: "Corp CA"},
+		DNSNames:       []string{"billing.internal"},
+		EmailAddresses: []string{"ops@corp.example"},
+		URIs:           []*url.URL{spiffe},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.7")},
+	}
+
+	absent := false
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		fields   map[string]configReader.ValueMatcher
+		expected bool
+	}{
+		{name: "no matchers", fields: nil, expected: true},
+		{name: "subject", cert: cert, fields: map[string]configReader.ValueMatcher{"subject": {EqualTo: "CN=billing,OU=Payments,O=Corp"}}, expected: true},
+		{name: "common name", cert: cert, fields: map[string]configReader.ValueMatcher{"common_name": {EqualTo: "billing"}}, expected: true},
+		{name: "common name misses", cert: cert, fields: map[string]configReader.ValueMatcher{"common_name": {EqualTo: "acme"}}, expected: false},
+		{name: "organization", cert: cert, fields: map[string]configReader.ValueMatcher{"organization": {EqualTo: "Corp"}, "organizational_unit": {EqualTo: "Payments"}}, expected: true},
+		{name: "issuer", cert: cert, fields: map[string]configReader.ValueMatcher{"issuer": {Matches: "Corp CA"}}, expected: true},
+		{name: "any SAN", cert: cert, fields: map[string]configReader.ValueMatcher{"san": {Matches: "^spiffe://corp/"}}, expected: true},
+		{name: "SAN misses", cert: cert, fields: map[string]configReader.ValueMatcher{"san": {Matches: "^spiffe://other/"}}, expected: false},
+		{name: "dns", cert: cert, fields: map[string]configReader.ValueMatcher{"dns": {EqualTo: "billing.internal"}}, expected: true},
+		{name: "email", cert: cert, fields: map[string]configReader.ValueMatcher{"email": {EqualTo: "ops@corp.example"}}, expected: true},
+		{name: "uri", cert: cert, fields: map[string]configReader.ValueMatcher{"uri": {EqualTo: "spiffe://corp/billing"}}, expected: true},
+		{name: "ip", cert: cert, fields: map[string]configReader.ValueMatcher{"ip": {EqualTo: "10.0.0.7"}}, expected: true},
+		{name: "ip is not a dns name", cert: cert, fields: map[string]configReader.ValueMatcher{"dns": {EqualTo: "10.0.0.7"}}, expected: false},
+		{name: "every field must match", cert: cert, fields: map[string]configReader.ValueMatcher{"common_name": {EqualTo: "billing"}, "dns": {EqualTo: "other"}}, expected: false},
+		{name: "without certificate", fields: map[string]configReader.ValueMatcher{"common_name": {Matches: ".*"}}, expected: false},
+		{name: "absence without certificate", fields: map[string]configReader.ValueMatcher{"subject": {Present: &absent}}, expected: true},
+		{name: "absence with certificate", cert: cert, fields: map[string]configReader.ValueMatcher{"subject": {Present: &absent}}, expected: false},
+	}
+
+	matcher := &RequestMatcherImpl{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.cert != nil {
+				r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tt.cert}}
+			}
+
+			matches, err := matcher.MatchClientCert(r, tt.fields)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	_, err = matcher.MatchClientCert(r, map[string]configReader.ValueMatcher{"san": {Matches: "("}})
+	assert.ErrorContains(t, err, "client certificate field san")
+}
+
+func TestMatchProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		proto    string
+		major    int
+		matcher  *configReader.ValueMatcher
+		expected bool
+	}{
+		{name: "no matcher", proto: "HTTP/1.1", major: 1, expected: true},
+		{name: "exact HTTP/1.1", proto: "HTTP/1.1", major: 1, matcher: &configReader.ValueMatcher{EqualTo: "HTTP/1.1"}, expected: true},
+		{name: "HTTP/1.1 is not HTTP/2", proto: "HTTP/1.1", major: 1, matcher: &configReader.ValueMatcher{EqualTo: "HTTP/2"}, expected: false},
+		{name: "exact HTTP/2.0", proto: "HTTP/2.0", major: 2, matcher: &configReader.ValueMatcher{EqualTo: "HTTP/2.0"}, expected: true},
+		{name: "HTTP/2 alias", proto: "HTTP/2.0", major: 2, matcher: &configReader.ValueMatcher{EqualTo: "HTTP/2"}, expected: true},
+		{name: "regex", proto: "HTTP/2.0", major: 2, matcher: &configReader.ValueMatcher{Matches: "^HTTP/1"}, expected: false},
+		{name: "values", proto: "HTTP/1.0", major: 1, matcher: &configReader.ValueMatcher{Values: []string{"HTTP/1.0"}}, expected: true},
+	}
+
+	matcher := &RequestMatcherImpl{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Proto, r.ProtoMajor = tt.proto, tt.major
+
+			matches, err := matcher.MatchProtocol(r, tt.matcher)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+
+	_, err := matcher.MatchProtocol(httptest.NewRequest("GET", "/", nil), &configReader.ValueMatcher{Matches: "("})
+	assert.ErrorContains(t, err, "invalid matcher for protocol")
+}
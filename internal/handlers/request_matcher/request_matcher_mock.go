@@ -3,6 +3,7 @@ package request_matcher
 import (
 	"net/http"
 
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,3 +20,28 @@ func (m *MockRequestMatcher) ExtractPathParameters(requestPath, pathPattern stri
 	args := m.Called(requestPath, pathPattern)
 	return args.Get(0).(map[string]string), args.Error(1)
 }
+
+func (m *MockRequestMatcher) MatchQuery(r *http.Request, query map[string]configReader.ValueMatcher) (bool, error) {
+	args := m.Called(r, query)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRequestMatcher) MatchHeaders(r *http.Request, headers map[string]configReader.ValueMatcher) (bool, error) {
+	args := m.Called(r, headers)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRequestMatcher) MatchBody(r *http.Request, matchers []configReader.BodyMatcher) (bool, error) {
+	args := m.Called(r, matchers)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRequestMatcher) MatchClientCert(r *http.Request, fields map[string]configReader.ValueMatcher) (bool, error) {
+	args := m.Called(r, fields)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRequestMatcher) MatchProtocol(r *http.Request, matcher *configReader.ValueMatcher) (bool, error) {
+	args := m.Called(r, matcher)
+	return args.Bool(0), args.Error(1)
+}
@@ -4,11 +4,29 @@ import (
 	"net/http"
 
 	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	callCounter "github.com/JTGlez/gockapi/internal/handlers/call_counter"
+	handlerRegistry "github.com/JTGlez/gockapi/internal/handlers/handler_registry"
+	scenarioStore "github.com/JTGlez/gockapi/internal/handlers/scenario_store"
 )
 
 type ResponseHandler interface {
-	HandleRequest(w http.ResponseWriter, r *http.Request, serviceConfig *configReader.ServiceConfig) error
-	MatchEndpoint(r *http.Request, endpoints map[string]configReader.EndpointConfig) (*configReader.EndpointConfig, string, error)
+	HandleRequest(w http.ResponseWriter, r *http.Request, serviceConfig *configReader.ServiceConfig, state *RequestState) (*configReader.Route, error)
+	MatchEndpoint(r *http.Request, endpoints map[string]configReader.EndpointList, state *RequestState) (*configReader.Route, map[string]string, error)
 	WriteResponse(w http.ResponseWriter, endpointConfig *configReader.EndpointConfig) error
 	GetSupportedContentTypes() []string
 }
+
+// RequestState is the per-server runtime state requests are matched and
+// answered against. A nil state disables scenario-bound endpoints and answers
+// every response sequence with its first entry. Registered Go handlers are
+// tried before the configured endpoints, however specific their paths, and
+// Unmatched, when set, answers the requests no endpoint matches instead of the
+// 404 response. InjectFault, when set, answers the requests whose endpoint
+// fault fires; write renders the response the matched route would have sent.
+type RequestState struct {
+	Scenarios   scenarioStore.ScenarioStore
+	Counters    callCounter.CallCounter
+	Handlers    handlerRegistry.HandlerRegistry
+	Unmatched   http.HandlerFunc
+	InjectFault func(w http.ResponseWriter, r *http.Request, route *configReader.Route, fault *configReader.FaultConfig, write func(http.ResponseWriter) error) error
+}
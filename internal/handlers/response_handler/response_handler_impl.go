@@ -2,75 +2,242 @@ package response_handler
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"net/http"
-	"strings"
-	"time"
 
 	configReader "github.com/JTGlez/gockapi/internal/config_reader"
 	requestMatcher "github.com/JTGlez/gockapi/internal/handlers/request_matcher"
 	responseWriter "github.com/JTGlez/gockapi/internal/handlers/response_writer"
+	templateRenderer "github.com/JTGlez/gockapi/internal/handlers/template_renderer"
 )
 
 type ResponseHandlerImpl struct {
-	Matcher requestMatcher.RequestMatcher
-	Writer  responseWriter.ResponseWriter
+	Matcher  requestMatcher.RequestMatcher
+	Writer   responseWriter.ResponseWriter
+	Renderer templateRenderer.TemplateRenderer
 }
 
 func NewResponseHandler() ResponseHandler {
 	return &ResponseHandlerImpl{
-		Matcher: &requestMatcher.RequestMatcherImpl{},
-		Writer:  &responseWriter.ResponseWriterImpl{},
+		Matcher:  &requestMatcher.RequestMatcherImpl{},
+		Writer:   &responseWriter.ResponseWriterImpl{},
+		Renderer: &templateRenderer.TemplateRendererImpl{},
 	}
 }
-func (rh *ResponseHandlerImpl) HandleRequest(w http.ResponseWriter, r *http.Request, serviceConfig *configReader.ServiceConfig) error {
-	endpointConfig, endpointKey, err := rh.MatchEndpoint(r, serviceConfig.Endpoints)
+func (rh *ResponseHandlerImpl) HandleRequest(w http.ResponseWriter, r *http.Request, serviceConfig *configReader.ServiceConfig, state *RequestState) (*configReader.Route, error) {
+	handlerRoute, handler, pathParams, err := rh.matchHandler(r, state)
 	if err != nil {
-		return fmt.Errorf("failed to match endpoint: %w", err)
+		return nil, fmt.Errorf("failed to match handler: %w", err)
+	}
+
+	if handler != nil {
+		if state.Counters != nil {
+			state.Counters.Increment(handlerRoute.ID())
+		}
+
+		for name, value := range pathParams {
+			r.SetPathValue(name, value)
+		}
+
+		handler(w, r)
+
+		return handlerRoute, nil
+	}
+
+	route, pathParams, err := rh.matchAndTransition(r, serviceConfig.Endpoints, state)
+	if err != nil {
+		return route, err
 	}
 
 	// Si no se encuentra endpoint, retornar 404
-	if endpointConfig == nil {
-		return rh.writeNotFound(w)
+	if route == nil {
+		if state != nil && state.Unmatched != nil {
+			state.Unmatched(w, r)
+			return nil, nil
+		}
+
+		return nil, rh.writeFallback(w, r, serviceConfig.Fallback)
+	}
+
+	endpointKey := route.Key
+
+	callCount := 1
+	if state != nil && state.Counters != nil {
+		callCount = state.Counters.Increment(route.ID())
+	}
+
+	endpointConfig := rh.selectResponse(&route.Endpoint, callCount)
+
+	if endpointConfig.Template {
+		endpointConfig, err = rh.Renderer.Render(r, pathParams, endpointConfig)
+		if err != nil {
+			return route, fmt.Errorf("failed to render template for endpoint %s: %w", endpointKey, err)
+		}
+	}
+
+	delay := endpointConfig.Delay
+	if delay == nil {
+		delay = serviceConfig.Delay
 	}
 
-	// Aplicar delay si está configurado
-	if endpointConfig.Delay > 0 {
-		time.Sleep(endpointConfig.Delay)
+	// Aplicar delay si está configurado; si el cliente se desconecta no se
+	// responde y el journal registra la petición como cancelada
+	if !delay.Wait(r.Context()) {
+		return route, nil
+	}
+
+	if fault := selectFault(endpointConfig, serviceConfig, rand.Float64); fault != nil && state != nil && state.InjectFault != nil {
+		err = state.InjectFault(w, r, route, fault, func(w http.ResponseWriter) error {
+			return rh.WriteResponse(w, endpointConfig)
+		})
+		if err != nil {
+			return route, fmt.Errorf("failed to inject %s fault for endpoint %s: %w", fault.Type, endpointKey, err)
+		}
+
+		return route, nil
 	}
 
 	// Escribir respuesta
 	err = rh.WriteResponse(w, endpointConfig)
 	if err != nil {
-		return fmt.Errorf("failed to write response for endpoint %s: %w", endpointKey, err)
+		return route, fmt.Errorf("failed to write response for endpoint %s: %w", endpointKey, err)
 	}
 
-	return nil
+	return route, nil
 }
 
-func (rh *ResponseHandlerImpl) MatchEndpoint(r *http.Request, endpoints map[string]configReader.EndpointConfig) (*configReader.EndpointConfig, string, error) {
-	for endpointKey, endpointConfig := range endpoints {
-		parts := strings.SplitN(endpointKey, " ", 2)
-		if len(parts) != 2 {
+func (rh *ResponseHandlerImpl) MatchEndpoint(r *http.Request, endpoints map[string]configReader.EndpointList, state *RequestState) (*configReader.Route, map[string]string, error) {
+	for _, route := range configReader.OrderedRoutes(endpoints) {
+		if !rh.matchesScenarioState(route.Endpoint, state) {
 			continue
 		}
 
-		method, pathPattern := parts[0], parts[1]
+		matches, pathParams, err := rh.Matcher.Match(r, route.Method, route.Path)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		matches, pathParams, err := rh.Matcher.Match(r, method, pathPattern)
+		if !matches {
+			continue
+		}
+
+		queryMatches, err := rh.Matcher.MatchQuery(r, route.Query)
 		if err != nil {
-			return nil, "", err
+			return nil, nil, fmt.Errorf("endpoint %s: %w", route.Key, err)
 		}
 
-		if matches {
-			configCopy := endpointConfig
+		if !queryMatches {
+			continue
+		}
 
-			_ = pathParams
+		headerMatches, err := rh.Matcher.MatchHeaders(r, route.Endpoint.Match.Headers)
+		if err != nil {
+			return nil, nil, fmt.Errorf("endpoint %s: %w", route.Key, err)
+		}
+
+		if !headerMatches {
+			continue
+		}
+
+		clientCertMatches, err := rh.Matcher.MatchClientCert(r, route.Endpoint.Match.ClientCert)
+		if err != nil {
+			return nil, nil, fmt.Errorf("endpoint %s: %w", route.Key, err)
+		}
+
+		if !clientCertMatches {
+			continue
+		}
+
+		protocolMatches, err := rh.Matcher.MatchProtocol(r, route.Endpoint.Match.Protocol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("endpoint %s: %w", route.Key, err)
+		}
 
-			return &configCopy, endpointKey, nil
+		if !protocolMatches {
+			continue
+		}
+
+		bodyMatches, err := rh.Matcher.MatchBody(r, route.Endpoint.Match.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("endpoint %s: %w", route.Key, err)
+		}
+
+		if bodyMatches {
+			matched := route
+
+			return &matched, pathParams, nil
 		}
 	}
 
-	return nil, "", nil
+	return nil, nil, nil
+}
+
+// matchAndTransition matches the request and moves the scenario of the matched
+// endpoint to its new state. The transition only applies while the scenario is
+// still in the state the endpoint required, so concurrent requests cannot both
+// take the same transition; a request that loses the race is matched again.
+// It runs before the delay, so a request cancelled while it waits keeps the
+// transition it took.
+func (rh *ResponseHandlerImpl) matchAndTransition(r *http.Request, endpoints map[string]configReader.EndpointList, state *RequestState) (*configReader.Route, map[string]string, error) {
+	for {
+		route, pathParams, err := rh.MatchEndpoint(r, endpoints, state)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to match endpoint: %w", err)
+		}
+
+		if route == nil || route.Endpoint.NewState == "" || state == nil || state.Scenarios == nil {
+			return route, pathParams, nil
+		}
+
+		endpointConfig := route.Endpoint
+
+		if endpointConfig.RequiredState == "" {
+			err = state.Scenarios.SetState(endpointConfig.Scenario, endpointConfig.NewState)
+			if err != nil {
+				return route, nil, fmt.Errorf("failed to transition scenario for endpoint %s: %w", route.Key, err)
+			}
+
+			return route, pathParams, nil
+		}
+
+		transitioned, err := state.Scenarios.CompareAndSetState(endpointConfig.Scenario, endpointConfig.RequiredState, endpointConfig.NewState)
+		if err != nil {
+			return route, nil, fmt.Errorf("failed to transition scenario for endpoint %s: %w", route.Key, err)
+		}
+
+		if transitioned {
+			return route, pathParams, nil
+		}
+	}
+}
+
+// matchHandler finds the registered Go handler for the request, if any.
+func (rh *ResponseHandlerImpl) matchHandler(r *http.Request, state *RequestState) (*configReader.Route, http.HandlerFunc, map[string]string, error) {
+	if state == nil || state.Handlers == nil {
+		return nil, nil, nil, nil
+	}
+
+	for _, route := range state.Handlers.Routes() {
+		matches, pathParams, err := rh.Matcher.Match(r, route.Method, route.Path)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		handler, exists := state.Handlers.Lookup(route.Key)
+		if !exists {
+			continue
+		}
+
+		matched := route
+
+		return &matched, handler, pathParams, nil
+	}
+
+	return nil, nil, nil, nil
 }
 
 func (rh *ResponseHandlerImpl) WriteResponse(w http.ResponseWriter, endpointConfig *configReader.EndpointConfig) error {
@@ -95,6 +262,136 @@ func (rh *ResponseHandlerImpl) GetSupportedContentTypes() []string {
 	return []string{"application/json", "text/plain"}
 }
 
+// selectResponse resolves the response sequence of an endpoint for the given
+// 1-based call count. Endpoints without a sequence are returned unchanged.
+func (rh *ResponseHandlerImpl) selectResponse(endpointConfig *configReader.EndpointConfig, callCount int) *configReader.EndpointConfig {
+	responses := endpointConfig.Responses
+	if len(responses) == 0 {
+		return endpointConfig
+	}
+
+	index := 0
+
+	switch endpointConfig.SequenceMode {
+	case configReader.SequenceCycle:
+		index = (callCount - 1) % len(responses)
+	case configReader.SequenceRandom:
+		index = rand.IntN(len(responses))
+	case configReader.SequenceWeighted:
+		index = pickWeighted(responses, rand.IntN)
+	default:
+		index = min(callCount, len(responses)) - 1
+	}
+
+	selected := *endpointConfig
+	selected.StatusCode = responses[index].StatusCode
+	selected.Body = responses[index].Body
+	if responses[index].Delay != nil {
+		selected.Delay = responses[index].Delay
+	}
+
+	if responses[index].Fault != nil {
+		selected.Fault = responses[index].Fault
+	}
+
+	if len(responses[index].Headers) > 0 {
+		selected.Headers = make(map[string]string, len(endpointConfig.Headers)+len(responses[index].Headers))
+		for key, value := range endpointConfig.Headers {
+			selected.Headers[key] = value
+		}
+
+		for key, value := range responses[index].Headers {
+			selected.Headers[key] = value
+		}
+	}
+
+	return &selected
+}
+
+// selectFault returns the fault to inject into this response: the endpoint's
+// own fault, else the service's, when it fires for the request. A fault with a
+// probability fires when draw, a number in [0, 1), falls below it.
+func selectFault(endpointConfig *configReader.EndpointConfig, serviceConfig *configReader.ServiceConfig, draw func() float64) *configReader.FaultConfig {
+	fault := endpointConfig.Fault
+	if fault == nil {
+		fault = serviceConfig.Fault
+	}
+
+	if fault == nil || fault.Type == configReader.FaultNone {
+		return nil
+	}
+
+	if fault.Probability > 0 && draw() >= fault.Probability {
+		return nil
+	}
+
+	return fault
+}
+
+// pickWeighted picks the index of a response with probability proportional to
+// its weight, drawing from intN. Without positive weights every response is
+// equally likely.
+func pickWeighted(responses []configReader.ResponseConfig, intN func(int) int) int {
+	total := 0
+	for _, response := range responses {
+		total += max(response.Weight, 0)
+	}
+
+	if total == 0 {
+		return intN(len(responses))
+	}
+
+	pick := intN(total)
+	for i, response := range responses {
+		pick -= max(response.Weight, 0)
+		if pick < 0 {
+			return i
+		}
+	}
+
+	return len(responses) - 1
+}
+
+func (rh *ResponseHandlerImpl) matchesScenarioState(endpointConfig configReader.EndpointConfig, state *RequestState) bool {
+	if endpointConfig.RequiredState == "" {
+		return true
+	}
+
+	if state == nil || state.Scenarios == nil {
+		return false
+	}
+
+	current, exists := state.Scenarios.GetState(endpointConfig.Scenario)
+
+	return exists && current == endpointConfig.RequiredState
+}
+
+// writeFallback answers an unmatched request with the service's fallback
+// response, or with a 404 when it has none.
+func (rh *ResponseHandlerImpl) writeFallback(w http.ResponseWriter, r *http.Request, fallback *configReader.FallbackConfig) error {
+	if fallback == nil || fallback.StatusCode == 0 {
+		return rh.writeNotFound(w)
+	}
+
+	endpointConfig := &configReader.EndpointConfig{
+		StatusCode: fallback.StatusCode,
+		Headers:    fallback.Headers,
+		Body:       fallback.Body,
+		Template:   fallback.Template,
+	}
+
+	if endpointConfig.Template {
+		rendered, err := rh.Renderer.Render(r, nil, endpointConfig)
+		if err != nil {
+			return fmt.Errorf("failed to render fallback template: %w", err)
+		}
+
+		endpointConfig = rendered
+	}
+
+	return rh.WriteResponse(w, endpointConfig)
+}
+
 func (rh *ResponseHandlerImpl) writeNotFound(w http.ResponseWriter) error {
 	notFoundConfig := &configReader.EndpointConfig{
 		StatusCode: 404,
@@ -0,0 +1,346 @@
+package response_handler
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	handlerRegistry "github.com/JTGlez/gockapi/internal/handlers/handler_registry"
+	scenarioStore "github.com/JTGlez/gockapi/internal/handlers/scenario_store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRequestTransitionsScenarioOnce(t *testing.T) {
+	serviceConfig := &configReader.ServiceConfig{
+		ServiceName: "checkoutService",
+		Scenarios: map[string]configReader.ScenarioConfig{
+			"checkout": {InitialState: "Started", States: []string{"Started", "Paid"}},
+		},
+		Endpoints: map[string]configReader.EndpointList{
+			"POST /api/pay": {
+				{StatusCode: http.StatusCreated, Scenario: "checkout", RequiredState: "Started", NewState: "Paid"},
+				{StatusCode: http.StatusConflict, Scenario: "checkout", RequiredState: "Paid"},
+			},
+		},
+	}
+
+	handler := NewResponseHandler()
+	state := &RequestState{Scenarios: scenarioStore.NewScenarioStore(serviceConfig.Scenarios)}
+
+	var mu sync.Mutex
+	statuses := map[int]int{}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			recorder := httptest.NewRecorder()
+			_, err := handler.HandleRequest(recorder, httptest.NewRequest(http.MethodPost, "/api/pay", nil), serviceConfig, state)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			statuses[recorder.Code]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, map[int]int{http.StatusCreated: 1, http.StatusConflict: 49}, statuses)
+
+	current, exists := state.Scenarios.GetState("checkout")
+	require.True(t, exists)
+	assert.Equal(t, "Paid", current)
+}
+
+func TestHandleRequestTransitionsBeforeDelay(t *testing.T) {
+	serviceConfig := &configReader.ServiceConfig{
+		ServiceName: "checkoutService",
+		Scenarios: map[string]configReader.ScenarioConfig{
+			"checkout": {InitialState: "Started"},
+		},
+		Endpoints: map[string]configReader.EndpointList{
+			"POST /api/pay": {
+				{StatusCode: http.StatusCreated, Scenario: "checkout", RequiredState: "Started", NewState: "Paid", Delay: configReader.FixedDelay(time.Minute)},
+			},
+		},
+	}
+
+	handler := NewResponseHandler()
+	state := &RequestState{Scenarios: scenarioStore.NewScenarioStore(serviceConfig.Scenarios)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	route, err := handler.HandleRequest(recorder, httptest.NewRequest(http.MethodPost, "/api/pay", nil).WithContext(ctx), serviceConfig, state)
+	require.NoError(t, err)
+	require.NotNil(t, route)
+	assert.Empty(t, recorder.Body.String(), "a cancelled request gets no response")
+
+	current, _ := state.Scenarios.GetState("checkout")
+	assert.Equal(t, "Paid", current, "the transition is kept")
+}
+
+func TestSelectResponseSequence(t *testing.T) {
+	responses := []configReader.ResponseConfig{
+		{StatusCode: http.StatusServiceUnavailable},
+		{StatusCode: http.StatusTooManyRequests},
+		{StatusCode: http.StatusOK},
+	}
+
+	tests := []struct {
+		mode     string
+		expected []int
+	}{
+		{mode: "", expected: []int{503, 429, 200, 200, 200}},
+		{mode: configReader.SequenceStickOnLast, expected: []int{503, 429, 200, 200, 200}},
+		{mode: configReader.SequenceCycle, expected: []int{503, 429, 200, 503, 429, 200, 503}},
+	}
+
+	handler := &ResponseHandlerImpl{}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			endpoint := &configReader.EndpointConfig{Responses: responses, SequenceMode: tt.mode}
+
+			statuses := []int{}
+			for callCount := 1; callCount <= len(tt.expected); callCount++ {
+				statuses = append(statuses, handler.selectResponse(endpoint, callCount).StatusCode)
+			}
+
+			assert.Equal(t, tt.expected, statuses)
+		})
+	}
+}
+
+func TestSelectResponseMergesEntry(t *testing.T) {
+	endpoint := &configReader.EndpointConfig{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "application/json", "X-Service": "payments"},
+		Delay:      configReader.FixedDelay(1),
+		Responses: []configReader.ResponseConfig{
+			{StatusCode: http.StatusAccepted, Body: "queued", Headers: map[string]string{"X-Service": "queue"}},
+			{StatusCode: http.StatusOK, Body: "done", Fault: &configReader.FaultConfig{Type: configReader.FaultEmptyReply}},
+		},
+	}
+
+	handler := &ResponseHandlerImpl{}
+
+	first := handler.selectResponse(endpoint, 1)
+	assert.Equal(t, http.StatusAccepted, first.StatusCode)
+	assert.Equal(t, "queued", first.Body)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Service": "queue"}, first.Headers)
+	assert.Equal(t, endpoint.Delay, first.Delay)
+	assert.Nil(t, first.Fault)
+
+	second := handler.selectResponse(endpoint, 2)
+	assert.Equal(t, endpoint.Headers, second.Headers)
+	assert.Equal(t, configReader.FaultEmptyReply, second.Fault.Type)
+
+	assert.Equal(t, "payments", endpoint.Headers["X-Service"], "the endpoint itself is not modified")
+}
+
+func TestPickWeighted(t *testing.T) {
+	tests := []struct {
+		name     string
+		weights  []int
+		expected []float64
+	}{
+		{name: "proportional", weights: []int{8, 2}, expected: []float64{0.8, 0.2}},
+		{name: "zero weight never picked", weights: []int{0, 3, 1}, expected: []float64{0, 0.75, 0.25}},
+		{name: "negative weight ignored", weights: []int{-5, 1}, expected: []float64{0, 1}},
+		{name: "no weights is uniform", weights: []int{0, 0}, expected: []float64{0.5, 0.5}},
+	}
+
+	const draws = 10000
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := make([]configReader.ResponseConfig, len(tt.weights))
+			for i, weight := range tt.weights {
+				responses[i].Weight = weight
+			}
+
+			random := rand.New(rand.NewPCG(1, 2))
+			picks := make([]int, len(responses))
+			for range draws {
+				picks[pickWeighted(responses, random.IntN)]++
+			}
+
+			for i, share := range tt.expected {
+				assert.InDelta(t, share, float64(picks[i])/draws, 0.02, "response %d", i)
+			}
+		})
+	}
+}
+
+func TestPickWeightedIsDeterministic(t *testing.T) {
+	responses := []configReader.ResponseConfig{{Weight: 1}, {Weight: 1}, {Weight: 1}}
+
+	first := rand.New(rand.NewPCG(7, 7))
+	second := rand.New(rand.NewPCG(7, 7))
+
+	for range 100 {
+		assert.Equal(t, pickWeighted(responses, first.IntN), pickWeighted(responses, second.IntN))
+	}
+}
+
+func TestSelectResponseWithoutSequence(t *testing.T) {
+	endpoint := &configReader.EndpointConfig{StatusCode: http.StatusOK}
+
+	assert.Same(t, endpoint, (&ResponseHandlerImpl{}).selectResponse(endpoint, 3))
+}
+
+func TestHandleRequestTriesHandlersFirst(t *testing.T) {
+	serviceConfig := &configReader.ServiceConfig{
+		ServiceName: "userService",
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /users/me":    {{StatusCode: http.StatusOK, Body: "config"}},
+			"GET /users/admin": {{StatusCode: http.StatusOK, Body: "config", Priority: 10}},
+			"GET /orders":      {{StatusCode: http.StatusOK, Body: "config"}},
+		},
+	}
+
+	registry := handlerRegistry.NewHandlerRegistry()
+	require.NoError(t, registry.Register("GET", "/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("handler " + r.PathValue("id")))
+	}))
+
+	handler := NewResponseHandler()
+	state := &RequestState{Handlers: registry}
+
+	tests := []struct {
+		target string
+		body   string
+		id     string
+	}{
+		{target: "/users/7", body: "handler 7", id: "GET /users/{id}#handler"},
+		{target: "/users/me", body: "handler me", id: "GET /users/{id}#handler"},
+		{target: "/users/admin", body: "handler admin", id: "GET /users/{id}#handler"},
+		{target: "/orders", body: "config", id: "GET /orders#0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			route, err := handler.HandleRequest(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil), serviceConfig, state)
+			require.NoError(t, err)
+			require.NotNil(t, route)
+			assert.Equal(t, tt.id, route.ID())
+			assert.Equal(t, tt.body, strings.TrimSpace(recorder.Body.String()))
+		})
+	}
+}
+
+func TestHandleRequestFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback *configReader.FallbackConfig
+		status   int
+		body     string
+		header   string
+	}{
+		{name: "no fallback", status: http.StatusNotFound, body: `"error":"Not Found"`},
+		{name: "proxy only", fallback: &configReader.FallbackConfig{Proxy: &configReader.ProxyConfig{URL: "http://localhost"}}, status: http.StatusNotFound, body: `"error":"Not Found"`},
+		{
+			name:     "response",
+			fallback: &configReader.FallbackConfig{StatusCode: http.StatusServiceUnavailable, Headers: map[string]string{"Retry-After": "5"}, Body: map[string]any{"error": "down"}},
+			status:   http.StatusServiceUnavailable,
+			body:     `{"error":"down"}`,
+			header:   "5",
+		},
+		{
+			name:     "template",
+			fallback: &configReader.FallbackConfig{StatusCode: http.StatusTeapot, Body: "no route for {{.Method}} {{.URL}}", Template: true},
+			status:   http.StatusTeapot,
+			body:     "no route for GET /missing?x=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := &configReader.ServiceConfig{
+				ServiceName: "fallbackService",
+				Endpoints:   map[string]configReader.EndpointList{"GET /users": {{StatusCode: http.StatusOK}}},
+				Fallback:    tt.fallback,
+			}
+
+			recorder := httptest.NewRecorder()
+			route, err := NewResponseHandler().HandleRequest(recorder, httptest.NewRequest(http.MethodGet, "/missing?x=1", nil), serviceConfig, nil)
+			require.NoError(t, err)
+
+			assert.Nil(t, route)
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.body)
+			assert.Equal(t, tt.header, recorder.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestHandleRequestUnmatchedHandler(t *testing.T) {
+	serviceConfig := &configReader.ServiceConfig{
+		ServiceName: "fallbackService",
+		Endpoints:   map[string]configReader.EndpointList{"GET /users": {{StatusCode: http.StatusOK}}},
+		Fallback:    &configReader.FallbackConfig{StatusCode: http.StatusServiceUnavailable},
+	}
+
+	state := &RequestState{Unmatched: func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}}
+
+	recorder := httptest.NewRecorder()
+	_, err := NewResponseHandler().HandleRequest(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil), serviceConfig, state)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, recorder.Code, "the unmatched handler answers before the fallback response")
+
+	recorder = httptest.NewRecorder()
+	route, err := NewResponseHandler().HandleRequest(recorder, httptest.NewRequest(http.MethodGet, "/users", nil), serviceConfig, state)
+	require.NoError(t, err)
+	require.NotNil(t, route)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestSelectFault(t *testing.T) {
+	reset := &configReader.FaultConfig{Type: configReader.FaultConnectionReset}
+	empty := &configReader.FaultConfig{Type: configReader.FaultEmptyReply}
+	half := &configReader.FaultConfig{Type: configReader.FaultEmptyReply, Probability: 0.5}
+
+	tests := []struct {
+		name     string
+		endpoint *configReader.FaultConfig
+		service  *configReader.FaultConfig
+		draw     float64
+		expected *configReader.FaultConfig
+	}{
+		{name: "no fault"},
+		{name: "service fault", service: reset, expected: reset},
+		{name: "endpoint fault wins", endpoint: empty, service: reset, expected: empty},
+		{name: "endpoint opts out", endpoint: &configReader.FaultConfig{Type: configReader.FaultNone}, service: reset},
+		{name: "without probability", endpoint: reset, draw: 0.99, expected: reset},
+		{name: "draw below probability", endpoint: half, draw: 0.49, expected: half},
+		{name: "draw at probability", endpoint: half, draw: 0.5},
+		{name: "draw above probability", endpoint: half, draw: 0.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fault := selectFault(
+				&configReader.EndpointConfig{Fault: tt.endpoint},
+				&configReader.ServiceConfig{Fault: tt.service},
+				func() float64 { return tt.draw },
+			)
+
+			assert.Same(t, tt.expected, fault)
+		})
+	}
+}
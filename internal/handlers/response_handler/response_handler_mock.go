@@ -11,14 +11,20 @@ type MockResponseHandler struct {
 	mock.Mock
 }
 
-func (m *MockResponseHandler) HandleRequest(w http.ResponseWriter, r *http.Request, serviceConfig *configReader.ServiceConfig) error {
-	args := m.Called(w, r, serviceConfig)
-	return args.Error(0)
+func (m *MockResponseHandler) HandleRequest(w http.ResponseWriter, r *http.Request, serviceConfig *configReader.ServiceConfig, state *RequestState) (*configReader.Route, error) {
+	args := m.Called(w, r, serviceConfig, state)
+
+	var route *configReader.Route
+	if args.Get(0) != nil {
+		route = args.Get(0).(*configReader.Route)
+	}
+
+	return route, args.Error(1)
 }
 
-func (m *MockResponseHandler) MatchEndpoint(r *http.Request, endpoints map[string]configReader.EndpointConfig) (*configReader.EndpointConfig, string, error) {
-	args := m.Called(r, endpoints)
-	return args.Get(0).(*configReader.EndpointConfig), args.String(1), args.Error(2)
+func (m *MockResponseHandler) MatchEndpoint(r *http.Request, endpoints map[string]configReader.EndpointList, state *RequestState) (*configReader.Route, map[string]string, error) {
+	args := m.Called(r, endpoints, state)
+	return args.Get(0).(*configReader.Route), args.Get(1).(map[string]string), args.Error(2)
 }
 
 func (m *MockResponseHandler) WriteResponse(w http.ResponseWriter, endpointConfig *configReader.EndpointConfig) error {
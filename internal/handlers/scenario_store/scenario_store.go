@@ -0,0 +1,13 @@
+package scenario_store
+
+import configReader "github.com/JTGlez/gockapi/internal/config_reader"
+
+type ScenarioStore interface {
+	Configure(scenarios map[string]configReader.ScenarioConfig)
+	GetState(name string) (string, bool)
+	SetState(name, state string) error
+	CompareAndSetState(name, expected, state string) (bool, error)
+	Reset(name string) error
+	ResetAll()
+	States() map[string]string
+}
@@ -0,0 +1,136 @@
+package scenario_store
+
+import (
+	"fmt"
+	"sync"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+type ScenarioStoreImpl struct {
+	mu        sync.RWMutex
+	scenarios map[string]configReader.ScenarioConfig
+	states    map[string]string
+}
+
+func NewScenarioStore(scenarios map[string]configReader.ScenarioConfig) ScenarioStore {
+	store := &ScenarioStoreImpl{
+		scenarios: make(map[string]configReader.ScenarioConfig),
+		states:    make(map[string]string),
+	}
+
+	store.Configure(scenarios)
+
+	return store
+}
+
+// Configure replaces the scenario definitions. Scenarios that remain defined
+// keep their current state; new ones start in their initial state.
+func (s *ScenarioStoreImpl) Configure(scenarios map[string]configReader.ScenarioConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make(map[string]string, len(scenarios))
+
+	for name, scenario := range scenarios {
+		if current, exists := s.states[name]; exists && scenario.AllowsState(current) {
+			states[name] = current
+		} else {
+			states[name] = scenario.InitialState
+		}
+	}
+
+	s.scenarios = make(map[string]configReader.ScenarioConfig, len(scenarios))
+	for name, scenario := range scenarios {
+		s.scenarios[name] = scenario
+	}
+
+	s.states = states
+}
+
+func (s *ScenarioStoreImpl) GetState(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, exists := s.states[name]
+
+	return state, exists
+}
+
+func (s *ScenarioStoreImpl) SetState(name, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.setState(name, state)
+}
+
+// CompareAndSetState moves the scenario to state only while it is still in the
+// expected state, and reports whether it did.
+func (s *ScenarioStoreImpl) CompareAndSetState(name, expected, state string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.scenarios[name]; !exists {
+		return false, fmt.Errorf("scenario %s is not defined", name)
+	}
+
+	if s.states[name] != expected {
+		return false, nil
+	}
+
+	if err := s.setState(name, state); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *ScenarioStoreImpl) setState(name, state string) error {
+	scenario, exists := s.scenarios[name]
+	if !exists {
+		return fmt.Errorf("scenario %s is not defined", name)
+	}
+
+	if !scenario.AllowsState(state) {
+		return fmt.Errorf("state %s is not declared for scenario %s", state, name)
+	}
+
+	s.states[name] = state
+
+	return nil
+}
+
+func (s *ScenarioStoreImpl) Reset(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scenario, exists := s.scenarios[name]
+	if !exists {
+		return fmt.Errorf("scenario %s is not defined", name)
+	}
+
+	s.states[name] = scenario.InitialState
+
+	return nil
+}
+
+func (s *ScenarioStoreImpl) ResetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, scenario := range s.scenarios {
+		s.states[name] = scenario.InitialState
+	}
+}
+
+func (s *ScenarioStoreImpl) States() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]string, len(s.states))
+	for name, state := range s.states {
+		result[name] = state
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package scenario_store
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareAndSetState(t *testing.T) {
+	store := NewScenarioStore(map[string]configReader.ScenarioConfig{
+		"checkout": {InitialState: "Started", States: []string{"Started", "Paid"}},
+	})
+
+	transitioned, err := store.CompareAndSetState("checkout", "Paid", "Started")
+	require.NoError(t, err)
+	assert.False(t, transitioned, "the scenario is not in the expected state")
+
+	transitioned, err = store.CompareAndSetState("checkout", "Started", "Shipped")
+	assert.Error(t, err, "the new state is not declared")
+	assert.False(t, transitioned)
+
+	transitioned, err = store.CompareAndSetState("checkout", "Started", "Paid")
+	require.NoError(t, err)
+	assert.True(t, transitioned)
+
+	state, _ := store.GetState("checkout")
+	assert.Equal(t, "Paid", state)
+
+	_, err = store.CompareAndSetState("unknown", "Started", "Paid")
+	assert.Error(t, err)
+}
+
+func TestCompareAndSetStateConcurrently(t *testing.T) {
+	store := NewScenarioStore(map[string]configReader.ScenarioConfig{
+		"checkout": {InitialState: "Started"},
+	})
+
+	var transitions atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			transitioned, err := store.CompareAndSetState("checkout", "Started", "Paid")
+			assert.NoError(t, err)
+
+			if transitioned {
+				transitions.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), transitions.Load())
+}
@@ -0,0 +1,43 @@
+package scenario_store
+
+import (
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScenarioStore struct {
+	mock.Mock
+}
+
+func (m *MockScenarioStore) Configure(scenarios map[string]configReader.ScenarioConfig) {
+	m.Called(scenarios)
+}
+
+func (m *MockScenarioStore) GetState(name string) (string, bool) {
+	args := m.Called(name)
+	return args.String(0), args.Bool(1)
+}
+
+func (m *MockScenarioStore) SetState(name, state string) error {
+	args := m.Called(name, state)
+	return args.Error(0)
+}
+
+func (m *MockScenarioStore) CompareAndSetState(name, expected, state string) (bool, error) {
+	args := m.Called(name, expected, state)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockScenarioStore) Reset(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockScenarioStore) ResetAll() {
+	m.Called()
+}
+
+func (m *MockScenarioStore) States() map[string]string {
+	args := m.Called()
+	return args.Get(0).(map[string]string)
+}
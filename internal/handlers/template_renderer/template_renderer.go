@@ -0,0 +1,22 @@
+package template_renderer
+
+import (
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+type TemplateRenderer interface {
+	Render(r *http.Request, pathParams map[string]string, endpointConfig *configReader.EndpointConfig) (*configReader.EndpointConfig, error)
+}
+
+// TemplateData is the value templates are executed against.
+type TemplateData struct {
+	Method  string
+	URL     string
+	Path    map[string]string
+	Query   map[string]string
+	Headers map[string]string
+	Body    any
+	RawBody string
+}
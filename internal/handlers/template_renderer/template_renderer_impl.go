@@ -0,0 +1,183 @@
+package template_renderer
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	requestMatcher "github.com/JTGlez/gockapi/internal/handlers/request_matcher"
+)
+
+type TemplateRendererImpl struct{}
+
+var templateFuncs = template.FuncMap{
+	"uuid":      newUUID,
+	"now":       now,
+	"randomInt": randomInt,
+	"json":      toJSON,
+}
+
+func (t *TemplateRendererImpl) Render(r *http.Request, pathParams map[string]string, endpointConfig *configReader.EndpointConfig) (*configReader.EndpointConfig, error) {
+	data, err := newTemplateData(r, pathParams)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := *endpointConfig
+
+	if len(endpointConfig.Headers) > 0 {
+		rendered.Headers = make(map[string]string, len(endpointConfig.Headers))
+
+		for key, value := range endpointConfig.Headers {
+			renderedValue, err := renderString(value, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render header %s: %w", key, err)
+			}
+
+			rendered.Headers[key] = renderedValue
+		}
+	}
+
+	rendered.Body, err = renderValue(endpointConfig.Body, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	return &rendered, nil
+}
+
+func newTemplateData(r *http.Request, pathParams map[string]string) (*TemplateData, error) {
+	rawBody, err := requestMatcher.ReadBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &TemplateData{
+		Method:  r.Method,
+		URL:     r.URL.String(),
+		Path:    pathParams,
+		Query:   map[string]string{},
+		Headers: map[string]string{},
+		RawBody: string(rawBody),
+	}
+
+	if data.Path == nil {
+		data.Path = map[string]string{}
+	}
+
+	for name := range r.URL.Query() {
+		data.Query[name] = r.URL.Query().Get(name)
+	}
+
+	for name := range r.Header {
+		data.Headers[name] = r.Header.Get(name)
+	}
+
+	var body any
+	if json.Unmarshal(rawBody, &body) == nil {
+		data.Body = body
+	}
+
+	return data, nil
+}
+
+func renderValue(value any, data *TemplateData) (any, error) {
+	switch typed := value.(type) {
+	case string:
+		return renderString(typed, data)
+	case map[string]any:
+		rendered := make(map[string]any, len(typed))
+
+		for key, child := range typed {
+			renderedChild, err := renderValue(child, data)
+			if err != nil {
+				return nil, err
+			}
+
+			rendered[key] = renderedChild
+		}
+
+		return rendered, nil
+	case []any:
+		rendered := make([]any, len(typed))
+
+		for i, child := range typed {
+			renderedChild, err := renderValue(child, data)
+			if err != nil {
+				return nil, err
+			}
+
+			rendered[i] = renderedChild
+		}
+
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+func renderString(text string, data *TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("response").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func now(layout ...string) string {
+	if len(layout) > 0 {
+		return time.Now().Format(layout[0])
+	}
+
+	return time.Now().Format(time.RFC3339)
+}
+
+func randomInt(minValue, maxValue int) (int, error) {
+	if maxValue < minValue {
+		return 0, fmt.Errorf("randomInt: max %d is lower than min %d", maxValue, minValue)
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(maxValue-minValue)+1))
+	if err != nil {
+		return 0, err
+	}
+
+	return minValue + int(n.Int64()), nil
+}
+
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
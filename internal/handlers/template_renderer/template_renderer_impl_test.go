@@ -0,0 +1,92 @@
+package template_renderer
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	requestBody := `{"name": "Ada", "address": {"city": "London"}}`
+
+	tests := []struct {
+		name     string
+		body     any
+		expected any
+	}{
+		{name: "path parameter", body: "{{.Path.id}}", expected: "42"},
+		{name: "query parameter", body: "{{.Query.status}}", expected: "active"},
+		{name: "header", body: `{{index .Headers "X-Tenant-Id"}}`, expected: "acme"},
+		{name: "json body field", body: "{{.Body.name}}", expected: "Ada"},
+		{name: "nested json body field", body: "{{.Body.address.city}}", expected: "London"},
+		{name: "raw body", body: "{{.RawBody}}", expected: requestBody},
+		{name: "method and url", body: "{{.Method}} {{.URL}}", expected: "POST /users/42?status=active"},
+		{name: "missing path parameter", body: "[{{.Path.missing}}]", expected: "[]"},
+		{name: "missing query parameter", body: "[{{.Query.missing}}]", expected: "[]"},
+		{name: "missing body field", body: "[{{.Body.missing}}]", expected: "[<no value>]"},
+		{name: "guarded body field", body: "[{{with .Body.missing}}{{.}}{{end}}]", expected: "[]"},
+		{name: "plain string", body: "no template", expected: "no template"},
+		{
+			name:     "nested values",
+			body:     map[string]any{"user": map[string]any{"id": "{{.Path.id}}"}, "tags": []any{"{{.Query.status}}", 7.0}},
+			expected: map[string]any{"user": map[string]any{"id": "42"}, "tags": []any{"active", 7.0}},
+		},
+		{name: "json helper", body: "{{json .Body.address}}", expected: `{"city":"London"}`},
+		{name: "non string values are kept", body: 3.5, expected: 3.5},
+	}
+
+	renderer := &TemplateRendererImpl{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/users/42?status=active", strings.NewReader(requestBody))
+			r.Header.Set("X-Tenant-ID", "acme")
+
+			rendered, err := renderer.Render(r, map[string]string{"id": "42"}, &configReader.EndpointConfig{Body: tt.body})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered.Body)
+		})
+	}
+}
+
+func TestRenderHeadersAndHelpers(t *testing.T) {
+	renderer := &TemplateRendererImpl{}
+	endpoint := &configReader.EndpointConfig{
+		StatusCode: 201,
+		Headers:    map[string]string{"X-Request-ID": "{{uuid}}", "Location": "/users/{{.Path.id}}"},
+		Body:       map[string]any{"n": "{{randomInt 3 5}}", "date": `{{now "2006-01-02"}}`},
+	}
+
+	rendered, err := renderer.Render(httptest.NewRequest("GET", "/users/7", nil), map[string]string{"id": "7"}, endpoint)
+	require.NoError(t, err)
+
+	assert.Equal(t, 201, rendered.StatusCode)
+	assert.Equal(t, "/users/7", rendered.Headers["Location"])
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), rendered.Headers["X-Request-ID"])
+	assert.Contains(t, []any{"3", "4", "5"}, rendered.Body.(map[string]any)["n"])
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}$`, rendered.Body.(map[string]any)["date"])
+	assert.Equal(t, "{{uuid}}", endpoint.Headers["X-Request-ID"], "the endpoint itself is not modified")
+}
+
+func TestRenderWithoutJSONBody(t *testing.T) {
+	renderer := &TemplateRendererImpl{}
+	r := httptest.NewRequest("POST", "/", strings.NewReader("name=Ada"))
+
+	rendered, err := renderer.Render(r, nil, &configReader.EndpointConfig{Body: "[{{with .Body}}{{.name}}{{end}}] {{.RawBody}}"})
+	require.NoError(t, err)
+	assert.Equal(t, "[] name=Ada", rendered.Body)
+}
+
+func TestRenderErrors(t *testing.T) {
+	renderer := &TemplateRendererImpl{}
+
+	for _, body := range []any{"{{.Path.id", "{{randomInt 5 1}}", "{{unknown}}"} {
+		_, err := renderer.Render(httptest.NewRequest("GET", "/", nil), nil, &configReader.EndpointConfig{Body: body})
+		assert.Error(t, err, body)
+	}
+}
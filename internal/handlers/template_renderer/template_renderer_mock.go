@@ -0,0 +1,23 @@
+package template_renderer
+
+import (
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTemplateRenderer struct {
+	mock.Mock
+}
+
+func (m *MockTemplateRenderer) Render(r *http.Request, pathParams map[string]string, endpointConfig *configReader.EndpointConfig) (*configReader.EndpointConfig, error) {
+	args := m.Called(r, pathParams, endpointConfig)
+
+	var rendered *configReader.EndpointConfig
+	if args.Get(0) != nil {
+		rendered = args.Get(0).(*configReader.EndpointConfig)
+	}
+
+	return rendered, args.Error(1)
+}
@@ -0,0 +1,149 @@
+package har
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+)
+
+const (
+	harVersion         = "1.2"
+	defaultHTTPVersion = "HTTP/1.1"
+)
+
+// FromJournal converts the request journal of a service reachable at baseURL
+// into an archive. Each entry's comment names the endpoint that answered it,
+// "fallback", "unmatched" or why the client certificate was rejected, followed
+// by any injected fault and contract violations.
+func FromJournal(baseURL string, entries []requestJournal.RecordedRequest) *HAR {
+	archive := &HAR{Log: Log{
+		Version: harVersion,
+		Creator: Creator{Name: "gockapi", Version: harVersion},
+		Entries: []Entry{},
+	}}
+
+	for _, recorded := range entries {
+		archive.Log.Entries = append(archive.Log.Entries, exportEntry(baseURL, recorded))
+	}
+
+	return archive
+}
+
+func exportEntry(baseURL string, recorded requestJournal.RecordedRequest) Entry {
+	requestURL := strings.TrimSuffix(baseURL, "/") + recorded.Path
+	if recorded.Query != "" {
+		requestURL += "?" + recorded.Query
+	}
+
+	query, _ := url.ParseQuery(recorded.Query)
+
+	httpVersion := recorded.Protocol
+	if httpVersion == "" {
+		httpVersion = defaultHTTPVersion
+	}
+
+	request := Request{
+		Method:      recorded.Method,
+		URL:         requestURL,
+		HTTPVersion: httpVersion,
+		Cookies:     []Cookie{},
+		Headers:     nameValues(recorded.Headers),
+		QueryString: nameValues(query),
+		HeadersSize: -1,
+		BodySize:    len(recorded.Body),
+	}
+
+	if recorded.Body != "" {
+		request.PostData = &PostData{
+			MimeType: recorded.Headers.Get("Content-Type"),
+			Text:     recorded.Body,
+		}
+	}
+
+	comment := recorded.EndpointKey
+	switch {
+	case recorded.Fallback:
+		comment = "fallback"
+	case recorded.ClientCertError != "":
+		comment = "rejected: " + recorded.ClientCertError
+	case comment == "":
+		comment = "unmatched"
+	}
+
+	if recorded.Fault != "" {
+		comment += "; fault: " + recorded.Fault
+	}
+
+	if len(recorded.Violations) > 0 {
+		comment += "; " + strings.Join(recorded.Violations, "; ")
+	}
+
+	entry := Entry{
+		StartedDateTime: recorded.Timestamp,
+		Request:         request,
+		Response:        exportResponse(recorded.Response, httpVersion),
+		Comment:         comment,
+	}
+
+	if recorded.Response != nil {
+		entry.Time = float64(recorded.Response.Duration.Microseconds()) / 1000
+		entry.Timings.Wait = entry.Time
+	}
+
+	return entry
+}
+
+func exportResponse(recorded *requestJournal.RecordedResponse, httpVersion string) Response {
+	response := Response{
+		HTTPVersion: httpVersion,
+		Cookies:     []Cookie{},
+		Headers:     []NameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if recorded == nil {
+		return response
+	}
+
+	response.Status = recorded.StatusCode
+	response.StatusText = http.StatusText(recorded.StatusCode)
+	response.Headers = nameValues(recorded.Headers)
+	response.RedirectURL = recorded.Headers.Get("Location")
+	response.BodySize = len(recorded.Body)
+	response.Content = Content{
+		Size:     len(recorded.Body),
+		MimeType: recorded.Headers.Get("Content-Type"),
+		Text:     recorded.Body,
+	}
+
+	if !utf8.ValidString(recorded.Body) {
+		response.Content.Text = base64.StdEncoding.EncodeToString([]byte(recorded.Body))
+		response.Content.Encoding = "base64"
+	}
+
+	return response
+}
+
+func nameValues[V ~map[string][]string](values V) []NameValue {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	pairs := []NameValue{}
+	for _, name := range names {
+		for _, value := range values[name] {
+			pairs = append(pairs, NameValue{Name: name, Value: value})
+		}
+	}
+
+	return pairs
+}
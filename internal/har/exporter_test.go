@@ -0,0 +1,128 @@
+package har
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJournal(t *testing.T) {
+	timestamp := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	archive := FromJournal("http://localhost:8080/", []requestJournal.RecordedRequest{
+		{
+			Method:      "POST",
+			Path:        "/users",
+			Query:       "dry=1&tag=b&tag=a",
+			Protocol:    "HTTP/2.0",
+			Headers:     http.Header{"Content-Type": {"application/json"}, "Accept": {"*/*"}},
+			Body:        `{"name": "Ada"}`,
+			EndpointKey: "POST /users",
+			Violations:  []string{"body /name: is required"},
+			Timestamp:   timestamp,
+			Response: &requestJournal.RecordedResponse{
+				StatusCode: 201,
+				Headers:    http.Header{"Content-Type": {"application/json"}, "Location": {"/users/1"}},
+				Body:       `{"id": 1}`,
+				Duration:   1500 * time.Microsecond,
+			},
+		},
+	})
+
+	assert.Equal(t, "1.2", archive.Log.Version)
+	assert.Equal(t, "gockapi", archive.Log.Creator.Name)
+	require.Len(t, archive.Log.Entries, 1)
+
+	entry := archive.Log.Entries[0]
+	assert.Equal(t, timestamp, entry.StartedDateTime)
+	assert.Equal(t, 1.5, entry.Time)
+	assert.Equal(t, 1.5, entry.Timings.Wait)
+	assert.Equal(t, "POST /users; body /name: is required", entry.Comment)
+
+	assert.Equal(t, Request{
+		Method:      "POST",
+		URL:         "http://localhost:8080/users?dry=1&tag=b&tag=a",
+		HTTPVersion: "HTTP/2.0",
+		Cookies:     []Cookie{},
+		Headers:     []NameValue{{Name: "Accept", Value: "*/*"}, {Name: "Content-Type", Value: "application/json"}},
+		QueryString: []NameValue{{Name: "dry", Value: "1"}, {Name: "tag", Value: "b"}, {Name: "tag", Value: "a"}},
+		PostData:    &PostData{MimeType: "application/json", Text: `{"name": "Ada"}`},
+		HeadersSize: -1,
+		BodySize:    15,
+	}, entry.Request)
+
+	assert.Equal(t, 201, entry.Response.Status)
+	assert.Equal(t, "Created", entry.Response.StatusText)
+	assert.Equal(t, "HTTP/2.0", entry.Response.HTTPVersion)
+	assert.Equal(t, "/users/1", entry.Response.RedirectURL)
+	assert.Equal(t, Content{Size: 9, MimeType: "application/json", Text: `{"id": 1}`}, entry.Response.Content)
+}
+
+func TestFromJournalComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded requestJournal.RecordedRequest
+		expected string
+	}{
+		{name: "matched", recorded: requestJournal.RecordedRequest{EndpointKey: "GET /users"}, expected: "GET /users"},
+		{name: "unmatched", recorded: requestJournal.RecordedRequest{}, expected: "unmatched"},
+		{name: "fallback", recorded: requestJournal.RecordedRequest{Fallback: true}, expected: "fallback"},
+		{name: "client certificate", recorded: requestJournal.RecordedRequest{ClientCertError: "certificate required"}, expected: "rejected: certificate required"},
+		{name: "fault", recorded: requestJournal.RecordedRequest{EndpointKey: "GET /users", Fault: "connection_reset"}, expected: "GET /users; fault: connection_reset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := FromJournal("http://localhost", []requestJournal.RecordedRequest{tt.recorded})
+			assert.Equal(t, tt.expected, archive.Log.Entries[0].Comment)
+		})
+	}
+}
+
+func TestFromJournalWithoutResponse(t *testing.T) {
+	entry := FromJournal("http://localhost", []requestJournal.RecordedRequest{{Method: "GET", Path: "/slow", Cancelled: true}}).Log.Entries[0]
+
+	assert.Equal(t, "HTTP/1.1", entry.Request.HTTPVersion)
+	assert.Nil(t, entry.Request.PostData)
+	assert.Equal(t, 0, entry.Response.Status)
+	assert.Equal(t, -1, entry.Response.BodySize)
+	assert.Zero(t, entry.Time)
+
+	assert.Empty(t, FromJournal("http://localhost", nil).Log.Entries)
+}
+
+func TestFromJournalBinaryBody(t *testing.T) {
+	entry := FromJournal("http://localhost", []requestJournal.RecordedRequest{{
+		Method:   "GET",
+		Path:     "/logo.png",
+		Response: &requestJournal.RecordedResponse{StatusCode: 200, Headers: http.Header{"Content-Type": {"image/png"}}, Body: "\x89PNG\xff"},
+	}}).Log.Entries[0]
+
+	assert.Equal(t, "base64", entry.Response.Content.Encoding)
+	assert.Equal(t, "iVBOR/8=", entry.Response.Content.Text)
+	assert.Equal(t, 5, entry.Response.Content.Size)
+}
+
+func TestFromJournalRoundTrip(t *testing.T) {
+	archive := FromJournal("http://localhost:8080", []requestJournal.RecordedRequest{
+		{Method: "GET", Path: "/jobs/1", Response: &requestJournal.RecordedResponse{StatusCode: 202, Body: "running"}},
+		{Method: "GET", Path: "/jobs/1", Response: &requestJournal.RecordedResponse{StatusCode: 200, Body: "done"}},
+		{Method: "GET", Path: "/unanswered"},
+	})
+
+	configs := ToServiceConfigs(archive, ImportOptions{})
+	require.Len(t, configs, 1)
+
+	assert.Equal(t, "localhost-8080", configs[0].ServiceName)
+	assert.Equal(t, map[string]configReader.EndpointList{
+		"GET /jobs/1": {{
+			StatusCode: 202,
+			Responses:  []configReader.ResponseConfig{{StatusCode: 202, Body: "running"}, {StatusCode: 200, Body: "done"}},
+		}},
+	}, configs[0].Endpoints)
+}
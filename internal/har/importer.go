@@ -0,0 +1,306 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+)
+
+// ImportOptions controls how an archive becomes service configurations.
+type ImportOptions struct {
+	// Hosts limits the import to the given hosts, written as in the request
+	// URLs ("api.example.com" or "localhost:8080"). Empty imports every host.
+	Hosts []string
+	// BasePort assigns consecutive ports to the services, in name order. It
+	// defaults to 0, letting the port manager pick free ports.
+	BasePort int
+}
+
+// Import loads the archive at path and builds one service per host.
+func Import(path string, options ImportOptions) ([]*configReader.ServiceConfig, error) {
+	archive, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ToServiceConfigs(archive, options), nil
+}
+
+// ToServiceConfigs builds one mock service per host found in the archive,
+// sorted by service name. Entries without a response, such as blocked or
+// aborted requests, are skipped, and so are entries whose decoded path holds
+// "?", "{" or "}", which an endpoint key would read back as a query or a path
+// parameter.
+//
+// Requests with the same method and path become one endpoint. When they differ
+// in query or body, every distinct request becomes a variant matching its own
+// query parameters and body. Distinct responses to the same request are
+// replayed in order as a response sequence.
+func ToServiceConfigs(archive *HAR, options ImportOptions) []*configReader.ServiceConfig {
+	byHost := map[string][]hostEntry{}
+
+	for _, entry := range archive.Log.Entries {
+		parsed, err := url.Parse(entry.Request.URL)
+		if err != nil || parsed.Host == "" || entry.Response.Status <= 0 {
+			continue
+		}
+
+		if strings.ContainsAny(parsed.Path, "?{}") {
+			continue
+		}
+
+		host := strings.ToLower(parsed.Host)
+		if len(options.Hosts) > 0 && !containsFold(options.Hosts, host) {
+			continue
+		}
+
+		byHost[host] = append(byHost[host], hostEntry{entry: entry, url: parsed})
+	}
+
+	configs := []*configReader.ServiceConfig{}
+	for host, entries := range byHost {
+		configs = append(configs, &configReader.ServiceConfig{
+			ServiceName: configReader.ServiceNameFrom(host),
+			Endpoints:   endpointsFor(entries),
+		})
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].ServiceName < configs[j].ServiceName
+	})
+
+	if options.BasePort > 0 {
+		for i, config := range configs {
+			config.Port = options.BasePort + i
+		}
+	}
+
+	return configs
+}
+
+// hostEntry is an archive entry with its parsed request URL.
+type hostEntry struct {
+	entry Entry
+	url   *url.URL
+}
+
+// exchangeGroup collects the responses to one distinct request.
+type exchangeGroup struct {
+	signature string
+	request   Request
+	query     url.Values
+	responses []configReader.ResponseConfig
+}
+
+func endpointsFor(entries []hostEntry) map[string]configReader.EndpointList {
+	keys := []string{}
+	groups := map[string][]*exchangeGroup{}
+
+	for _, item := range entries {
+		entry, parsed := item.entry, item.url
+
+		path := parsed.Path
+		if path == "" {
+			path = "/"
+		}
+
+		key := strings.ToUpper(entry.Request.Method) + " " + path
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+
+		query := parsed.Query()
+		signature := query.Encode() + "\n" + requestBody(entry.Request)
+
+		var group *exchangeGroup
+		for _, existing := range groups[key] {
+			if existing.signature == signature {
+				group = existing
+				break
+			}
+		}
+
+		if group == nil {
+			group = &exchangeGroup{signature: signature, request: entry.Request, query: query}
+			groups[key] = append(groups[key], group)
+		}
+
+		group.responses = append(group.responses, responseConfig(entry.Response))
+	}
+
+	endpoints := make(map[string]configReader.EndpointList, len(keys))
+	for _, key := range keys {
+		variants := configReader.EndpointList{}
+
+		for _, group := range groups[key] {
+			endpoint := endpointConfig(group.responses)
+			if len(groups[key]) > 1 {
+				endpoint.Query = queryMatchers(group.query)
+				endpoint.Match.Body = bodyMatchers(requestBody(group.request))
+			}
+
+			variants = append(variants, endpoint)
+		}
+
+		endpoints[key] = variants
+	}
+
+	return endpoints
+}
+
+// RecordedEndpoint turns a journal entry and its response into an endpoint
+// variant replaying the response to requests with the same method, path,
+// query parameters and body. It returns the endpoint key with the variant.
+func RecordedEndpoint(recorded requestJournal.RecordedRequest) (string, configReader.EndpointConfig) {
+	query, _ := url.ParseQuery(recorded.Query)
+
+	endpoint := endpointConfig([]configReader.ResponseConfig{responseConfig(exportResponse(recorded.Response, defaultHTTPVersion))})
+	endpoint.Query = queryMatchers(query)
+	endpoint.Match.Body = bodyMatchers(recorded.Body)
+
+	return strings.ToUpper(recorded.Method) + " " + recorded.Path, endpoint
+}
+
+// endpointConfig answers with the response, or with the responses in order
+// when the same request got different answers.
+func endpointConfig(responses []configReader.ResponseConfig) configReader.EndpointConfig {
+	distinct := false
+	for _, response := range responses[1:] {
+		if !reflect.DeepEqual(response, responses[0]) {
+			distinct = true
+			break
+		}
+	}
+
+	if !distinct {
+		return configReader.EndpointConfig{
+			StatusCode: responses[0].StatusCode,
+			Headers:    responses[0].Headers,
+			Body:       responses[0].Body,
+		}
+	}
+
+	return configReader.EndpointConfig{
+		StatusCode: responses[0].StatusCode,
+		Responses:  responses,
+	}
+}
+
+// skippedResponseHeaders are recomputed when the mock writes its response.
+var skippedResponseHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Encoding":  true,
+	"Content-Length":    true,
+	"Date":              true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+}
+
+func responseConfig(response Response) configReader.ResponseConfig {
+	config := configReader.ResponseConfig{StatusCode: response.Status}
+
+	for _, header := range response.Headers {
+		name := http.CanonicalHeaderKey(header.Name)
+		if strings.HasPrefix(name, ":") || skippedResponseHeaders[name] {
+			continue
+		}
+
+		if config.Headers == nil {
+			config.Headers = make(map[string]string)
+		}
+
+		if existing, ok := config.Headers[name]; ok {
+			config.Headers[name] = existing + ", " + header.Value
+			continue
+		}
+
+		config.Headers[name] = header.Value
+	}
+
+	text := response.Content.Text
+	if response.Content.Encoding == "base64" {
+		if decoded, err := base64.StdEncoding.DecodeString(text); err == nil {
+			text = string(decoded)
+		}
+	}
+
+	if text == "" {
+		return config
+	}
+
+	config.Body = text
+
+	if config.Headers["Content-Type"] == "" && response.Content.MimeType != "" {
+		if config.Headers == nil {
+			config.Headers = make(map[string]string)
+		}
+
+		config.Headers["Content-Type"] = response.Content.MimeType
+	}
+
+	if strings.Contains(response.Content.MimeType, "json") {
+		var value any
+		if err := json.Unmarshal([]byte(text), &value); err == nil {
+			config.Body = value
+		}
+	}
+
+	return config
+}
+
+func requestBody(request Request) string {
+	if request.PostData == nil {
+		return ""
+	}
+
+	return request.PostData.Text
+}
+
+func queryMatchers(query url.Values) map[string]configReader.ValueMatcher {
+	if len(query) == 0 {
+		return nil
+	}
+
+	matchers := make(map[string]configReader.ValueMatcher, len(query))
+	for name, values := range query {
+		if len(values) == 1 {
+			matchers[name] = configReader.ValueMatcher{EqualTo: values[0]}
+			continue
+		}
+
+		matchers[name] = configReader.ValueMatcher{Values: values}
+	}
+
+	return matchers
+}
+
+func bodyMatchers(body string) []configReader.BodyMatcher {
+	if body == "" {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(body), &value); err == nil {
+		return []configReader.BodyMatcher{{EqualToJSON: value}}
+	}
+
+	return []configReader.BodyMatcher{{Matches: "^" + regexp.QuoteMeta(body) + "$"}}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+
+	return false
+}
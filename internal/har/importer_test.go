@@ -0,0 +1,225 @@
+package har
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entry(method, url string, status int, body string) Entry {
+	return Entry{
+		Request: Request{Method: method, URL: url},
+		Response: Response{
+			Status:  status,
+			Headers: []NameValue{{Name: "content-type", Value: "application/json"}},
+			Content: Content{MimeType: "application/json", Text: body},
+		},
+	}
+}
+
+func withRequestBody(e Entry, body string) Entry {
+	e.Request.PostData = &PostData{MimeType: "application/json", Text: body}
+	return e
+}
+
+func archiveOf(entries ...Entry) *HAR {
+	return &HAR{Log: Log{Version: harVersion, Entries: entries}}
+}
+
+func TestToServiceConfigsGroupsByHost(t *testing.T) {
+	archive := archiveOf(
+		entry("GET", "https://api.example.com/users", 200, `[]`),
+		entry("get", "https://API.example.com/users/1", 200, `{"id": 1}`),
+		entry("GET", "http://localhost:8080", 204, ""),
+		entry("GET", "https://cdn.example.com/app.js", 0, ""),
+		entry("GET", "/relative", 200, ""),
+		entry("GET", "https://api.example.com/a%3Fb", 200, ""),
+		entry("GET", "https://api.example.com/files/%7Bid%7D", 200, ""),
+		entry("GET", "https://api.example.com/%zz", 200, ""),
+	)
+
+	configs := ToServiceConfigs(archive, ImportOptions{})
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "api-example-com", configs[0].ServiceName)
+	assert.Equal(t, 0, configs[0].Port)
+	assert.ElementsMatch(t, []string{"GET /users", "GET /users/1"}, keys(configs[0].Endpoints))
+
+	assert.Equal(t, "localhost-8080", configs[1].ServiceName)
+	assert.Equal(t, configReader.EndpointList{{StatusCode: 204, Headers: map[string]string{"Content-Type": "application/json"}}}, configs[1].Endpoints["GET /"])
+}
+
+func TestToServiceConfigsOptions(t *testing.T) {
+	archive := archiveOf(
+		entry("GET", "https://b.example.com/", 200, ""),
+		entry("GET", "https://a.example.com/", 200, ""),
+		entry("GET", "https://c.example.com/", 200, ""),
+	)
+
+	configs := ToServiceConfigs(archive, ImportOptions{Hosts: []string{"C.example.com", "a.example.com"}, BasePort: 9000})
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "a-example-com", configs[0].ServiceName)
+	assert.Equal(t, 9000, configs[0].Port)
+	assert.Equal(t, "c-example-com", configs[1].ServiceName)
+	assert.Equal(t, 9001, configs[1].Port)
+}
+
+func TestToServiceConfigsVariants(t *testing.T) {
+	archive := archiveOf(
+		entry("GET", "https://api.example.com/search?q=a", 200, `{"hits": 1}`),
+		entry("GET", "https://api.example.com/search?q=b&tag=x&tag=y", 200, `{"hits": 2}`),
+		entry("GET", "https://api.example.com/search?q=a", 200, `{"hits": 1}`),
+		withRequestBody(entry("POST", "https://api.example.com/users", 201, `{"id": 1}`), `{"name": "Ada"}`),
+		withRequestBody(entry("POST", "https://api.example.com/users", 400, `{"error": "bad"}`), `name=`),
+	)
+
+	configs := ToServiceConfigs(archive, ImportOptions{})
+	require.Len(t, configs, 1)
+
+	search := configs[0].Endpoints["GET /search"]
+	require.Len(t, search, 2, "repeated requests with the same answer share a variant")
+	assert.Equal(t, map[string]configReader.ValueMatcher{"q": {EqualTo: "a"}}, search[0].Query)
+	assert.Equal(t, map[string]any{"hits": float64(1)}, search[0].Body)
+	assert.Nil(t, search[0].Responses)
+	assert.Equal(t, map[string]configReader.ValueMatcher{"q": {EqualTo: "b"}, "tag": {Values: []string{"x", "y"}}}, search[1].Query)
+
+	users := configs[0].Endpoints["POST /users"]
+	require.Len(t, users, 2)
+	assert.Equal(t, 201, users[0].StatusCode)
+	assert.Equal(t, []configReader.BodyMatcher{{EqualToJSON: map[string]any{"name": "Ada"}}}, users[0].Match.Body)
+	assert.Equal(t, 400, users[1].StatusCode)
+	assert.Equal(t, []configReader.BodyMatcher{{Matches: "^name=$"}}, users[1].Match.Body)
+}
+
+func TestToServiceConfigsSingleVariantHasNoMatchers(t *testing.T) {
+	archive := archiveOf(withRequestBody(entry("POST", "https://api.example.com/users?dry=1", 201, `{}`), `{"name": "Ada"}`))
+
+	endpoint := ToServiceConfigs(archive, ImportOptions{})[0].Endpoints["POST /users"][0]
+
+	assert.Nil(t, endpoint.Query)
+	assert.Nil(t, endpoint.Match.Body)
+}
+
+func TestToServiceConfigsSequences(t *testing.T) {
+	archive := archiveOf(
+		entry("GET", "https://api.example.com/jobs/1", 202, `{"state": "running"}`),
+		entry("GET", "https://api.example.com/jobs/1", 202, `{"state": "running"}`),
+		entry("GET", "https://api.example.com/jobs/1", 200, `{"state": "done"}`),
+	)
+
+	endpoint := ToServiceConfigs(archive, ImportOptions{})[0].Endpoints["GET /jobs/1"][0]
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	assert.Equal(t, 202, endpoint.StatusCode)
+	assert.Nil(t, endpoint.Body)
+	assert.Equal(t, []configReader.ResponseConfig{
+		{StatusCode: 202, Headers: headers, Body: map[string]any{"state": "running"}},
+		{StatusCode: 202, Headers: headers, Body: map[string]any{"state": "running"}},
+		{StatusCode: 200, Headers: headers, Body: map[string]any{"state": "done"}},
+	}, endpoint.Responses)
+}
+
+func TestResponseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		response Response
+		expected configReader.ResponseConfig
+	}{
+		{
+			name: "headers",
+			response: Response{Status: 200, Headers: []NameValue{
+				{Name: "set-cookie", Value: "a=1"},
+				{Name: "Set-Cookie", Value: "b=2"},
+				{Name: "content-length", Value: "3"},
+				{Name: ":status", Value: "200"},
+				{Name: "Content-Encoding", Value: "gzip"},
+			}},
+			expected: configReader.ResponseConfig{StatusCode: 200, Headers: map[string]string{"Set-Cookie": "a=1, b=2"}},
+		},
+		{
+			name:     "text",
+			response: Response{Status: 200, Content: Content{MimeType: "text/plain", Text: "hi"}},
+			expected: configReader.ResponseConfig{StatusCode: 200, Headers: map[string]string{"Content-Type": "text/plain"}, Body: "hi"},
+		},
+		{
+			name:     "base64",
+			response: Response{Status: 200, Content: Content{MimeType: "application/json", Text: "eyJvayI6dHJ1ZX0=", Encoding: "base64"}},
+			expected: configReader.ResponseConfig{StatusCode: 200, Headers: map[string]string{"Content-Type": "application/json"}, Body: map[string]any{"ok": true}},
+		},
+		{
+			name:     "invalid JSON kept as text",
+			response: Response{Status: 500, Content: Content{MimeType: "application/json", Text: "{oops"}},
+			expected: configReader.ResponseConfig{StatusCode: 500, Headers: map[string]string{"Content-Type": "application/json"}, Body: "{oops"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, responseConfig(tt.response))
+		})
+	}
+}
+
+func TestRecordedEndpoint(t *testing.T) {
+	key, endpoint := RecordedEndpoint(requestJournal.RecordedRequest{
+		Method: "post",
+		Path:   "/users",
+		Query:  "dry=1",
+		Body:   `{"name": "Ada"}`,
+		Response: &requestJournal.RecordedResponse{
+			StatusCode: 201,
+			Headers:    http.Header{"Content-Type": {"application/json"}},
+			Body:       `{"id": 1}`,
+		},
+	})
+
+	assert.Equal(t, "POST /users", key)
+	assert.Equal(t, configReader.EndpointConfig{
+		StatusCode: 201,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       map[string]any{"id": float64(1)},
+		Query:      map[string]configReader.ValueMatcher{"dry": {EqualTo: "1"}},
+		Match:      configReader.MatchConfig{Body: []configReader.BodyMatcher{{EqualToJSON: map[string]any{"name": "Ada"}}}},
+	}, endpoint)
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+
+	data, err := json.Marshal(archiveOf(entry("GET", "https://api.example.com/health", 200, `{"ok": true}`)))
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "session.har")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	configs, err := Import(path, ImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Contains(t, configs[0].Endpoints, "GET /health")
+
+	empty := filepath.Join(dir, "empty.har")
+	require.NoError(t, os.WriteFile(empty, []byte(`{"log": {"entries": []}}`), 0o644))
+
+	_, err = Import(empty, ImportOptions{})
+	assert.ErrorContains(t, err, "has no entries")
+
+	_, err = Import(filepath.Join(dir, "missing.har"), ImportOptions{})
+	assert.Error(t, err)
+}
+
+func keys(endpoints map[string]configReader.EndpointList) []string {
+	names := []string{}
+	for key := range endpoints {
+		names = append(names, key)
+	}
+
+	return names
+}
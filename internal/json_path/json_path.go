@@ -0,0 +1,256 @@
+package json_path
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Expression is a compiled JSONPath selector with an optional comparison, such
+// as "$.amount > 1000", "$.items[0].sku == 'A-1'" or "$.customer.email".
+// Without a comparison the expression holds when the selector resolves.
+type Expression struct {
+	source   string
+	segments []segment
+	operator string
+	operand  any
+	regex    *regexp.Regexp
+}
+
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+var operators = []string{"==", "!=", ">=", "<=", "=~", ">", "<"}
+
+func Compile(expression string) (*Expression, error) {
+	source := strings.TrimSpace(expression)
+	if !strings.HasPrefix(source, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", expression)
+	}
+
+	segments, rest, err := parseSegments(source[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid json path %q: %w", expression, err)
+	}
+
+	compiled := &Expression{source: source, segments: segments}
+
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return compiled, nil
+	}
+
+	for _, operator := range operators {
+		if strings.HasPrefix(rest, operator) {
+			compiled.operator = operator
+			break
+		}
+	}
+
+	if compiled.operator == "" {
+		return nil, fmt.Errorf("invalid json path %q: unexpected %q", expression, rest)
+	}
+
+	literal := strings.TrimSpace(rest[len(compiled.operator):])
+	if literal == "" {
+		return nil, fmt.Errorf("invalid json path %q: missing value after %s", expression, compiled.operator)
+	}
+
+	compiled.operand, err = parseLiteral(literal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json path %q: %w", expression, err)
+	}
+
+	switch compiled.operator {
+	case "=~":
+		pattern, ok := compiled.operand.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid json path %q: =~ requires a string pattern", expression)
+		}
+
+		compiled.regex, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json path %q: %w", expression, err)
+		}
+	case ">", ">=", "<", "<=":
+		if _, ok := compiled.operand.(float64); !ok {
+			return nil, fmt.Errorf("invalid json path %q: %s requires a number", expression, compiled.operator)
+		}
+	}
+
+	return compiled, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Select returns every value the selector resolves to in the document.
+func (e *Expression) Select(document any) []any {
+	current := []any{document}
+
+	for _, seg := range e.segments {
+		next := []any{}
+
+		for _, value := range current {
+			switch {
+			case seg.wildcard:
+				switch typed := value.(type) {
+				case []any:
+					next = append(next, typed...)
+				case map[string]any:
+					for _, child := range typed {
+						next = append(next, child)
+					}
+				}
+			case seg.isIndex:
+				if list, ok := value.([]any); ok {
+					index := seg.index
+					if index < 0 {
+						index += len(list)
+					}
+
+					if index >= 0 && index < len(list) {
+						next = append(next, list[index])
+					}
+				}
+			default:
+				if object, ok := value.(map[string]any); ok {
+					if child, exists := object[seg.key]; exists {
+						next = append(next, child)
+					}
+				}
+			}
+		}
+
+		current = next
+	}
+
+	return current
+}
+
+// Evaluate reports whether any value selected from the document satisfies the
+// comparison, or whether the selector resolved at all when there is none.
+func (e *Expression) Evaluate(document any) bool {
+	for _, value := range e.Select(document) {
+		if e.compare(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Expression) compare(value any) bool {
+	switch e.operator {
+	case "":
+		return true
+	case "==":
+		return reflect.DeepEqual(value, e.operand)
+	case "!=":
+		return !reflect.DeepEqual(value, e.operand)
+	case "=~":
+		text, ok := value.(string)
+		return ok && e.regex.MatchString(text)
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		return false
+	}
+
+	operand := e.operand.(float64)
+
+	switch e.operator {
+	case ">":
+		return number > operand
+	case ">=":
+		return number >= operand
+	case "<":
+		return number < operand
+	case "<=":
+		return number <= operand
+	}
+
+	return false
+}
+
+func parseSegments(path string) ([]segment, string, error) {
+	segments := []segment{}
+
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			end := 1
+			for end < len(path) && isKeyChar(path[end]) {
+				end++
+			}
+
+			key := path[1:end]
+			if key == "" {
+				return nil, "", fmt.Errorf("empty key")
+			}
+
+			if key == "*" {
+				segments = append(segments, segment{wildcard: true})
+			} else {
+				segments = append(segments, segment{key: key})
+			}
+
+			path = path[end:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, "", fmt.Errorf("unclosed [")
+			}
+
+			inner := strings.TrimSpace(path[1:end])
+			path = path[end+1:]
+
+			switch {
+			case inner == "*":
+				segments = append(segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, "", fmt.Errorf("invalid index %q", inner)
+				}
+
+				segments = append(segments, segment{index: index, isIndex: true})
+			}
+		default:
+			return segments, path, nil
+		}
+	}
+
+	return segments, "", nil
+}
+
+func isKeyChar(char byte) bool {
+	return char == '_' || char == '-' || char == '*' ||
+		(char >= 'a' && char <= 'z') ||
+		(char >= 'A' && char <= 'Z') ||
+		(char >= '0' && char <= '9')
+}
+
+func parseLiteral(literal string) (any, error) {
+	if len(literal) >= 2 && literal[0] == '\'' && literal[len(literal)-1] == '\'' {
+		return literal[1 : len(literal)-1], nil
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(literal), &value); err != nil {
+		return nil, fmt.Errorf("invalid value %s", literal)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,106 @@
+package json_path
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const paymentDocument = `{
+	"amount": 1500,
+	"currency": "USD",
+	"customer": {"email": "jane@example.com", "tier": "gold"},
+	"items": [
+		{"sku": "A-1", "quantity": 2},
+		{"sku": "B-2", "quantity": 1}
+	],
+	"metadata": {"source": "web", "retry": false},
+	"weird-key": "ok"
+}`
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "missing root", expression: "amount > 1"},
+		{name: "empty key", expression: "$..amount"},
+		{name: "unclosed bracket", expression: "$.items[0"},
+		{name: "invalid index", expression: "$.items[first]"},
+		{name: "unknown operator", expression: "$.amount ~ 1"},
+		{name: "missing operand", expression: "$.amount >"},
+		{name: "invalid literal", expression: "$.amount == abc"},
+		{name: "numeric operator with string", expression: "$.amount > 'big'"},
+		{name: "regex with number", expression: "$.currency =~ 1"},
+		{name: "invalid regex", expression: "$.currency =~ '('"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expression)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(paymentDocument), &document))
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   bool
+	}{
+		{name: "root exists", expression: "$", expected: true},
+		{name: "field exists", expression: "$.customer.email", expected: true},
+		{name: "missing field", expression: "$.customer.phone", expected: false},
+		{name: "greater than", expression: "$.amount > 1000", expected: true},
+		{name: "greater than fails", expression: "$.amount > 1500", expected: false},
+		{name: "greater or equal", expression: "$.amount >= 1500", expected: true},
+		{name: "less than", expression: "$.amount < 1000", expected: false},
+		{name: "less or equal", expression: "$.amount <= 1500", expected: true},
+		{name: "numeric operator on string", expression: "$.currency > 1", expected: false},
+		{name: "equal single quoted string", expression: "$.currency == 'USD'", expected: true},
+		{name: "equal double quoted string", expression: `$.currency == "USD"`, expected: true},
+		{name: "equal number", expression: "$.items[0].quantity == 2", expected: true},
+		{name: "equal boolean", expression: "$.metadata.retry == false", expected: true},
+		{name: "not equal", expression: "$.currency != 'EUR'", expected: true},
+		{name: "regex", expression: "$.customer.email =~ '@example\\.com$'", expected: true},
+		{name: "regex on number", expression: "$.amount =~ '1'", expected: false},
+		{name: "index", expression: "$.items[1].sku == 'B-2'", expected: true},
+		{name: "negative index", expression: "$.items[-1].sku == 'B-2'", expected: true},
+		{name: "index out of range", expression: "$.items[5]", expected: false},
+		{name: "negative index out of range", expression: "$.items[-3]", expected: false},
+		{name: "bracket key", expression: "$['customer']['tier'] == 'gold'", expected: true},
+		{name: "key with dash", expression: "$.weird-key == 'ok'", expected: true},
+		{name: "array wildcard", expression: "$.items[*].sku == 'B-2'", expected: true},
+		{name: "dot wildcard", expression: "$.items.*.quantity > 1", expected: true},
+		{name: "object wildcard", expression: "$.metadata.* == 'web'", expected: true},
+		{name: "wildcard without match", expression: "$.items[*].sku == 'C-3'", expected: false},
+		{name: "index on object", expression: "$.customer[0]", expected: false},
+		{name: "key on array", expression: "$.items.sku", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := Compile(tt.expression)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, compiled.Evaluate(document))
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(paymentDocument), &document))
+
+	compiled, err := Compile("$.items[*].sku")
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"A-1", "B-2"}, compiled.Select(document))
+	assert.Equal(t, "$.items[*].sku", compiled.String())
+}
@@ -2,17 +2,24 @@ package manager
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
-	"path/filepath"
+	"net/http"
+	"os"
+	"slices"
 	"strings"
 	"sync"
 
 	configReader "github.com/JTGlez/gockapi/internal/config_reader"
 	"github.com/JTGlez/gockapi/internal/config_reader/impl"
 	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	"github.com/JTGlez/gockapi/internal/har"
+	certAuthority "github.com/JTGlez/gockapi/internal/server/cert_authority"
 	mockServer "github.com/JTGlez/gockapi/internal/server/mock_server"
 	portManager "github.com/JTGlez/gockapi/internal/server/port_manager"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
 )
 
 type MockManager struct {
@@ -20,8 +27,22 @@ type MockManager struct {
 	configReader configReader.ConfigReader
 	portManager  portManager.PortManager
 	configPath   string
+	inMemory     map[string]bool
 	running      bool
+	ephemeral    bool
 	mu           sync.RWMutex
+	recordMu     sync.Mutex
+	caOnce       sync.Once
+	ca           certAuthority.CertificateAuthority
+	caErr        error
+}
+
+// RecordOptions configures a recording session. Target is the base URL of
+// the upstream that answers the requests the service does not match. Port is
+// used only when the service has no config file yet.
+type RecordOptions struct {
+	Target string
+	Port   int
 }
 
 type ServiceStatus struct {
@@ -40,11 +61,27 @@ func NewMockManager(configPath string) *MockManager {
 		servers:      make(map[string]mockServer.MockServer),
 		configReader: impl.NewConfigReader(configPath),
 		portManager:  portManager.NewPortManager(),
+		inMemory:     make(map[string]bool),
 		configPath:   configPath,
 		running:      false,
 	}
 }
 
+// SetEphemeralPorts makes services started afterwards listen on a free port
+// picked by the system instead of the port in their configuration.
+func (m *MockManager) SetEphemeralPorts(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ephemeral = enabled
+}
+
+// RegisterDecoder adds support for another configuration format to the config
+// directory. Services already running are not reloaded.
+func (m *MockManager) RegisterDecoder(decoder configReader.ConfigDecoder) {
+	m.configReader.RegisterDecoder(decoder)
+}
+
 func (m *MockManager) StartAll(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -53,17 +90,9 @@ func (m *MockManager) StartAll(ctx context.Context) error {
 		return fmt.Errorf("mock manager is already running")
 	}
 
-	pattern := filepath.Join(m.configPath, "*.json")
-	files, err := filepath.Glob(pattern)
+	services, err := m.configReader.ListServices()
 	if err != nil {
-		return fmt.Errorf("failed to list config files: %w", err)
-	}
-
-	services := []string{}
-	for _, file := range files {
-		base := filepath.Base(file)
-		serviceName := strings.TrimSuffix(base, filepath.Ext(base))
-		services = append(services, serviceName)
+		return err
 	}
 
 	failedServices := []string{}
@@ -96,6 +125,32 @@ func (m *MockManager) StartService(ctx context.Context, serviceName string) erro
 	return m.startServiceInternal(ctx, serviceName)
 }
 
+// StartServiceConfig starts a service from a configuration built in memory
+// instead of a file in the config directory. It is validated like a file
+// config but is not watched for changes.
+func (m *MockManager) StartServiceConfig(ctx context.Context, cfg *configReader.ServiceConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration cannot be nil")
+	}
+
+	err := m.configReader.ValidateConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid config for %s: %w", cfg.ServiceName, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err = m.startServer(ctx, cfg.ServiceName, cfg)
+	if err != nil {
+		return err
+	}
+
+	m.inMemory[cfg.ServiceName] = true
+
+	return nil
+}
+
 func (m *MockManager) startServiceInternal(ctx context.Context, serviceName string) error {
 	if _, exists := m.servers[serviceName]; exists {
 		return fmt.Errorf("service %s is already running", serviceName)
@@ -106,42 +161,270 @@ func (m *MockManager) startServiceInternal(ctx context.Context, serviceName stri
 		return fmt.Errorf("failed to read config for %s: %w", serviceName, err)
 	}
 
-	port, err := m.portManager.AllocatePort(serviceName, cfg.Port)
+	err = m.startServer(ctx, serviceName, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to allocate port for %s: %w", serviceName, err)
+		return err
 	}
 
-	cfg.Port = port
+	err = m.configReader.WatchForChanges(serviceName, func(newConfig *configReader.ServiceConfig) {
+		m.handleConfigChange(serviceName)
+	})
+
+	if err != nil {
+		log.Printf("Warning: failed to setup hot-reload for %s: %v\n", serviceName, err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *MockManager) startServer(ctx context.Context, serviceName string, cfg *configReader.ServiceConfig) error {
+	if _, exists := m.servers[serviceName]; exists {
+		return fmt.Errorf("service %s is already running", serviceName)
+	}
+
+	preferredPort := cfg.Port
+	if m.ephemeral {
+		preferredPort = 0
+	}
+
+	// A port of 0 is bound by the server itself and reserved once it is known.
+	var err error
+	if preferredPort != 0 {
+		_, err = m.portManager.AllocatePort(serviceName, preferredPort)
+		if err != nil {
+			return fmt.Errorf("failed to allocate port for %s: %w", serviceName, err)
+		}
+	}
 
-	responseHandler := handlers.NewResponseHandler()
+	cfg.Port = preferredPort
 
-	httpMockServer := mockServer.NewHTTPMockServer(serviceName, cfg, responseHandler)
+	var server mockServer.MockServer
+	if cfg.GRPC != nil {
+		server = mockServer.NewGRPCMockServer(serviceName, cfg)
+	} else {
+		server = mockServer.NewHTTPMockServer(serviceName, cfg, handlers.NewResponseHandler())
+	}
 
-	err = httpMockServer.Start(ctx)
+	if cfg.TLS.UsesGeneratedCA() {
+		err = m.useCertificateAuthority(server)
+		if err != nil {
+			m.portManager.ReleasePort(serviceName)
+			return fmt.Errorf("failed to configure tls for %s: %w", serviceName, err)
+		}
+	}
+
+	err = server.Start(ctx)
 	if err != nil {
 		m.portManager.ReleasePort(serviceName)
 		return fmt.Errorf("failed to start server for %s: %w", serviceName, err)
 	}
 
-	m.servers[serviceName] = httpMockServer
+	if preferredPort == 0 {
+		err = m.portManager.ReservePort(serviceName, server.GetPort())
+		if err != nil {
+			server.Stop()
+			return fmt.Errorf("failed to reserve port for %s: %w", serviceName, err)
+		}
+	}
 
-	err = m.configReader.WatchForChanges(serviceName, func(newConfig *configReader.ServiceConfig) {
-		m.handleConfigChange(serviceName)
+	m.servers[serviceName] = server
+
+	return nil
+}
+
+func (m *MockManager) useCertificateAuthority(server mockServer.MockServer) error {
+	tlsServer, ok := server.(mockServer.TLSServer)
+	if !ok {
+		return fmt.Errorf("server does not support tls")
+	}
+
+	ca, err := m.certificateAuthority()
+	if err != nil {
+		return err
+	}
+
+	tlsServer.SetCertificateAuthority(ca)
+
+	return nil
+}
+
+// certificateAuthority returns the CA issuing the certificates of the HTTPS
+// services without certificate files, generated on first use.
+func (m *MockManager) certificateAuthority() (certAuthority.CertificateAuthority, error) {
+	m.caOnce.Do(func() {
+		m.ca, m.caErr = certAuthority.NewCertificateAuthority("gockapi CA")
 	})
 
+	return m.ca, m.caErr
+}
+
+// CACertificate returns the PEM certificate of the CA generated for HTTPS
+// services without certificate files.
+func (m *MockManager) CACertificate() ([]byte, error) {
+	ca, err := m.certificateAuthority()
+	if err != nil {
+		return nil, err
+	}
+
+	return ca.CertPEM(), nil
+}
+
+// GetCACertificate returns the PEM certificate clients should trust to reach
+// an HTTPS service.
+func (m *MockManager) GetCACertificate(serviceName string) ([]byte, error) {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsServer, ok := server.(mockServer.TLSServer)
+	if !ok || tlsServer.CACertificate() == nil {
+		return nil, fmt.Errorf("service %s does not serve https", serviceName)
+	}
+
+	return tlsServer.CACertificate(), nil
+}
+
+// IssueClientCertificate issues a client certificate signed by the generated
+// CA, accepted by the services verifying client certificates without a CA file.
+func (m *MockManager) IssueClientCertificate(commonName string, sans []string) (*tls.Certificate, error) {
+	ca, err := m.certificateAuthority()
+	if err != nil {
+		return nil, err
+	}
+
+	return ca.IssueClientCertificate(commonName, sans)
+}
+
+// TLSConfig returns a client TLS config trusting the generated CA and the
+// certificates of every running HTTPS service, presenting the given client
+// certificates.
+func (m *MockManager) TLSConfig(clientCerts ...tls.Certificate) (*tls.Config, error) {
+	caPEM, err := m.CACertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, server := range m.servers {
+		if tlsServer, ok := server.(mockServer.TLSServer); ok && tlsServer.CACertificate() != nil {
+			pool.AppendCertsFromPEM(tlsServer.CACertificate())
+		}
+	}
+
+	return &tls.Config{RootCAs: pool, Certificates: clientCerts}, nil
+}
+
+// StartRecording forwards the requests a service does not match to the
+// upstream and saves every exchange as a new endpoint variant, in memory and
+// in the service's config file unless it was started from an in-memory
+// config. A service that is not running is started first, from an empty
+// config when it has no file yet; the file is created with the first
+// recorded exchange.
+func (m *MockManager) StartRecording(ctx context.Context, serviceName string, options RecordOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.servers[serviceName]; !exists {
+		if err := m.startRecordingServer(ctx, serviceName, options.Port); err != nil {
+			return err
+		}
+	}
+
+	recorder, ok := m.servers[serviceName].(mockServer.TrafficRecorder)
+	if !ok {
+		return fmt.Errorf("service %s does not support recording", serviceName)
+	}
+
+	// A new config file gets the port actually bound, which differs from
+	// options.Port when that is 0.
+	port := m.servers[serviceName].GetPort()
+
+	var onRecord func(string, configReader.EndpointConfig)
+	if !m.inMemory[serviceName] {
+		onRecord = func(endpointKey string, endpoint configReader.EndpointConfig) {
+			if err := m.saveRecordedEndpoint(serviceName, port, endpointKey, endpoint); err != nil {
+				log.Printf("Warning: failed to save recorded endpoint %s for %s: %v\n", endpointKey, serviceName, err)
+			}
+		}
+	}
+
+	return recorder.StartRecording(options.Target, onRecord)
+}
+
+func (m *MockManager) StopRecording(serviceName string) error {
+	server, err := m.getServer(serviceName)
 	if err != nil {
-		log.Printf("Warning: failed to setup hot-reload for %s: %v\n", serviceName, err)
 		return err
 	}
 
+	recorder, ok := server.(mockServer.TrafficRecorder)
+	if !ok {
+		return fmt.Errorf("service %s does not support recording", serviceName)
+	}
+
+	recorder.StopRecording()
+
 	return nil
 }
 
+func (m *MockManager) startRecordingServer(ctx context.Context, serviceName string, port int) error {
+	if _, err := os.Stat(m.configReader.GetConfigPath(serviceName)); err == nil {
+		return m.startServiceInternal(ctx, serviceName)
+	}
+
+	// A new service has no endpoints yet, which a config file may not have,
+	// so it skips validation until its first exchange is recorded.
+	if err := impl.NewConfigValidator().ValidateServiceName(serviceName); err != nil {
+		return err
+	}
+
+	return m.startServer(ctx, serviceName, &configReader.ServiceConfig{
+		ServiceName: serviceName,
+		Port:        port,
+		Endpoints:   make(map[string]configReader.EndpointList),
+	})
+}
+
+func (m *MockManager) saveRecordedEndpoint(serviceName string, port int, endpointKey string, endpoint configReader.EndpointConfig) error {
+	m.recordMu.Lock()
+	defer m.recordMu.Unlock()
+
+	cfg := &configReader.ServiceConfig{
+		ServiceName: serviceName,
+		Port:        port,
+		Endpoints:   make(map[string]configReader.EndpointList),
+	}
+
+	if _, err := os.Stat(m.configReader.GetConfigPath(serviceName)); err == nil {
+		cfg, err = m.configReader.ReadServiceConfig(serviceName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if slices.ContainsFunc(cfg.Endpoints[endpointKey], func(existing configReader.EndpointConfig) bool {
+		return mockServer.SameEndpoint(existing, endpoint)
+	}) {
+		return nil
+	}
+
+	cfg.Endpoints[endpointKey] = append(cfg.Endpoints[endpointKey], endpoint)
+
+	return m.configReader.WriteServiceConfig(cfg)
+}
+
 func (m *MockManager) StopAll() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if !m.running {
+	if !m.running && len(m.servers) == 0 {
 		return fmt.Errorf("mock manager is not running")
 	}
 
@@ -159,6 +442,7 @@ func (m *MockManager) StopAll() error {
 	}
 
 	m.servers = make(map[string]mockServer.MockServer)
+	m.inMemory = make(map[string]bool)
 	m.running = false
 
 	if len(errors) > 0 {
@@ -187,6 +471,7 @@ func (m *MockManager) StopService(serviceName string) error {
 	m.configReader.StopWatching(serviceName)
 
 	delete(m.servers, serviceName)
+	delete(m.inMemory, serviceName)
 
 	return nil
 }
@@ -196,6 +481,10 @@ func (m *MockManager) ReloadAll() error {
 
 	serviceNames := make([]string, 0, len(m.servers))
 	for serviceName := range m.servers {
+		if m.inMemory[serviceName] {
+			continue
+		}
+
 		serviceNames = append(serviceNames, serviceName)
 	}
 	m.mu.RUnlock()
@@ -219,17 +508,26 @@ func (m *MockManager) ReloadAll() error {
 func (m *MockManager) ReloadService(serviceName string) error {
 	m.mu.RLock()
 	server, exists := m.servers[serviceName]
+	inMemory := m.inMemory[serviceName]
 	m.mu.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("service %s is not running", serviceName)
 	}
 
+	if inMemory {
+		return fmt.Errorf("service %s was started from an in-memory config and has no file to reload", serviceName)
+	}
+
 	newConfig, err := m.configReader.ReadServiceConfig(serviceName)
 	if err != nil {
 		return fmt.Errorf("failed to read new config for %s: %w", serviceName, err)
 	}
 
+	if newConfig.Port == 0 || m.ephemeral {
+		newConfig.Port = server.GetPort()
+	}
+
 	err = server.Reload(newConfig)
 	if err != nil {
 		return fmt.Errorf("failed to reload server for %s: %w", serviceName, err)
@@ -287,6 +585,165 @@ func (m *MockManager) GetRunningServices() []string {
 	return services
 }
 
+// GetServiceURL returns the base URL of a running service, or the scheme-less
+// address to dial for gRPC services.
+func (m *MockManager) GetServiceURL(serviceName string) (string, error) {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return "", err
+	}
+
+	return server.GetURL(), nil
+}
+
+func (m *MockManager) ResetCounters(serviceName string) error {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return err
+	}
+
+	counterController, ok := server.(mockServer.CounterController)
+	if !ok {
+		return fmt.Errorf("service %s does not track call counters", serviceName)
+	}
+
+	counterController.ResetCounters()
+
+	return nil
+}
+
+func (m *MockManager) GetCallCounts(serviceName string) (map[string]int, error) {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	counterController, ok := server.(mockServer.CounterController)
+	if !ok {
+		return nil, fmt.Errorf("service %s does not track call counters", serviceName)
+	}
+
+	return counterController.GetCallCounts(), nil
+}
+
+func (m *MockManager) HandleFunc(serviceName, method, path string, handler http.HandlerFunc) error {
+	registrar, err := m.getHandlerRegistrar(serviceName)
+	if err != nil {
+		return err
+	}
+
+	err = registrar.HandleFunc(method, path, handler)
+	if err != nil {
+		return fmt.Errorf("failed to register handler for %s %s on %s: %w", method, path, serviceName, err)
+	}
+
+	return nil
+}
+
+func (m *MockManager) RemoveHandler(serviceName, method, path string) error {
+	registrar, err := m.getHandlerRegistrar(serviceName)
+	if err != nil {
+		return err
+	}
+
+	if !registrar.RemoveHandler(method, path) {
+		return fmt.Errorf("no handler registered for %s %s on %s", method, path, serviceName)
+	}
+
+	return nil
+}
+
+func (m *MockManager) GetRecordedRequests(serviceName string) ([]requestJournal.RecordedRequest, error) {
+	recorder, err := m.getRequestRecorder(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return recorder.GetRecordedRequests(), nil
+}
+
+// ExportHAR returns the request journal of a running service as an HTTP
+// Archive.
+func (m *MockManager) ExportHAR(serviceName string) (*har.HAR, error) {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := m.GetRecordedRequests(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// gRPC services report the address to dial rather than a URL.
+	baseURL := server.GetURL()
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	return har.FromJournal(baseURL, requests), nil
+}
+
+func (m *MockManager) CountCalls(serviceName, endpointKey string) (int, error) {
+	recorder, err := m.getRequestRecorder(serviceName)
+	if err != nil {
+		return 0, err
+	}
+
+	return recorder.CountCalls(endpointKey), nil
+}
+
+func (m *MockManager) ResetRecordedRequests(serviceName string) error {
+	recorder, err := m.getRequestRecorder(serviceName)
+	if err != nil {
+		return err
+	}
+
+	recorder.ResetRecordedRequests()
+
+	return nil
+}
+
+func (m *MockManager) getServer(serviceName string) (mockServer.MockServer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	server, exists := m.servers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("service %s is not running", serviceName)
+	}
+
+	return server, nil
+}
+
+func (m *MockManager) getRequestRecorder(serviceName string) (mockServer.RequestRecorder, error) {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder, ok := server.(mockServer.RequestRecorder)
+	if !ok {
+		return nil, fmt.Errorf("service %s does not record requests", serviceName)
+	}
+
+	return recorder, nil
+}
+
+func (m *MockManager) getHandlerRegistrar(serviceName string) (mockServer.HandlerRegistrar, error) {
+	server, err := m.getServer(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	registrar, ok := server.(mockServer.HandlerRegistrar)
+	if !ok {
+		return nil, fmt.Errorf("service %s does not support Go handlers", serviceName)
+	}
+
+	return registrar, nil
+}
+
 func (m *MockManager) handleConfigChange(serviceName string) {
 	log.Printf("🔥 Hot reload: Config change detected for service %s\n", serviceName)
 
@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartServiceConfigOnPortZero(t *testing.T) {
+	m := NewMockManager("")
+	t.Cleanup(func() {
+		_ = m.StopAll()
+	})
+
+	urls := map[string]string{}
+	for _, name := range []string{"first", "second"} {
+		err := m.StartServiceConfig(context.Background(), &configReader.ServiceConfig{
+			ServiceName: name,
+			Port:        0,
+			Endpoints: map[string]configReader.EndpointList{
+				"GET /name": {{StatusCode: http.StatusOK, Body: name}},
+			},
+		})
+		require.NoError(t, err)
+
+		url, err := m.GetServiceURL(name)
+		require.NoError(t, err)
+		urls[name] = url
+	}
+
+	assert.NotEqual(t, urls["first"], urls["second"])
+
+	for name, url := range urls {
+		assert.NotContains(t, url, ":0", name)
+
+		resp, err := http.Get(url + "/name")
+		require.NoError(t, err, name)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, name)
+	}
+
+	ports := map[int]bool{}
+	for name, status := range m.GetStatus() {
+		assert.NotZero(t, status.Port, name)
+		ports[status.Port] = true
+	}
+	assert.Len(t, ports, 2)
+}
+
+func TestEphemeralPortsIgnoreConfiguredPort(t *testing.T) {
+	m := NewMockManager("")
+	m.SetEphemeralPorts(true)
+	t.Cleanup(func() {
+		_ = m.StopAll()
+	})
+
+	for _, name := range []string{"first", "second"} {
+		err := m.StartServiceConfig(context.Background(), &configReader.ServiceConfig{
+			ServiceName: name,
+			Port:        55999,
+			Endpoints: map[string]configReader.EndpointList{
+				"GET /": {{StatusCode: http.StatusNoContent}},
+			},
+		})
+		require.NoError(t, err, name)
+	}
+
+	first, err := m.GetServiceURL("first")
+	require.NoError(t, err)
+	second, err := m.GetServiceURL("second")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.NotContains(t, first, ":55999")
+}
@@ -0,0 +1,388 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the subset of an OpenAPI 3.x document used to build and
+// validate mock services. Only local references ("#/components/...") are
+// supported.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components,omitzero"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas       map[string]*Schema     `json:"schemas,omitempty"`
+	Parameters    map[string]Parameter   `json:"parameters,omitempty"`
+	RequestBodies map[string]RequestBody `json:"requestBodies,omitempty"`
+	Responses     map[string]Response    `json:"responses,omitempty"`
+	Headers       map[string]Header      `json:"headers,omitempty"`
+	Examples      map[string]Example     `json:"examples,omitempty"`
+}
+
+type PathItem struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Get        *Operation  `json:"get,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Options    *Operation  `json:"options,omitempty"`
+	Head       *Operation  `json:"head,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+}
+
+// Operations returns the operations of the path item keyed by HTTP method.
+func (p PathItem) Operations() map[string]*Operation {
+	operations := map[string]*Operation{}
+
+	for method, operation := range map[string]*Operation{
+		http.MethodGet:     p.Get,
+		http.MethodPut:     p.Put,
+		http.MethodPost:    p.Post,
+		http.MethodDelete:  p.Delete,
+		http.MethodOptions: p.Options,
+		http.MethodHead:    p.Head,
+		http.MethodPatch:   p.Patch,
+	} {
+		if operation != nil {
+			operations[method] = operation
+		}
+	}
+
+	return operations
+}
+
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Ref      string  `json:"$ref,omitempty"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+	Example  any     `json:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Ref      string               `json:"$ref,omitempty"`
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content,omitempty"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Ref     string  `json:"$ref,omitempty"`
+	Schema  *Schema `json:"schema,omitempty"`
+	Example any     `json:"example,omitempty"`
+}
+
+type MediaType struct {
+	Schema   *Schema            `json:"schema,omitempty"`
+	Example  any                `json:"example,omitempty"`
+	Examples map[string]Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Ref   string `json:"$ref,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Schema is a JSON schema as used by OpenAPI 3.0 and 3.1.
+type Schema struct {
+	Ref                  string                `json:"$ref,omitempty"`
+	Type                 SchemaType            `json:"type,omitempty"`
+	Format               string                `json:"format,omitempty"`
+	Nullable             bool                  `json:"nullable,omitempty"`
+	Enum                 []any                 `json:"enum,omitempty"`
+	Const                any                   `json:"const,omitempty"`
+	Example              any                   `json:"example,omitempty"`
+	Examples             []any                 `json:"examples,omitempty"`
+	Default              any                   `json:"default,omitempty"`
+	Properties           map[string]*Schema    `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty"`
+	Items                *Schema               `json:"items,omitempty"`
+	AllOf                []*Schema             `json:"allOf,omitempty"`
+	OneOf                []*Schema             `json:"oneOf,omitempty"`
+	AnyOf                []*Schema             `json:"anyOf,omitempty"`
+	Minimum              *float64              `json:"minimum,omitempty"`
+	Maximum              *float64              `json:"maximum,omitempty"`
+	MinLength            *int                  `json:"minLength,omitempty"`
+	MaxLength            *int                  `json:"maxLength,omitempty"`
+	Pattern              string                `json:"pattern,omitempty"`
+	MinItems             *int                  `json:"minItems,omitempty"`
+	MaxItems             *int                  `json:"maxItems,omitempty"`
+}
+
+// SchemaType holds the schema type, written as a single name in OpenAPI 3.0
+// and optionally as a list in 3.1.
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var types []string
+		if err := json.Unmarshal(trimmed, &types); err != nil {
+			return err
+		}
+
+		*t = types
+		return nil
+	}
+
+	var single string
+	if err := json.Unmarshal(trimmed, &single); err != nil {
+		return err
+	}
+
+	*t = SchemaType{single}
+
+	return nil
+}
+
+// Is reports whether the type list contains the given type name.
+func (t SchemaType) Is(name string) bool {
+	for _, typeName := range t {
+		if typeName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AdditionalProperties is either a boolean or a schema for the properties of
+// an object that are not listed in its properties.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.Allowed = allowed
+		return nil
+	}
+
+	a.Allowed = true
+
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Load reads an OpenAPI document written in YAML or JSON.
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+
+	document, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document %s: %w", path, err)
+	}
+
+	return document, nil
+}
+
+// Parse decodes an OpenAPI document written in YAML or JSON.
+func Parse(data []byte) (*Document, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	normalized, err := json.Marshal(configReader.NormalizeDocument(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var document Document
+	if err := json.Unmarshal(normalized, &document); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, expected 3.x", document.OpenAPI)
+	}
+
+	if len(document.Paths) == 0 {
+		return nil, fmt.Errorf("document has no paths")
+	}
+
+	return &document, nil
+}
+
+const maxRefDepth = 32
+
+// ResolveSchema follows the schema's reference, if any.
+func (d *Document) ResolveSchema(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != ""; depth++ {
+		name, ok := componentName(schema.Ref, "schemas")
+		if !ok || depth == maxRefDepth {
+			return nil
+		}
+
+		schema = d.Components.Schemas[name]
+	}
+
+	return schema
+}
+
+// ResolveParameter follows the parameter's reference, if any.
+func (d *Document) ResolveParameter(parameter Parameter) (Parameter, bool) {
+	for depth := 0; parameter.Ref != ""; depth++ {
+		name, ok := componentName(parameter.Ref, "parameters")
+		if !ok || depth == maxRefDepth {
+			return Parameter{}, false
+		}
+
+		parameter, ok = d.Components.Parameters[name]
+		if !ok {
+			return Parameter{}, false
+		}
+	}
+
+	return parameter, true
+}
+
+// ResolveRequestBody follows the request body's reference, if any.
+func (d *Document) ResolveRequestBody(body *RequestBody) *RequestBody {
+	for depth := 0; body != nil && body.Ref != ""; depth++ {
+		name, ok := componentName(body.Ref, "requestBodies")
+		if !ok || depth == maxRefDepth {
+			return nil
+		}
+
+		resolved, exists := d.Components.RequestBodies[name]
+		if !exists {
+			return nil
+		}
+
+		body = &resolved
+	}
+
+	return body
+}
+
+// ResolveResponse follows the response's reference, if any.
+func (d *Document) ResolveResponse(response Response) (Response, bool) {
+	for depth := 0; response.Ref != ""; depth++ {
+		name, ok := componentName(response.Ref, "responses")
+		if !ok || depth == maxRefDepth {
+			return Response{}, false
+		}
+
+		response, ok = d.Components.Responses[name]
+		if !ok {
+			return Response{}, false
+		}
+	}
+
+	return response, true
+}
+
+// ResolveHeader follows the header's reference, if any.
+func (d *Document) ResolveHeader(header Header) (Header, bool) {
+	for depth := 0; header.Ref != ""; depth++ {
+		name, ok := componentName(header.Ref, "headers")
+		if !ok || depth == maxRefDepth {
+			return Header{}, false
+		}
+
+		header, ok = d.Components.Headers[name]
+		if !ok {
+			return Header{}, false
+		}
+	}
+
+	return header, true
+}
+
+// ResolveExample follows the example's reference, if any.
+func (d *Document) ResolveExample(example Example) (Example, bool) {
+	for depth := 0; example.Ref != ""; depth++ {
+		name, ok := componentName(example.Ref, "examples")
+		if !ok || depth == maxRefDepth {
+			return Example{}, false
+		}
+
+		example, ok = d.Components.Examples[name]
+		if !ok {
+			return Example{}, false
+		}
+	}
+
+	return example, true
+}
+
+// OperationParameters returns the resolved parameters of an operation,
+// including those declared on its path item. Operation parameters override
+// path item parameters with the same name and location.
+func (d *Document) OperationParameters(pathItem PathItem, operation *Operation) []Parameter {
+	parameters := []Parameter{}
+	positions := map[string]int{}
+
+	for _, declared := range append(append([]Parameter{}, pathItem.Parameters...), operation.Parameters...) {
+		parameter, ok := d.ResolveParameter(declared)
+		if !ok {
+			continue
+		}
+
+		id := parameter.In + ":" + parameter.Name
+		if position, exists := positions[id]; exists {
+			parameters[position] = parameter
+			continue
+		}
+
+		positions[id] = len(parameters)
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
+
+func componentName(ref, kind string) (string, bool) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(ref, prefix)
+	name = strings.ReplaceAll(name, "~1", "/")
+	name = strings.ReplaceAll(name, "~0", "~")
+
+	return name, true
+}
@@ -0,0 +1,417 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+// PreferHeader selects a non-default response of an imported operation:
+// "Prefer: code=404" answers with the 404 response and "Prefer: example=name"
+// with a named example of the default response.
+const PreferHeader = "Prefer"
+
+// ImportOptions controls how a document becomes a service configuration.
+type ImportOptions struct {
+	// ServiceName defaults to a name derived from info.title.
+	ServiceName string
+	// Port defaults to 0, letting the port manager pick a free port.
+	Port int
+	// BasePath prefixes every path. It defaults to the path of the first
+	// server URL; set it to "/" to use the paths as written.
+	BasePath string
+	// ValidateRequests checks incoming requests against the document and
+	// answers invalid ones with a 400 listing the violations.
+	ValidateRequests bool
+}
+
+// Import loads the document at specPath and builds its service configuration.
+func Import(specPath string, options ImportOptions) (*configReader.ServiceConfig, error) {
+	document, err := Load(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := ToServiceConfig(document, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.ValidateRequests {
+		config.RequestValidation = &configReader.RequestValidationConfig{
+			Spec:     specPath,
+			BasePath: options.BasePath,
+		}
+	}
+
+	return config, nil
+}
+
+// ToServiceConfig builds a mock service answering every operation of the
+// document with its examples, or with values generated from its schemas when
+// an operation has no examples.
+func ToServiceConfig(document *Document, options ImportOptions) (*configReader.ServiceConfig, error) {
+	serviceName := options.ServiceName
+	if serviceName == "" {
+		serviceName = configReader.ServiceNameFrom(document.Info.Title)
+	}
+
+	if serviceName == "" {
+		return nil, fmt.Errorf("service name is required when the document has no title")
+	}
+
+	basePath := options.BasePath
+	if basePath == "" {
+		basePath = document.serverBasePath()
+	}
+
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	config := &configReader.ServiceConfig{
+		ServiceName: serviceName,
+		Port:        options.Port,
+		Endpoints:   make(map[string]configReader.EndpointList),
+	}
+
+	for path, pathItem := range document.Paths {
+		for method, operation := range pathItem.Operations() {
+			variants, err := document.endpointVariants(operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+
+			config.Endpoints[method+" "+basePath+path] = variants
+		}
+	}
+
+	return config, nil
+}
+
+func (d *Document) serverBasePath() string {
+	if len(d.Servers) == 0 {
+		return ""
+	}
+
+	parsed, err := url.Parse(d.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Path
+}
+
+// endpointVariants answers with the lowest 2xx response by default. Every
+// other response and every other named example of the default response becomes
+// a variant selected through the Prefer header.
+func (d *Document) endpointVariants(operation *Operation) (configReader.EndpointList, error) {
+	codes := responseCodes(operation.Responses)
+	if len(codes) == 0 {
+		return configReader.EndpointList{{StatusCode: 200}}, nil
+	}
+
+	primary := codes[0]
+	for _, code := range codes {
+		if code.status >= 200 && code.status < 300 {
+			primary = code
+			break
+		}
+	}
+
+	response, ok := d.ResolveResponse(operation.Responses[primary.key])
+	if !ok {
+		return nil, fmt.Errorf("unresolved response %s", primary.key)
+	}
+
+	variants := configReader.EndpointList{d.endpoint(primary.status, response, "")}
+
+	_, media, _ := selectMedia(response.Content)
+
+	defaultExample := ""
+	if media.Example == nil {
+		defaultExample = firstExampleName(media)
+	}
+
+	for _, name := range sortedKeys(media.Examples) {
+		if name == defaultExample {
+			continue
+		}
+
+		variant := d.endpoint(primary.status, response, name)
+		variant.Match.Headers = preferMatcher("example=" + name)
+		variants = append(variants, variant)
+	}
+
+	for _, code := range codes {
+		if code.key == primary.key {
+			continue
+		}
+
+		response, ok := d.ResolveResponse(operation.Responses[code.key])
+		if !ok {
+			return nil, fmt.Errorf("unresolved response %s", code.key)
+		}
+
+		variant := d.endpoint(code.status, response, "")
+		variant.Match.Headers = preferMatcher("code=" + strconv.Itoa(code.status))
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+func (d *Document) endpoint(status int, response Response, exampleName string) configReader.EndpointConfig {
+	endpoint := configReader.EndpointConfig{StatusCode: status}
+
+	for name, declared := range response.Headers {
+		header, ok := d.ResolveHeader(declared)
+		if !ok || strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+
+		value := header.Example
+		if value == nil {
+			value = d.exampleFromSchema(header.Schema, nil)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if endpoint.Headers == nil {
+			endpoint.Headers = make(map[string]string)
+		}
+
+		endpoint.Headers[name] = fmt.Sprint(value)
+	}
+
+	contentType, media, ok := selectMedia(response.Content)
+	if !ok {
+		return endpoint
+	}
+
+	if !strings.Contains(contentType, "*") {
+		if endpoint.Headers == nil {
+			endpoint.Headers = make(map[string]string)
+		}
+
+		endpoint.Headers["Content-Type"] = contentType
+	}
+
+	endpoint.Body = d.mediaExample(media, exampleName)
+
+	return endpoint
+}
+
+func (d *Document) mediaExample(media MediaType, exampleName string) any {
+	if exampleName == "" && media.Example != nil {
+		return media.Example
+	}
+
+	if exampleName == "" {
+		exampleName = firstExampleName(media)
+	}
+
+	if exampleName != "" {
+		if example, ok := d.ResolveExample(media.Examples[exampleName]); ok && example.Value != nil {
+			return example.Value
+		}
+	}
+
+	return d.exampleFromSchema(media.Schema, nil)
+}
+
+// exampleFromSchema returns the schema's own example, default or first enum
+// value, or builds a value of the schema's type. refs holds the references
+// being expanded, so recursive schemas stop at their first repetition.
+func (d *Document) exampleFromSchema(schema *Schema, refs []string) any {
+	if schema != nil && schema.Ref != "" {
+		if slices.Contains(refs, schema.Ref) {
+			return nil
+		}
+
+		refs = append(refs, schema.Ref)
+	}
+
+	schema = d.ResolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		return schema.Default
+	case schema.Const != nil:
+		return schema.Const
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := map[string]any{}
+		for _, part := range schema.AllOf {
+			if object, ok := d.exampleFromSchema(part, refs).(map[string]any); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+
+		return merged
+	case len(schema.OneOf) > 0:
+		return d.exampleFromSchema(schema.OneOf[0], refs)
+	case len(schema.AnyOf) > 0:
+		return d.exampleFromSchema(schema.AnyOf[0], refs)
+	}
+
+	switch {
+	case schema.Type.Is("object") || (len(schema.Type) == 0 && len(schema.Properties) > 0):
+		object := map[string]any{}
+		for name, property := range schema.Properties {
+			if value := d.exampleFromSchema(property, refs); value != nil {
+				object[name] = value
+			}
+		}
+
+		return object
+	case schema.Type.Is("array"):
+		item := d.exampleFromSchema(schema.Items, refs)
+		if item == nil {
+			return []any{}
+		}
+
+		return []any{item}
+	case schema.Type.Is("string"):
+		return exampleString(schema.Format)
+	case schema.Type.Is("integer"), schema.Type.Is("number"):
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+
+		return 0
+	case schema.Type.Is("boolean"):
+		return true
+	}
+
+	return nil
+}
+
+func exampleString(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	}
+
+	return "string"
+}
+
+type responseCode struct {
+	key    string
+	status int
+}
+
+// responseCodes returns the responses of an operation as status codes in
+// ascending order. Ranges such as "4XX" use their lowest code and "default"
+// answers with 500 unless it is the only response.
+func responseCodes(responses map[string]Response) []responseCode {
+	codes := []responseCode{}
+
+	for key := range responses {
+		upper := strings.ToUpper(key)
+
+		switch {
+		case upper == "DEFAULT":
+			status := 500
+			if len(responses) == 1 {
+				status = 200
+			}
+
+			codes = append(codes, responseCode{key: key, status: status})
+		case len(upper) == 3 && strings.HasSuffix(upper, "XX"):
+			if digit, err := strconv.Atoi(upper[:1]); err == nil {
+				codes = append(codes, responseCode{key: key, status: digit * 100})
+			}
+		default:
+			if status, err := strconv.Atoi(key); err == nil {
+				codes = append(codes, responseCode{key: key, status: status})
+			}
+		}
+	}
+
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].status != codes[j].status {
+			return codes[i].status < codes[j].status
+		}
+
+		return codes[i].key < codes[j].key
+	})
+
+	return slices.CompactFunc(codes, func(a, b responseCode) bool {
+		return a.status == b.status
+	})
+}
+
+// selectMedia prefers JSON content, then the first media type by name.
+func selectMedia(content map[string]MediaType) (string, MediaType, bool) {
+	if len(content) == 0 {
+		return "", MediaType{}, false
+	}
+
+	names := sortedKeys(content)
+	for _, name := range names {
+		if strings.HasPrefix(name, "application/json") {
+			return name, content[name], true
+		}
+	}
+
+	for _, name := range names {
+		if strings.Contains(name, "json") {
+			return name, content[name], true
+		}
+	}
+
+	return names[0], content[names[0]], true
+}
+
+func firstExampleName(media MediaType) string {
+	names := sortedKeys(media.Examples)
+	if len(names) == 0 {
+		return ""
+	}
+
+	return names[0]
+}
+
+func preferMatcher(preference string) map[string]configReader.ValueMatcher {
+	return map[string]configReader.ValueMatcher{
+		PreferHeader: {Matches: `(^|[\s,;])` + regexp.QuoteMeta(preference) + `($|[\s,;])`},
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,284 @@
+package openapi
+
+import (
+	"regexp"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadPetstore(t *testing.T) *Document {
+	t.Helper()
+
+	document, err := Load("testdata/petstore.yaml")
+	require.NoError(t, err)
+
+	return document
+}
+
+func TestImport(t *testing.T) {
+	config, err := Import("testdata/petstore.yaml", ImportOptions{ValidateRequests: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Pet-Store-API", config.ServiceName)
+	assert.Equal(t, 0, config.Port)
+	assert.ElementsMatch(t, []string{
+		"GET /v1/pets",
+		"POST /v1/pets",
+		"GET /v1/pets/{petId}",
+		"DELETE /v1/pets/{petId}",
+		"GET /v1/owners/{ownerId}/pets",
+		"POST /v1/search",
+	}, sortedKeys(config.Endpoints))
+
+	require.NotNil(t, config.RequestValidation)
+	assert.Equal(t, "testdata/petstore.yaml", config.RequestValidation.Spec)
+
+	_, err = Import("testdata/missing.yaml", ImportOptions{})
+	assert.Error(t, err)
+}
+
+func TestToServiceConfigBasePath(t *testing.T) {
+	document := loadPetstore(t)
+
+	tests := []struct {
+		name     string
+		options  ImportOptions
+		expected string
+	}{
+		{name: "first server", options: ImportOptions{}, expected: "GET /v1/pets"},
+		{name: "paths as written", options: ImportOptions{BasePath: "/"}, expected: "GET /pets"},
+		{name: "custom", options: ImportOptions{BasePath: "/api/"}, expected: "GET /api/pets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ToServiceConfig(document, tt.options)
+			require.NoError(t, err)
+			assert.Contains(t, config.Endpoints, tt.expected)
+		})
+	}
+}
+
+func TestServerBasePath(t *testing.T) {
+	tests := []struct {
+		servers  []Server
+		expected string
+	}{
+		{servers: nil, expected: ""},
+		{servers: []Server{{URL: "https://api.example.com/v1"}, {URL: "https://other/v2"}}, expected: "/v1"},
+		{servers: []Server{{URL: "/relative/base"}}, expected: "/relative/base"},
+		{servers: []Server{{URL: "https://api.example.com"}}, expected: ""},
+		{servers: []Server{{URL: "://bad"}}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, (&Document{Servers: tt.servers}).serverBasePath(), tt.servers)
+	}
+}
+
+func TestToServiceConfigServiceName(t *testing.T) {
+	document := &Document{OpenAPI: "3.0.0", Info: Info{Title: "  !!  "}, Paths: map[string]PathItem{"/": {}}}
+
+	_, err := ToServiceConfig(document, ImportOptions{})
+	assert.Error(t, err, "a title without valid characters needs an explicit name")
+
+	config, err := ToServiceConfig(document, ImportOptions{ServiceName: "named", Port: 55010})
+	require.NoError(t, err)
+	assert.Equal(t, "named", config.ServiceName)
+	assert.Equal(t, 55010, config.Port)
+}
+
+func TestResponseCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []string
+		expected  []responseCode
+	}{
+		{name: "only default", responses: []string{"default"}, expected: []responseCode{{key: "default", status: 200}}},
+		{name: "default with others", responses: []string{"default", "200"}, expected: []responseCode{{key: "200", status: 200}, {key: "default", status: 500}}},
+		{name: "ranges", responses: []string{"4XX", "2xx", "5XX"}, expected: []responseCode{{key: "2xx", status: 200}, {key: "4XX", status: 400}, {key: "5XX", status: 500}}},
+		{name: "sorted", responses: []string{"404", "201", "400"}, expected: []responseCode{{key: "201", status: 201}, {key: "400", status: 400}, {key: "404", status: 404}}},
+		{name: "explicit code wins over range", responses: []string{"4XX", "400"}, expected: []responseCode{{key: "400", status: 400}}},
+		{name: "invalid keys skipped", responses: []string{"ok", "XXX", "200"}, expected: []responseCode{{key: "200", status: 200}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := map[string]Response{}
+			for _, key := range tt.responses {
+				responses[key] = Response{}
+			}
+
+			assert.Equal(t, tt.expected, responseCodes(responses))
+		})
+	}
+}
+
+func TestEndpointVariants(t *testing.T) {
+	config, err := ToServiceConfig(loadPetstore(t), ImportOptions{BasePath: "/"})
+	require.NoError(t, err)
+
+	list := config.Endpoints["GET /pets"]
+	require.Len(t, list, 4)
+
+	assert.Equal(t, 200, list[0].StatusCode)
+	assert.Equal(t, []any{}, list[0].Body, "the first named example is the default")
+	assert.Equal(t, "application/json", list[0].Headers["Content-Type"])
+	assert.Equal(t, "0", list[0].Headers["X-Total-Count"])
+	assert.Empty(t, list[0].Match.Headers)
+
+	assert.Equal(t, 200, list[1].StatusCode)
+	assert.Equal(t, []any{map[string]any{"id": float64(1), "name": "Rex"}}, list[1].Body)
+
+	assert.Equal(t, 400, list[2].StatusCode)
+	assert.Equal(t, map[string]any{"error": "bad request"}, list[2].Body)
+	assert.Equal(t, 500, list[3].StatusCode)
+
+	post := config.Endpoints["POST /pets"]
+	require.Len(t, post, 2)
+	assert.Equal(t, 201, post[0].StatusCode)
+	assert.Equal(t, map[string]any{"id": float64(10), "name": "Rex"}, post[0].Body)
+
+	deleted := config.Endpoints["DELETE /pets/{petId}"]
+	require.Len(t, deleted, 1)
+	assert.Equal(t, 204, deleted[0].StatusCode)
+	assert.Nil(t, deleted[0].Body)
+
+	anyPet := config.Endpoints["GET /pets/{petId}"]
+	require.Len(t, anyPet, 1)
+	assert.Equal(t, 200, anyPet[0].StatusCode, "a lone default response answers 200")
+}
+
+func TestPreferVariants(t *testing.T) {
+	config, err := ToServiceConfig(loadPetstore(t), ImportOptions{BasePath: "/"})
+	require.NoError(t, err)
+
+	list := config.Endpoints["GET /pets"]
+
+	tests := []struct {
+		prefer   string
+		status   int
+		expected any
+	}{
+		{prefer: "", status: 200, expected: []any{}},
+		{prefer: "example=one", status: 200, expected: []any{map[string]any{"id": float64(1), "name": "Rex"}}},
+		{prefer: "return=minimal, example=one", status: 200, expected: []any{map[string]any{"id": float64(1), "name": "Rex"}}},
+		{prefer: "example=on", status: 200, expected: []any{}},
+		{prefer: "code=400", status: 400, expected: map[string]any{"error": "bad request"}},
+		{prefer: "code=500;q=1", status: 500, expected: map[string]any{"error": "bad request"}},
+		{prefer: "code=404", status: 200, expected: []any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefer, func(t *testing.T) {
+			endpoint := preferred(t, list, tt.prefer)
+
+			assert.Equal(t, tt.status, endpoint.StatusCode)
+			assert.Equal(t, tt.expected, endpoint.Body)
+		})
+	}
+}
+
+// preferred returns the variant a request with the given Prefer header
+// selects: the first one whose matcher accepts it, or the default one.
+func preferred(t *testing.T, list configReader.EndpointList, prefer string) configReader.EndpointConfig {
+	t.Helper()
+
+	for _, endpoint := range list[1:] {
+		matcher, ok := endpoint.Match.Headers[PreferHeader]
+		require.True(t, ok, "every variant is selected through the Prefer header")
+
+		if regexp.MustCompile(matcher.Matches).MatchString(prefer) {
+			return endpoint
+		}
+	}
+
+	return list[0]
+}
+
+func TestExampleFromSchema(t *testing.T) {
+	document := loadPetstore(t)
+
+	owner := document.exampleFromSchema(&Schema{Ref: "#/components/schemas/Owner"}, nil)
+	assert.Equal(t, map[string]any{
+		"name":  "Ada",
+		"email": "user@example.com",
+		"pets": []any{map[string]any{
+			"id":     float64(1),
+			"name":   "string",
+			"tag":    "string",
+			"status": "available",
+			"tags":   []any{"string"},
+			"born":   "2024-01-01",
+		}},
+	}, owner, "the recursive best_friend reference stops at its first repetition")
+
+	minimum := 5.0
+	tests := []struct {
+		name     string
+		schema   *Schema
+		expected any
+	}{
+		{name: "nil", schema: nil, expected: nil},
+		{name: "unresolved reference", schema: &Schema{Ref: "#/components/schemas/Missing"}, expected: nil},
+		{name: "external reference", schema: &Schema{Ref: "other.yaml#/Pet"}, expected: nil},
+		{name: "example", schema: &Schema{Type: SchemaType{"string"}, Example: "x"}, expected: "x"},
+		{name: "examples", schema: &Schema{Examples: []any{"first", "second"}}, expected: "first"},
+		{name: "default", schema: &Schema{Default: 3.0}, expected: 3.0},
+		{name: "const", schema: &Schema{Const: "fixed"}, expected: "fixed"},
+		{name: "enum", schema: &Schema{Enum: []any{"a", "b"}}, expected: "a"},
+		{name: "integer minimum", schema: &Schema{Type: SchemaType{"integer"}, Minimum: &minimum}, expected: 5.0},
+		{name: "number", schema: &Schema{Type: SchemaType{"number"}}, expected: 0},
+		{name: "boolean", schema: &Schema{Type: SchemaType{"boolean"}}, expected: true},
+		{name: "nullable 3.1 type", schema: &Schema{Type: SchemaType{"string", "null"}, Format: "uuid"}, expected: "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		{name: "empty array", schema: &Schema{Type: SchemaType{"array"}}, expected: []any{}},
+		{name: "untyped properties", schema: &Schema{Properties: map[string]*Schema{"ok": {Type: SchemaType{"boolean"}}}}, expected: map[string]any{"ok": true}},
+		{
+			name: "allOf merge",
+			schema: &Schema{AllOf: []*Schema{
+				{Properties: map[string]*Schema{"a": {Example: 1.0}, "b": {Example: 2.0}}},
+				{Properties: map[string]*Schema{"b": {Example: 3.0}}},
+				{Type: SchemaType{"string"}},
+			}},
+			expected: map[string]any{"a": 1.0, "b": 3.0},
+		},
+		{name: "oneOf first", schema: &Schema{OneOf: []*Schema{{Type: SchemaType{"integer"}}, {Type: SchemaType{"string"}}}}, expected: 0},
+		{name: "anyOf first", schema: &Schema{AnyOf: []*Schema{{Type: SchemaType{"string"}, Format: "date-time"}}}, expected: "2024-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, document.exampleFromSchema(tt.schema, nil))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "json", data: `{"openapi": "3.1.0", "info": {"title": "t"}, "paths": {"/": {"get": {"responses": {"200": {"description": "ok"}}}}}}`},
+		{name: "yaml with integer keys", data: "openapi: 3.0.0\npaths:\n  /:\n    get:\n      responses:\n        200:\n          description: ok\n"},
+		{name: "swagger 2", data: "swagger: '2.0'\nopenapi: '2.0'\npaths:\n  /: {}\n", wantErr: true},
+		{name: "no paths", data: "openapi: 3.0.0\npaths: {}\n", wantErr: true},
+		{name: "invalid yaml", data: "openapi: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,637 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation is a way a request breaks the contract of its OpenAPI operation.
+// Location is "method", "path", "query", "header" or "body"; Name is the
+// parameter name, or a JSON pointer into the body.
+type Violation struct {
+	Location string `json:"location"`
+	Name     string `json:"name,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Name == "" {
+		return fmt.Sprintf("%s: %s", v.Location, v.Message)
+	}
+
+	return fmt.Sprintf("%s %s: %s", v.Location, v.Name, v.Message)
+}
+
+// RequestValidator checks requests against the operations of a document.
+type RequestValidator struct {
+	document *Document
+	basePath string
+	paths    []pathTemplate
+	patterns map[string]*regexp.Regexp
+}
+
+type pathTemplate struct {
+	template string
+	regex    *regexp.Regexp
+	params   []string
+	literals int
+}
+
+var templateParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// NewRequestValidator builds a validator for the document. Request paths must
+// start with basePath, which defaults to the path of the first server URL; "/"
+// means the paths are used as written. A schema pattern that is not a valid
+// regular expression is an error.
+func NewRequestValidator(document *Document, basePath string) (*RequestValidator, error) {
+	if basePath == "" {
+		basePath = document.serverBasePath()
+	}
+
+	validator := &RequestValidator{
+		document: document,
+		basePath: strings.TrimSuffix(basePath, "/"),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+
+	if err := validator.compilePatterns(); err != nil {
+		return nil, err
+	}
+
+	for template := range document.Paths {
+		compiled, err := compilePathTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+
+		validator.paths = append(validator.paths, compiled)
+	}
+
+	// Literal paths win over templated ones, as in the request matcher.
+	sort.Slice(validator.paths, func(i, j int) bool {
+		if validator.paths[i].literals != validator.paths[j].literals {
+			return validator.paths[i].literals > validator.paths[j].literals
+		}
+
+		return validator.paths[i].template < validator.paths[j].template
+	})
+
+	return validator, nil
+}
+
+// compilePatterns compiles the patterns of every schema a request can be
+// checked against: component schemas, parameters and request bodies.
+func (v *RequestValidator) compilePatterns() error {
+	for _, name := range sortedKeys(v.document.Components.Schemas) {
+		if err := v.compileSchemaPatterns(v.document.Components.Schemas[name]); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	for _, name := range sortedKeys(v.document.Components.Parameters) {
+		if err := v.compileSchemaPatterns(v.document.Components.Parameters[name].Schema); err != nil {
+			return fmt.Errorf("parameter %s: %w", name, err)
+		}
+	}
+
+	for _, name := range sortedKeys(v.document.Components.RequestBodies) {
+		if err := v.compileContentPatterns(v.document.Components.RequestBodies[name].Content); err != nil {
+			return fmt.Errorf("request body %s: %w", name, err)
+		}
+	}
+
+	for _, path := range sortedKeys(v.document.Paths) {
+		pathItem := v.document.Paths[path]
+
+		for method, operation := range pathItem.Operations() {
+			for _, parameter := range append(append([]Parameter{}, pathItem.Parameters...), operation.Parameters...) {
+				if err := v.compileSchemaPatterns(parameter.Schema); err != nil {
+					return fmt.Errorf("%s %s parameter %s: %w", method, path, parameter.Name, err)
+				}
+			}
+
+			if operation.RequestBody != nil {
+				if err := v.compileContentPatterns(operation.RequestBody.Content); err != nil {
+					return fmt.Errorf("%s %s request body: %w", method, path, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *RequestValidator) compileContentPatterns(content map[string]MediaType) error {
+	for _, media := range content {
+		if err := v.compileSchemaPatterns(media.Schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileSchemaPatterns does not follow references; the component schemas
+// they point to are compiled on their own.
+func (v *RequestValidator) compileSchemaPatterns(schema *Schema) error {
+	if schema == nil {
+		return nil
+	}
+
+	if _, compiled := v.patterns[schema.Pattern]; schema.Pattern != "" && !compiled {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", schema.Pattern, err)
+		}
+
+		v.patterns[schema.Pattern] = pattern
+	}
+
+	children := append(append(append([]*Schema{schema.Items}, schema.AllOf...), schema.OneOf...), schema.AnyOf...)
+	for _, name := range sortedKeys(schema.Properties) {
+		children = append(children, schema.Properties[name])
+	}
+
+	if schema.AdditionalProperties != nil {
+		children = append(children, schema.AdditionalProperties.Schema)
+	}
+
+	for _, child := range children {
+		if err := v.compileSchemaPatterns(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compilePathTemplate(template string) (pathTemplate, error) {
+	compiled := pathTemplate{template: template}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, location := range templateParamRegex.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:location[0]]))
+		pattern.WriteString(`([^/]+)`)
+		compiled.params = append(compiled.params, template[location[2]:location[3]])
+		last = location[1]
+	}
+
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	regex, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return pathTemplate{}, fmt.Errorf("invalid path %s: %w", template, err)
+	}
+
+	compiled.regex = regex
+
+	for _, segment := range strings.Split(template, "/") {
+		if !strings.Contains(segment, "{") {
+			compiled.literals++
+		}
+	}
+
+	return compiled, nil
+}
+
+// Validate returns the violations of the request, or nil when it conforms to
+// its operation. The body is passed separately because it has usually been
+// read already.
+func (v *RequestValidator) Validate(r *http.Request, body []byte) []Violation {
+	requestPath := r.URL.Path
+	if v.basePath != "" {
+		if requestPath != v.basePath && !strings.HasPrefix(requestPath, v.basePath+"/") {
+			return []Violation{{Location: "path", Message: fmt.Sprintf("%s is outside the API base path %s", requestPath, v.basePath)}}
+		}
+
+		requestPath = strings.TrimPrefix(requestPath, v.basePath)
+	}
+
+	for _, template := range v.paths {
+		matches := template.regex.FindStringSubmatch(requestPath)
+		if matches == nil {
+			continue
+		}
+
+		pathItem := v.document.Paths[template.template]
+		operation := pathItem.Operations()[strings.ToUpper(r.Method)]
+		if operation == nil {
+			return []Violation{{Location: "method", Message: fmt.Sprintf("%s is not declared for %s", r.Method, template.template)}}
+		}
+
+		pathValues := make(map[string]string, len(template.params))
+		for i, name := range template.params {
+			pathValues[name] = matches[i+1]
+		}
+
+		return v.validateOperation(r, body, pathItem, operation, pathValues)
+	}
+
+	return []Violation{{Location: "path", Message: fmt.Sprintf("%s is not declared in the API", r.URL.Path)}}
+}
+
+func (v *RequestValidator) validateOperation(r *http.Request, body []byte, pathItem PathItem, operation *Operation, pathValues map[string]string) []Violation {
+	violations := []Violation{}
+	query := r.URL.Query()
+
+	for _, parameter := range v.document.OperationParameters(pathItem, operation) {
+		var values []string
+
+		switch parameter.In {
+		case "path":
+			if value, ok := pathValues[parameter.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[parameter.Name]
+		case "header":
+			values = r.Header.Values(parameter.Name)
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if parameter.Required || parameter.In == "path" {
+				violations = append(violations, Violation{Location: parameter.In, Name: parameter.Name, Message: "is required"})
+			}
+
+			continue
+		}
+
+		for _, message := range v.validateParameter(parameter, values) {
+			violations = append(violations, Violation{Location: parameter.In, Name: parameter.Name, Message: message})
+		}
+	}
+
+	violations = append(violations, v.validateBody(r, body, operation)...)
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return violations
+}
+
+// validateParameter converts the raw values to the parameter's schema type
+// before checking them, since parameters always arrive as strings.
+func (v *RequestValidator) validateParameter(parameter Parameter, values []string) []string {
+	schema := v.document.ResolveSchema(parameter.Schema)
+	if schema == nil {
+		return nil
+	}
+
+	var value any
+	if schema.Type.Is("array") {
+		if len(values) == 1 && strings.Contains(values[0], ",") {
+			values = strings.Split(values[0], ",")
+		}
+
+		items := make([]any, len(values))
+		for i, raw := range values {
+			items[i] = coerceParameter(raw, v.document.ResolveSchema(schema.Items))
+		}
+
+		value = items
+	} else {
+		value = coerceParameter(values[0], schema)
+	}
+
+	messages := []string{}
+	for _, violation := range v.validateSchema(value, parameter.Schema, "") {
+		message := violation.Message
+		if violation.Name != "" {
+			message = violation.Name + ": " + message
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+func coerceParameter(raw string, schema *Schema) any {
+	if schema == nil {
+		return raw
+	}
+
+	switch {
+	case schema.Type.Is("integer"), schema.Type.Is("number"):
+		if number, err := strconv.ParseFloat(raw, 64); err == nil {
+			return number
+		}
+	case schema.Type.Is("boolean"):
+		if boolean, err := strconv.ParseBool(raw); err == nil {
+			return boolean
+		}
+	}
+
+	return raw
+}
+
+func (v *RequestValidator) validateBody(r *http.Request, body []byte, operation *Operation) []Violation {
+	requestBody := v.document.ResolveRequestBody(operation.RequestBody)
+	if requestBody == nil {
+		return nil
+	}
+
+	if len(body) == 0 {
+		if requestBody.Required {
+			return []Violation{{Location: "body", Message: "is required"}}
+		}
+
+		return nil
+	}
+
+	if len(requestBody.Content) == 0 {
+		return nil
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return []Violation{{Location: "header", Name: "Content-Type", Message: "is missing or invalid"}}
+	}
+
+	media, declared := requestBody.Content[contentType]
+	if !declared {
+		media, declared = matchWildcardMedia(requestBody.Content, contentType)
+	}
+
+	if !declared {
+		return []Violation{{Location: "header", Name: "Content-Type", Message: fmt.Sprintf("%s is not accepted by the operation", contentType)}}
+	}
+
+	if !strings.Contains(contentType, "json") || media.Schema == nil {
+		return nil
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []Violation{{Location: "body", Message: fmt.Sprintf("is not valid JSON: %v", err)}}
+	}
+
+	return v.validateSchema(document, media.Schema, "")
+}
+
+func matchWildcardMedia(content map[string]MediaType, contentType string) (MediaType, bool) {
+	major, _, _ := strings.Cut(contentType, "/")
+
+	if media, ok := content[major+"/*"]; ok {
+		return media, true
+	}
+
+	media, ok := content["*/*"]
+
+	return media, ok
+}
+
+// validateSchema checks a decoded JSON value against a schema and returns one
+// body violation per failed constraint, named by JSON pointer.
+func (v *RequestValidator) validateSchema(value any, schema *Schema, pointer string) []Violation {
+	schema = v.document.ResolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+
+	violation := func(format string, args ...any) []Violation {
+		return []Violation{{Location: "body", Name: pointer, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if value == nil && (schema.Nullable || schema.Type.Is("null")) {
+		return nil
+	}
+
+	violations := []Violation{}
+
+	for _, part := range schema.AllOf {
+		violations = append(violations, v.validateSchema(value, part, pointer)...)
+	}
+
+	if len(schema.AnyOf) > 0 && v.countMatching(value, schema.AnyOf, pointer) == 0 {
+		violations = append(violations, violation("does not match any of the allowed schemas")...)
+	}
+
+	if len(schema.OneOf) > 0 {
+		if matching := v.countMatching(value, schema.OneOf, pointer); matching != 1 {
+			violations = append(violations, violation("must match exactly one schema, matched %d", matching)...)
+		}
+	}
+
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, value) {
+		violations = append(violations, violation("must be one of %s", formatValues(schema.Enum))...)
+	}
+
+	if schema.Const != nil && !reflect.DeepEqual(schema.Const, value) {
+		violations = append(violations, violation("must be %s", formatValues([]any{schema.Const}))...)
+	}
+
+	if len(schema.Type) > 0 && !matchesType(value, schema.Type) {
+		return append(violations, violation("must be of type %s, got %s", strings.Join(schema.Type, " or "), typeName(value))...)
+	}
+
+	switch typed := value.(type) {
+	case string:
+		violations = append(violations, v.validateString(typed, schema, pointer)...)
+	case float64:
+		if schema.Minimum != nil && typed < *schema.Minimum {
+			violations = append(violations, violation("must be >= %v", *schema.Minimum)...)
+		}
+
+		if schema.Maximum != nil && typed > *schema.Maximum {
+			violations = append(violations, violation("must be <= %v", *schema.Maximum)...)
+		}
+	case []any:
+		if schema.MinItems != nil && len(typed) < *schema.MinItems {
+			violations = append(violations, violation("must have at least %d items", *schema.MinItems)...)
+		}
+
+		if schema.MaxItems != nil && len(typed) > *schema.MaxItems {
+			violations = append(violations, violation("must have at most %d items", *schema.MaxItems)...)
+		}
+
+		if schema.Items != nil {
+			for i, item := range typed {
+				violations = append(violations, v.validateSchema(item, schema.Items, pointer+"/"+strconv.Itoa(i))...)
+			}
+		}
+	case map[string]any:
+		violations = append(violations, v.validateObject(typed, schema, pointer)...)
+	}
+
+	return violations
+}
+
+func (v *RequestValidator) validateString(value string, schema *Schema, pointer string) []Violation {
+	messages := []string{}
+	length := utf8.RuneCountInString(value)
+
+	if schema.MinLength != nil && length < *schema.MinLength {
+		messages = append(messages, fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		messages = append(messages, fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+	}
+
+	if schema.Pattern != "" {
+		if pattern := v.patterns[schema.Pattern]; pattern != nil && !pattern.MatchString(value) {
+			messages = append(messages, fmt.Sprintf("must match pattern %s", schema.Pattern))
+		}
+	}
+
+	if !matchesFormat(value, schema.Format) {
+		messages = append(messages, fmt.Sprintf("must be a valid %s", schema.Format))
+	}
+
+	violations := make([]Violation, len(messages))
+	for i, message := range messages {
+		violations[i] = Violation{Location: "body", Name: pointer, Message: message}
+	}
+
+	return violations
+}
+
+func (v *RequestValidator) validateObject(object map[string]any, schema *Schema, pointer string) []Violation {
+	violations := []Violation{}
+
+	for _, name := range schema.Required {
+		if _, exists := object[name]; !exists {
+			violations = append(violations, Violation{Location: "body", Name: pointer + "/" + escapePointer(name), Message: "is required"})
+		}
+	}
+
+	for _, name := range sortedKeys(object) {
+		propertyPointer := pointer + "/" + escapePointer(name)
+
+		if property, declared := schema.Properties[name]; declared {
+			violations = append(violations, v.validateSchema(object[name], property, propertyPointer)...)
+			continue
+		}
+
+		if schema.AdditionalProperties == nil {
+			continue
+		}
+
+		if !schema.AdditionalProperties.Allowed {
+			violations = append(violations, Violation{Location: "body", Name: propertyPointer, Message: "is not allowed"})
+			continue
+		}
+
+		if schema.AdditionalProperties.Schema != nil {
+			violations = append(violations, v.validateSchema(object[name], schema.AdditionalProperties.Schema, propertyPointer)...)
+		}
+	}
+
+	return violations
+}
+
+func (v *RequestValidator) countMatching(value any, schemas []*Schema, pointer string) int {
+	matching := 0
+	for _, candidate := range schemas {
+		if len(v.validateSchema(value, candidate, pointer)) == 0 {
+			matching++
+		}
+	}
+
+	return matching
+}
+
+func matchesType(value any, types SchemaType) bool {
+	for _, name := range types {
+		switch name {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "integer":
+			if number, ok := value.(float64); ok && number == float64(int64(number)) {
+				return true
+			}
+		default:
+			if typeName(value) == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func matchesFormat(value, format string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "uuid":
+		return uuidRegex.MatchString(value)
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	}
+
+	return true
+}
+
+func containsValue(values []any, value any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatValues(values []any) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+
+	if len(values) == 1 {
+		return strings.TrimSuffix(strings.TrimPrefix(string(data), "["), "]")
+	}
+
+	return string(data)
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,332 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validationCase struct {
+	name     string
+	method   string
+	target   string
+	headers  map[string]string
+	body     string
+	expected []Violation
+}
+
+func runValidationCases(t *testing.T, validator *RequestValidator, tests []validationCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			assert.Equal(t, tt.expected, validator.Validate(r, []byte(tt.body)))
+		})
+	}
+}
+
+func petstoreValidator(t *testing.T, basePath string) *RequestValidator {
+	t.Helper()
+
+	validator, err := NewRequestValidator(loadPetstore(t), basePath)
+	require.NoError(t, err)
+
+	return validator
+}
+
+func TestValidateParameters(t *testing.T) {
+	runValidationCases(t, petstoreValidator(t, ""), []validationCase{
+		{name: "no parameters", method: http.MethodGet, target: "/v1/pets"},
+		{name: "coerced integer", method: http.MethodGet, target: "/v1/pets?limit=5"},
+		{
+			name: "not an integer", method: http.MethodGet, target: "/v1/pets?limit=five",
+			expected: []Violation{{Location: "query", Name: "limit", Message: "must be of type integer, got string"}},
+		},
+		{
+			name: "fraction is not an integer", method: http.MethodGet, target: "/v1/pets?limit=1.5",
+			expected: []Violation{{Location: "query", Name: "limit", Message: "must be of type integer, got number"}},
+		},
+		{
+			name: "below minimum", method: http.MethodGet, target: "/v1/pets?limit=0",
+			expected: []Violation{{Location: "query", Name: "limit", Message: "must be >= 1"}},
+		},
+		{
+			name: "above maximum", method: http.MethodGet, target: "/v1/pets?limit=101",
+			expected: []Violation{{Location: "query", Name: "limit", Message: "must be <= 100"}},
+		},
+		{name: "enum value", method: http.MethodGet, target: "/v1/pets?status=sold"},
+		{
+			name: "not in enum", method: http.MethodGet, target: "/v1/pets?status=lost",
+			expected: []Violation{{Location: "query", Name: "status", Message: `must be one of ["available","sold"]`}},
+		},
+		{name: "coerced path parameter", method: http.MethodGet, target: "/v1/pets/7"},
+		{
+			name: "invalid path parameter", method: http.MethodGet, target: "/v1/pets/rex",
+			expected: []Violation{{Location: "path", Name: "petId", Message: "must be of type integer, got string"}},
+		},
+		{name: "pattern", method: http.MethodGet, target: "/v1/owners/o-12/pets"},
+		{
+			name: "pattern mismatch", method: http.MethodGet, target: "/v1/owners/ada/pets",
+			expected: []Violation{{Location: "path", Name: "ownerId", Message: "must match pattern ^o-[0-9]+$"}},
+		},
+		{
+			name: "required header", method: http.MethodDelete, target: "/v1/pets/7",
+			headers: map[string]string{"X-Request-ID": "3fa85f64-5717-4562-b3fc-2c963f66afa6"},
+		},
+		{
+			name: "missing required header", method: http.MethodDelete, target: "/v1/pets/7",
+			expected: []Violation{{Location: "header", Name: "X-Request-ID", Message: "is required"}},
+		},
+		{
+			name: "header format", method: http.MethodDelete, target: "/v1/pets/7",
+			headers:  map[string]string{"X-Request-ID": "42"},
+			expected: []Violation{{Location: "header", Name: "X-Request-ID", Message: "must be a valid uuid"}},
+		},
+	})
+}
+
+func TestValidateBody(t *testing.T) {
+	runValidationCases(t, petstoreValidator(t, ""), []validationCase{
+		{name: "valid", method: http.MethodPost, target: "/v1/pets", body: `{"name": "Rex", "tag": "dog", "status": "available", "tags": ["a", "b"]}`},
+		{
+			name: "missing body", method: http.MethodPost, target: "/v1/pets",
+			expected: []Violation{{Location: "body", Message: "is required"}},
+		},
+		{
+			name: "invalid JSON", method: http.MethodPost, target: "/v1/pets", body: `{"name":`,
+			expected: []Violation{{Location: "body", Message: "is not valid JSON: unexpected end of JSON input"}},
+		},
+		{
+			name: "undeclared content type", method: http.MethodPost, target: "/v1/pets", body: "name=Rex",
+			headers:  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			expected: []Violation{{Location: "header", Name: "Content-Type", Message: "application/x-www-form-urlencoded is not accepted by the operation"}},
+		},
+		{
+			name: "wrong type", method: http.MethodPost, target: "/v1/pets", body: `[]`,
+			expected: []Violation{{Location: "body", Message: "must be of type object, got array"}},
+		},
+		{
+			name: "missing required property", method: http.MethodPost, target: "/v1/pets", body: `{"tag": "dog"}`,
+			expected: []Violation{{Location: "body", Name: "/name", Message: "is required"}},
+		},
+		{
+			name: "too short", method: http.MethodPost, target: "/v1/pets", body: `{"name": ""}`,
+			expected: []Violation{{Location: "body", Name: "/name", Message: "must be at least 1 characters long"}},
+		},
+		{
+			name: "too long", method: http.MethodPost, target: "/v1/pets", body: `{"name": "` + strings.Repeat("x", 21) + `"}`,
+			expected: []Violation{{Location: "body", Name: "/name", Message: "must be at most 20 characters long"}},
+		},
+		{
+			name: "pattern mismatch", method: http.MethodPost, target: "/v1/pets", body: `{"name": "Rex", "tag": "Dog"}`,
+			expected: []Violation{{Location: "body", Name: "/tag", Message: "must match pattern ^[a-z]+$"}},
+		},
+		{
+			name: "not in enum", method: http.MethodPost, target: "/v1/pets", body: `{"name": "Rex", "status": "lost"}`,
+			expected: []Violation{{Location: "body", Name: "/status", Message: `must be one of ["available","sold"]`}},
+		},
+		{
+			name: "too many items", method: http.MethodPost, target: "/v1/pets", body: `{"name": "Rex", "tags": ["a", "b", "c"]}`,
+			expected: []Violation{{Location: "body", Name: "/tags", Message: "must have at most 2 items"}},
+		},
+		{
+			name: "invalid item", method: http.MethodPost, target: "/v1/pets", body: `{"name": "Rex", "tags": ["a", 1]}`,
+			expected: []Violation{{Location: "body", Name: "/tags/1", Message: "must be of type string, got number"}},
+		},
+		{
+			name: "additional property", method: http.MethodPost, target: "/v1/pets", body: `{"name": "Rex", "owner/id": 1}`,
+			expected: []Violation{{Location: "body", Name: "/owner~1id", Message: "is not allowed"}},
+		},
+		{name: "oneOf by name", method: http.MethodPost, target: "/v1/search", body: `{"name": "Rex"}`},
+		{name: "oneOf by id", method: http.MethodPost, target: "/v1/search", body: `{"id": 1}`},
+		{
+			name: "oneOf matching none", method: http.MethodPost, target: "/v1/search", body: `{"name": "Rex", "id": 1}`,
+			expected: []Violation{{Location: "body", Message: "must match exactly one schema, matched 0"}},
+		},
+	})
+}
+
+const combinatorsDocument = `
+openapi: 3.1.0
+paths:
+  /notes:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                text:
+                  anyOf:
+                    - type: string
+                      maxLength: 3
+                    - type: integer
+                kind:
+                  oneOf:
+                    - type: string
+                    - type: string
+                      enum: [a, b]
+                labels:
+                  type: object
+                  additionalProperties:
+                    type: string
+                    pattern: '^[a-z]+$'
+                parent:
+                  type: [string, "null"]
+                meta:
+                  allOf:
+                    - required: [id]
+                    - required: [at]
+                      properties:
+                        at:
+                          format: date-time
+`
+
+func TestValidateCombinators(t *testing.T) {
+	document, err := Parse([]byte(combinatorsDocument))
+	require.NoError(t, err)
+
+	validator, err := NewRequestValidator(document, "")
+	require.NoError(t, err)
+
+	runValidationCases(t, validator, []validationCase{
+		{name: "anyOf first", method: http.MethodPost, target: "/notes", body: `{"text": "abc"}`},
+		{name: "anyOf second", method: http.MethodPost, target: "/notes", body: `{"text": 12}`},
+		{
+			name: "anyOf none", method: http.MethodPost, target: "/notes", body: `{"text": "abcd"}`,
+			expected: []Violation{{Location: "body", Name: "/text", Message: "does not match any of the allowed schemas"}},
+		},
+		{name: "oneOf exactly one", method: http.MethodPost, target: "/notes", body: `{"kind": "c"}`},
+		{
+			name: "oneOf several", method: http.MethodPost, target: "/notes", body: `{"kind": "a"}`,
+			expected: []Violation{{Location: "body", Name: "/kind", Message: "must match exactly one schema, matched 2"}},
+		},
+		{name: "additionalProperties schema", method: http.MethodPost, target: "/notes", body: `{"labels": {"env": "prod"}}`},
+		{
+			name: "additionalProperties schema mismatch", method: http.MethodPost, target: "/notes", body: `{"labels": {"env": "PROD"}}`,
+			expected: []Violation{{Location: "body", Name: "/labels/env", Message: "must match pattern ^[a-z]+$"}},
+		},
+		{name: "nullable", method: http.MethodPost, target: "/notes", body: `{"parent": null}`},
+		{name: "allOf", method: http.MethodPost, target: "/notes", body: `{"meta": {"id": 1, "at": "2024-01-01T00:00:00Z"}}`},
+		{
+			name: "allOf parts", method: http.MethodPost, target: "/notes", body: `{"meta": {"at": "yesterday"}}`,
+			expected: []Violation{
+				{Location: "body", Name: "/meta/id", Message: "is required"},
+				{Location: "body", Name: "/meta/at", Message: "must be a valid date-time"},
+			},
+		},
+	})
+}
+
+const pathsDocument = `
+openapi: 3.0.0
+servers:
+  - url: https://api.example.com/api/v2/
+paths:
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get: {responses: {200: {description: ok}}}
+  /items/latest:
+    get: {responses: {200: {description: ok}}}
+  /items/{id}/tags/{tag}:
+    get: {responses: {200: {description: ok}}}
+`
+
+func TestValidatePaths(t *testing.T) {
+	document, err := Parse([]byte(pathsDocument))
+	require.NoError(t, err)
+
+	serverBase, err := NewRequestValidator(document, "")
+	require.NoError(t, err)
+
+	runValidationCases(t, serverBase, []validationCase{
+		{name: "literal path wins over template", method: http.MethodGet, target: "/api/v2/items/latest"},
+		{name: "template", method: http.MethodGet, target: "/api/v2/items/3"},
+		{name: "several parameters", method: http.MethodGet, target: "/api/v2/items/3/tags/new"},
+		{
+			name: "template parameter", method: http.MethodGet, target: "/api/v2/items/first",
+			expected: []Violation{{Location: "path", Name: "id", Message: "must be of type integer, got string"}},
+		},
+		{
+			name: "parameter does not span segments", method: http.MethodGet, target: "/api/v2/items/3/4",
+			expected: []Violation{{Location: "path", Message: "/api/v2/items/3/4 is not declared in the API"}},
+		},
+		{
+			name: "undeclared method", method: http.MethodDelete, target: "/api/v2/items/3",
+			expected: []Violation{{Location: "method", Message: "DELETE is not declared for /items/{id}"}},
+		},
+		{
+			name: "outside base path", method: http.MethodGet, target: "/items/3",
+			expected: []Violation{{Location: "path", Message: "/items/3 is outside the API base path /api/v2"}},
+		},
+		{
+			name: "base path prefix of a segment", method: http.MethodGet, target: "/api/v20/items/3",
+			expected: []Violation{{Location: "path", Message: "/api/v20/items/3 is outside the API base path /api/v2"}},
+		},
+	})
+
+	asWritten, err := NewRequestValidator(document, "/")
+	require.NoError(t, err)
+
+	runValidationCases(t, asWritten, []validationCase{
+		{name: "paths as written", method: http.MethodGet, target: "/items/3"},
+		{
+			name: "server base path not stripped", method: http.MethodGet, target: "/api/v2/items/3",
+			expected: []Violation{{Location: "path", Message: "/api/v2/items/3 is not declared in the API"}},
+		},
+	})
+}
+
+func TestNewRequestValidatorInvalidPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		expected string
+	}{
+		{
+			name:     "component schema",
+			document: "openapi: 3.0.0\npaths:\n  /: {}\ncomponents:\n  schemas:\n    Code:\n      properties:\n        value:\n          pattern: '[a-'\n",
+			expected: "schema Code: invalid pattern",
+		},
+		{
+			name:     "parameter",
+			document: "openapi: 3.0.0\npaths:\n  /items:\n    get:\n      parameters:\n        - name: q\n          in: query\n          schema:\n            pattern: '(x'\n      responses: {}\n",
+			expected: "GET /items parameter q: invalid pattern",
+		},
+		{
+			name:     "request body",
+			document: "openapi: 3.0.0\npaths:\n  /items:\n    post:\n      requestBody:\n        content:\n          application/json:\n            schema:\n              items:\n                pattern: '*'\n      responses: {}\n",
+			expected: "POST /items request body: invalid pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := Parse([]byte(tt.document))
+			require.NoError(t, err)
+
+			_, err = NewRequestValidator(document, "")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package cert_authority
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const certificateValidity = 365 * 24 * time.Hour
+
+// DefaultHosts are the names every issued server certificate is valid for.
+var DefaultHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+type CertificateAuthority interface {
+	CertPEM() []byte
+	IssueServerCertificate(hosts []string) (*tls.Certificate, error)
+	IssueClientCertificate(commonName string, sans []string) (*tls.Certificate, error)
+	CertPool() *x509.CertPool
+}
+
+// CertificateAuthorityImpl is a throwaway CA generated in memory, trusted only
+// by the clients given its certificate.
+type CertificateAuthorityImpl struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+}
+
+func NewCertificateAuthority(name string) (CertificateAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name, Organization: []string{"gockapi"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(certificateValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	return &CertificateAuthorityImpl{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}, nil
+}
+
+func (c *CertificateAuthorityImpl) CertPEM() []byte {
+	return c.certPEM
+}
+
+func (c *CertificateAuthorityImpl) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(c.cert)
+
+	return pool
+}
+
+// IssueServerCertificate issues a leaf certificate for the hosts, IP addresses
+// or DNS names, plus DefaultHosts.
+func (c *CertificateAuthorityImpl) IssueServerCertificate(hosts []string) (*tls.Certificate, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost", Organization: []string{"gockapi"}},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	addSANs(template, append(append([]string{}, DefaultHosts...), hosts...))
+
+	return c.issue(template)
+}
+
+// IssueClientCertificate issues a client certificate for commonName. Each SAN
+// becomes an IP address, an email address, a URI or a DNS name depending on
+// its form.
+func (c *CertificateAuthorityImpl) IssueClientCertificate(commonName string, sans []string) (*tls.Certificate, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	addSANs(template, sans)
+
+	return c.issue(template)
+}
+
+func (c *CertificateAuthorityImpl) issue(template *x509.Certificate) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+
+	template.SerialNumber, err = serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(certificateValidity)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, &key.PublicKey, c.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der, c.cert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+func addSANs(template *x509.Certificate, sans []string) {
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+			continue
+		}
+
+		if strings.Contains(san, "://") {
+			if uri, err := url.Parse(san); err == nil {
+				template.URIs = append(template.URIs, uri)
+				continue
+			}
+		}
+
+		if strings.Contains(san, "@") {
+			template.EmailAddresses = append(template.EmailAddresses, san)
+			continue
+		}
+
+		template.DNSNames = append(template.DNSNames, san)
+	}
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	return serial, nil
+}
@@ -0,0 +1,78 @@
+package cert_authority
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCertificateAuthority(t *testing.T) {
+	ca, err := NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	block, rest := pem.Decode(ca.CertPEM())
+	require.NotNil(t, block)
+	assert.Empty(t, rest)
+	assert.Equal(t, "CERTIFICATE", block.Type)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.True(t, cert.IsCA)
+	assert.Equal(t, "gockapi test CA", cert.Subject.CommonName)
+	assert.NotZero(t, cert.KeyUsage&x509.KeyUsageCertSign)
+
+	other, err := NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+	assert.NotEqual(t, ca.CertPEM(), other.CertPEM(), "every CA has its own key")
+}
+
+func TestIssueServerCertificate(t *testing.T) {
+	ca, err := NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	cert, err := ca.IssueServerCertificate([]string{"payments.local", "10.0.0.5"})
+	require.NoError(t, err)
+	require.Len(t, cert.Certificate, 2, "the chain includes the CA")
+
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "payments.local", "10.0.0.5"} {
+		_, err := cert.Leaf.Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     ca.CertPool(),
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		assert.NoError(t, err, host)
+	}
+
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: "other.local", Roots: ca.CertPool()})
+	assert.Error(t, err)
+
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: x509.NewCertPool()})
+	assert.Error(t, err, "only clients trusting the CA accept its certificates")
+
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{Roots: ca.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.Error(t, err, "server certificates cannot authenticate clients")
+}
+
+func TestIssueClientCertificate(t *testing.T) {
+	ca, err := NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	cert, err := ca.IssueClientCertificate("billing", []string{"billing.internal", "10.0.0.7", "spiffe://corp/billing", "ops@corp.example"})
+	require.NoError(t, err)
+
+	leaf := cert.Leaf
+	assert.Equal(t, "billing", leaf.Subject.CommonName)
+	assert.Equal(t, []string{"billing.internal"}, leaf.DNSNames)
+	assert.Equal(t, []string{"ops@corp.example"}, leaf.EmailAddresses)
+	require.Len(t, leaf.URIs, 1)
+	assert.Equal(t, "spiffe://corp/billing", leaf.URIs[0].String())
+	require.Len(t, leaf.IPAddresses, 1)
+	assert.True(t, leaf.IPAddresses[0].Equal(net.ParseIP("10.0.0.7")))
+
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: ca.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+}
@@ -2,8 +2,11 @@ package mock_server
 
 import (
 	"context"
+	"net/http"
 
 	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	certAuthority "github.com/JTGlez/gockapi/internal/server/cert_authority"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
 )
 
 type MockServer interface {
@@ -11,6 +14,9 @@ type MockServer interface {
 	Stop() error
 	Reload(config *configReader.ServiceConfig) error
 	IsHealthy() bool
+	// GetURL returns the base URL of HTTP servers, such as
+	// "http://localhost:55001", and the scheme-less address gRPC clients
+	// dial, such as "localhost:55100".
 	GetURL() string
 	GetServiceName() string
 	GetPort() int
@@ -21,6 +27,45 @@ type HealthChecker interface {
 	GetHealthEndpoint() string
 }
 
+type ScenarioController interface {
+	GetScenarioStates() map[string]string
+	ResetScenarios(names ...string) error
+}
+
+type CounterController interface {
+	GetCallCounts() map[string]int
+	ResetCounters(ids ...string)
+}
+
+type RequestRecorder interface {
+	GetRecordedRequests() []requestJournal.RecordedRequest
+	CountCalls(endpointKey string) int
+	ResetRecordedRequests()
+}
+
+type EndpointEditor interface {
+	GetConfig() *configReader.ServiceConfig
+	AddEndpoint(endpointKey string, variants configReader.EndpointList) error
+	ReplaceEndpoint(endpointKey string, variants configReader.EndpointList) error
+	DeleteEndpoint(endpointKey string) error
+}
+
+type HandlerRegistrar interface {
+	HandleFunc(method, path string, handler http.HandlerFunc) error
+	RemoveHandler(method, path string) bool
+}
+
+type TrafficRecorder interface {
+	StartRecording(target string, onRecord func(endpointKey string, endpoint configReader.EndpointConfig)) error
+	StopRecording()
+	IsRecording() bool
+}
+
+type TLSServer interface {
+	SetCertificateAuthority(ca certAuthority.CertificateAuthority)
+	CACertificate() []byte
+}
+
 type HealthStatus struct {
 	Healthy   bool              `json:"healthy"`
 	Service   string            `json:"service"`
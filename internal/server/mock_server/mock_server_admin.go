@@ -0,0 +1,299 @@
+package mock_server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/JTGlez/gockapi/internal/config_reader/impl"
+	"github.com/JTGlez/gockapi/internal/har"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+)
+
+const adminPrefix = "/_admin"
+
+type adminEndpoint struct {
+	Key      string                    `json:"key"`
+	Endpoint configReader.EndpointList `json:"endpoint"`
+}
+
+type adminRoute struct {
+	ID       string                      `json:"id"`
+	Key      string                      `json:"key"`
+	Endpoint configReader.EndpointConfig `json:"endpoint"`
+}
+
+func (m *MockServerImpl) registerAdminRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(adminPrefix+"/config", m.handleAdminConfig)
+	mux.HandleFunc(adminPrefix+"/endpoints", m.handleAdminEndpoints)
+	mux.HandleFunc(adminPrefix+"/requests", m.handleAdminRequests)
+	mux.HandleFunc(adminPrefix+"/reset", m.handleAdminReset)
+	mux.HandleFunc(adminPrefix+"/scenarios", m.handleScenarios)
+	mux.HandleFunc(adminPrefix+"/scenarios/reset", m.handleScenariosReset)
+	mux.HandleFunc(adminPrefix+"/counters", m.handleCounters)
+	mux.HandleFunc(adminPrefix+"/counters/reset", m.handleCountersReset)
+}
+
+func (m *MockServerImpl) GetConfig() *configReader.ServiceConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config
+}
+
+// AddEndpoint appends variants to an endpoint key, creating the key if needed.
+func (m *MockServerImpl) AddEndpoint(endpointKey string, variants configReader.EndpointList) error {
+	return m.updateEndpoints(func(endpoints map[string]configReader.EndpointList) error {
+		endpoints[endpointKey] = append(append(configReader.EndpointList{}, endpoints[endpointKey]...), variants...)
+		return nil
+	})
+}
+
+func (m *MockServerImpl) ReplaceEndpoint(endpointKey string, variants configReader.EndpointList) error {
+	return m.updateEndpoints(func(endpoints map[string]configReader.EndpointList) error {
+		if _, exists := endpoints[endpointKey]; !exists {
+			return fmt.Errorf("endpoint %s is not configured", endpointKey)
+		}
+
+		endpoints[endpointKey] = variants
+		return nil
+	})
+}
+
+func (m *MockServerImpl) DeleteEndpoint(endpointKey string) error {
+	return m.updateEndpoints(func(endpoints map[string]configReader.EndpointList) error {
+		if _, exists := endpoints[endpointKey]; !exists {
+			return fmt.Errorf("endpoint %s is not configured", endpointKey)
+		}
+
+		delete(endpoints, endpointKey)
+		return nil
+	})
+}
+
+// updateEndpoints applies a change to a copy of the active configuration and
+// swaps it in only when the result is still valid.
+func (m *MockServerImpl) updateEndpoints(change func(endpoints map[string]configReader.EndpointList) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated := *m.config
+	updated.Endpoints = make(map[string]configReader.EndpointList, len(m.config.Endpoints))
+	for endpointKey, variants := range m.config.Endpoints {
+		updated.Endpoints[endpointKey] = variants
+	}
+
+	if err := change(updated.Endpoints); err != nil {
+		return err
+	}
+
+	// The port is already bound and may be an ephemeral one outside the
+	// configurable range, so it is left out of validation.
+	candidate := updated
+	candidate.Port = 0
+
+	if err := impl.NewConfigValidator().Validate(&candidate); err != nil {
+		return err
+	}
+
+	m.config = &updated
+
+	return nil
+}
+
+func (m *MockServerImpl) GetScenarioStates() map[string]string {
+	return m.scenarios.States()
+}
+
+func (m *MockServerImpl) ResetScenarios(names ...string) error {
+	if len(names) == 0 {
+		m.scenarios.ResetAll()
+		return nil
+	}
+
+	for _, name := range names {
+		if err := m.scenarios.Reset(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MockServerImpl) GetCallCounts() map[string]int {
+	return m.counters.Counts()
+}
+
+func (m *MockServerImpl) ResetCounters(ids ...string) {
+	m.counters.Reset(ids...)
+}
+
+func (m *MockServerImpl) GetRecordedRequests() []requestJournal.RecordedRequest {
+	return m.journal.Entries()
+}
+
+func (m *MockServerImpl) CountCalls(endpointKey string) int {
+	return m.journal.Count(endpointKey)
+}
+
+func (m *MockServerImpl) ResetRecordedRequests() {
+	m.journal.Clear()
+}
+
+func (m *MockServerImpl) handleAdminConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.writeAdminJSON(w, http.StatusOK, m.GetConfig())
+}
+
+func (m *MockServerImpl) handleAdminEndpoints(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		routes := []adminRoute{}
+		for _, route := range configReader.OrderedRoutes(m.GetConfig().Endpoints) {
+			routes = append(routes, adminRoute{ID: route.ID(), Key: route.Key, Endpoint: route.Endpoint})
+		}
+
+		m.writeAdminJSON(w, http.StatusOK, routes)
+	case "POST":
+		var payload adminEndpoint
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			m.writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid endpoint payload: %w", err))
+			return
+		}
+
+		if err := m.AddEndpoint(payload.Key, payload.Endpoint); err != nil {
+			m.writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		m.writeAdminJSON(w, http.StatusCreated, adminEndpoint{Key: payload.Key, Endpoint: m.GetConfig().Endpoints[payload.Key]})
+	case "PUT":
+		endpointKey := r.URL.Query().Get("key")
+
+		var variants configReader.EndpointList
+		if err := json.NewDecoder(r.Body).Decode(&variants); err != nil {
+			m.writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid endpoint payload: %w", err))
+			return
+		}
+
+		if err := m.ReplaceEndpoint(endpointKey, variants); err != nil {
+			m.writeAdminError(w, m.adminErrorStatus(endpointKey), err)
+			return
+		}
+
+		m.writeAdminJSON(w, http.StatusOK, adminEndpoint{Key: endpointKey, Endpoint: variants})
+	case "DELETE":
+		endpointKey := r.URL.Query().Get("key")
+
+		if err := m.DeleteEndpoint(endpointKey); err != nil {
+			m.writeAdminError(w, m.adminErrorStatus(endpointKey), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (m *MockServerImpl) handleAdminRequests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		if r.URL.Query().Get("format") == "har" {
+			m.writeAdminJSON(w, http.StatusOK, har.FromJournal(m.GetURL(), m.GetRecordedRequests()))
+			return
+		}
+
+		m.writeAdminJSON(w, http.StatusOK, m.GetRecordedRequests())
+	case "DELETE":
+		m.ResetRecordedRequests()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (m *MockServerImpl) handleAdminReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.ResetCounters()
+	m.ResetRecordedRequests()
+	m.ResetScenarios()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (m *MockServerImpl) handleScenarios(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.writeAdminJSON(w, http.StatusOK, m.GetScenarioStates())
+}
+
+func (m *MockServerImpl) handleScenariosReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := m.ResetScenarios(r.URL.Query()["name"]...); err != nil {
+		m.writeAdminError(w, http.StatusNotFound, err)
+		return
+	}
+
+	m.writeAdminJSON(w, http.StatusOK, m.GetScenarioStates())
+}
+
+func (m *MockServerImpl) handleCounters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.writeAdminJSON(w, http.StatusOK, m.GetCallCounts())
+}
+
+func (m *MockServerImpl) handleCountersReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.ResetCounters(r.URL.Query()["id"]...)
+
+	m.writeAdminJSON(w, http.StatusOK, m.GetCallCounts())
+}
+
+// adminErrorStatus distinguishes unknown endpoint keys (404) from invalid
+// changes (400).
+func (m *MockServerImpl) adminErrorStatus(endpointKey string) int {
+	if _, exists := m.GetConfig().Endpoints[endpointKey]; !exists {
+		return http.StatusNotFound
+	}
+
+	return http.StatusBadRequest
+}
+
+func (m *MockServerImpl) writeAdminError(w http.ResponseWriter, statusCode int, err error) {
+	m.writeAdminJSON(w, statusCode, map[string]string{"error": err.Error()})
+}
+
+func (m *MockServerImpl) writeAdminJSON(w http.ResponseWriter, statusCode int, body any) {
+	endpointConfig := &configReader.EndpointConfig{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       body,
+	}
+
+	m.responseHandler.WriteResponse(w, endpointConfig)
+}
@@ -0,0 +1,200 @@
+package mock_server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminTestServer serves the mock and admin routes of a service over
+// httptest, without binding the service's own port.
+func newAdminTestServer(t *testing.T, cfg *configReader.ServiceConfig) (*MockServerImpl, *httptest.Server) {
+	t.Helper()
+
+	server := NewHTTPMockServer(cfg.ServiceName, cfg, handlers.NewResponseHandler()).(*MockServerImpl)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", server.handleRequest)
+	server.registerAdminRoutes(mux)
+
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	return server, httpServer
+}
+
+func adminRequest(t *testing.T, method, target, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, target, strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(data)
+}
+
+func adminTestConfig() *configReader.ServiceConfig {
+	return &configReader.ServiceConfig{
+		ServiceName: "adminService",
+		Scenarios: map[string]configReader.ScenarioConfig{
+			"order": {InitialState: "pending"},
+		},
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /users": {{StatusCode: http.StatusOK, Body: "users"}},
+			"POST /pay":  {{StatusCode: http.StatusOK, Scenario: "order", NewState: "paid"}},
+		},
+	}
+}
+
+func TestAdminEndpoints(t *testing.T) {
+	_, httpServer := newAdminTestServer(t, adminTestConfig())
+	endpoints := httpServer.URL + "/_admin/endpoints"
+	keyQuery := "?key=" + url.QueryEscape("GET /users")
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		expected int
+	}{
+		{name: "list", method: "GET", target: endpoints, expected: http.StatusOK},
+		{name: "add", method: "POST", target: endpoints, body: `{"key": "GET /orders", "endpoint": {"status_code": 200}}`, expected: http.StatusCreated},
+		{name: "add variant", method: "POST", target: endpoints, body: `{"key": "GET /users", "endpoint": [{"status_code": 401, "match": {"headers": {"X-Token": "bad"}}}]}`, expected: http.StatusCreated},
+		{name: "add malformed", method: "POST", target: endpoints, body: `{"key":`, expected: http.StatusBadRequest},
+		{name: "add invalid", method: "POST", target: endpoints, body: `{"key": "GET /broken", "endpoint": {"status_code": 999}}`, expected: http.StatusBadRequest},
+		{name: "add invalid key", method: "POST", target: endpoints, body: `{"key": "nokey", "endpoint": {"status_code": 200}}`, expected: http.StatusBadRequest},
+		{name: "replace", method: "PUT", target: endpoints + keyQuery, body: `[{"status_code": 202}]`, expected: http.StatusOK},
+		{name: "replace invalid", method: "PUT", target: endpoints + keyQuery, body: `[{"status_code": 999}]`, expected: http.StatusBadRequest},
+		{name: "replace malformed", method: "PUT", target: endpoints + keyQuery, body: `[`, expected: http.StatusBadRequest},
+		{name: "replace unknown", method: "PUT", target: endpoints + "?key=" + url.QueryEscape("GET /nope"), body: `[{"status_code": 200}]`, expected: http.StatusNotFound},
+		{name: "delete unknown", method: "DELETE", target: endpoints + "?key=" + url.QueryEscape("GET /nope"), expected: http.StatusNotFound},
+		{name: "delete", method: "DELETE", target: endpoints + "?key=" + url.QueryEscape("GET /orders"), expected: http.StatusNoContent},
+		{name: "unsupported method", method: "PATCH", target: endpoints, expected: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := adminRequest(t, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.expected, status, body)
+		})
+	}
+
+	status, body := adminRequest(t, "GET", httpServer.URL+"/users", "")
+	assert.Equal(t, http.StatusAccepted, status, "PUT replaced the variants")
+	assert.Empty(t, body)
+
+	status, _ = adminRequest(t, "GET", httpServer.URL+"/orders", "")
+	assert.Equal(t, http.StatusNotFound, status, "DELETE removed the endpoint")
+
+	_, body = adminRequest(t, "GET", endpoints, "")
+
+	var routes []adminRoute
+	require.NoError(t, json.Unmarshal([]byte(body), &routes))
+
+	ids := []string{}
+	for _, route := range routes {
+		ids = append(ids, route.ID)
+	}
+
+	assert.ElementsMatch(t, []string{"GET /users#0", "POST /pay#0"}, ids)
+}
+
+func TestAdminConfig(t *testing.T) {
+	_, httpServer := newAdminTestServer(t, adminTestConfig())
+
+	status, body := adminRequest(t, "GET", httpServer.URL+"/_admin/config", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"service_name":"adminService"`)
+
+	status, _ = adminRequest(t, "POST", httpServer.URL+"/_admin/config", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestAdminResets(t *testing.T) {
+	server, httpServer := newAdminTestServer(t, adminTestConfig())
+
+	adminRequest(t, "GET", httpServer.URL+"/users", "")
+	adminRequest(t, "POST", httpServer.URL+"/pay", "")
+
+	assert.Equal(t, map[string]string{"order": "paid"}, server.GetScenarioStates())
+	assert.Equal(t, map[string]int{"GET /users#0": 1, "POST /pay#0": 1}, server.GetCallCounts())
+	assert.Len(t, server.GetRecordedRequests(), 2)
+
+	status, body := adminRequest(t, "POST", httpServer.URL+"/_admin/counters/reset?id="+url.QueryEscape("GET /users#0"), "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"POST /pay#0": 1}`, body)
+
+	status, body = adminRequest(t, "GET", httpServer.URL+"/_admin/scenarios", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"order": "paid"}`, body)
+
+	status, _ = adminRequest(t, "POST", httpServer.URL+"/_admin/scenarios/reset?name=unknown", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = adminRequest(t, "POST", httpServer.URL+"/_admin/scenarios/reset?name=order", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"order": "pending"}`, body)
+
+	adminRequest(t, "POST", httpServer.URL+"/pay", "")
+
+	status, _ = adminRequest(t, "POST", httpServer.URL+"/_admin/reset", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, map[string]string{"order": "pending"}, server.GetScenarioStates())
+	assert.Empty(t, server.GetCallCounts())
+	assert.Empty(t, server.GetRecordedRequests())
+
+	for _, target := range []string{"/_admin/reset", "/_admin/scenarios/reset", "/_admin/counters/reset"} {
+		status, _ = adminRequest(t, "GET", httpServer.URL+target, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, status, target)
+	}
+
+	for _, target := range []string{"/_admin/scenarios", "/_admin/counters"} {
+		status, _ = adminRequest(t, "POST", httpServer.URL+target, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, status, target)
+	}
+}
+
+func TestAdminRequests(t *testing.T) {
+	_, httpServer := newAdminTestServer(t, adminTestConfig())
+
+	adminRequest(t, "GET", httpServer.URL+"/users?page=2", "")
+
+	status, body := adminRequest(t, "GET", httpServer.URL+"/_admin/requests", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/users", entries[0]["path"])
+	assert.Equal(t, "page=2", entries[0]["query"])
+	assert.Equal(t, "GET /users", entries[0]["endpoint_key"])
+
+	status, body = adminRequest(t, "GET", httpServer.URL+"/_admin/requests?format=har", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"log"`)
+
+	status, _ = adminRequest(t, "DELETE", httpServer.URL+"/_admin/requests", "")
+	assert.Equal(t, http.StatusNoContent, status)
+
+	_, body = adminRequest(t, "GET", httpServer.URL+"/_admin/requests", "")
+	assert.JSONEq(t, `[]`, body)
+
+	status, _ = adminRequest(t, "POST", httpServer.URL+"/_admin/requests", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
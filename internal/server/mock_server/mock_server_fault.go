@@ -0,0 +1,181 @@
+package mock_server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+const (
+	defaultBytesPerSecond = 100
+	randomGarbageSize     = 1024
+)
+
+// bufferedResponse captures a response instead of sending it.
+type bufferedResponse struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(statusCode int) {
+	if b.statusCode == 0 {
+		b.statusCode = statusCode
+	}
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	if b.statusCode == 0 {
+		b.statusCode = http.StatusOK
+	}
+
+	return b.body.Write(data)
+}
+
+// injectFault answers the request with the fault instead of the response write
+// renders. Every fault but slow_body takes over the connection and closes it.
+func (m *MockServerImpl) injectFault(w http.ResponseWriter, r *http.Request, fault *configReader.FaultConfig, write func(http.ResponseWriter) error) error {
+	response := &bufferedResponse{header: http.Header{}}
+	if err := write(response); err != nil {
+		return err
+	}
+
+	if fault.Type == configReader.FaultSlowBody {
+		writeSlowBody(w, r, response, fault.BytesPerSecond)
+		return nil
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// HTTP/2 streams cannot be hijacked, so the stream is reset instead.
+		abortStream(w, fault, response)
+		return err
+	}
+
+	defer conn.Close()
+
+	body := response.body.Bytes()
+
+	switch fault.Type {
+	case configReader.FaultConnectionReset:
+		return resetConnection(conn)
+	case configReader.FaultEmptyReply:
+		return nil
+	case configReader.FaultCloseMidBody:
+		writeHead(rw.Writer, response, "Content-Length", strconv.Itoa(max(len(body), 1)))
+		rw.Write(body[:len(body)/2])
+	case configReader.FaultMalformedChunk:
+		writeHead(rw.Writer, response, "Transfer-Encoding", "chunked")
+		fmt.Fprintf(rw, "zz\r\n%s\r\n", body)
+	case configReader.FaultRandomGarbage:
+		garbage := make([]byte, randomGarbageSize)
+		rand.Read(garbage)
+		rw.Write(garbage)
+	}
+
+	return rw.Flush()
+}
+
+// writeHead writes the status line and headers of response, with the given
+// framing header, straight to a hijacked connection.
+func writeHead(w *bufio.Writer, response *bufferedResponse, framing, value string) {
+	statusCode := response.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	response.header.Set(framing, value)
+	response.header.Set("Connection", "close")
+
+	fmt.Fprintf(w, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+	response.header.Write(w)
+	w.WriteString("\r\n")
+}
+
+// resetConnection closes the underlying TCP connection with SO_LINGER set to
+// zero, so the client gets a RST instead of an orderly shutdown.
+func resetConnection(conn net.Conn) error {
+	if wrapped, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = wrapped.NetConn()
+	}
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		if err := tcpConn.SetLinger(0); err != nil {
+			return err
+		}
+	}
+
+	return conn.Close()
+}
+
+// abortStream resets the stream of a connection that cannot be hijacked, after
+// sending half of the body for close_mid_body. It never returns: it panics with
+// http.ErrAbortHandler, which the server turns into a stream reset.
+func abortStream(w http.ResponseWriter, fault *configReader.FaultConfig, response *bufferedResponse) {
+	if fault.Type == configReader.FaultCloseMidBody {
+		body := response.body.Bytes()
+
+		for name, values := range response.header {
+			w.Header()[name] = values
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(max(len(body), 1)))
+		w.WriteHeader(max(response.statusCode, http.StatusOK))
+		w.Write(body[:len(body)/2])
+		http.NewResponseController(w).Flush()
+	}
+
+	panic(http.ErrAbortHandler)
+}
+
+// writeSlowBody sends the response at bytesPerSecond, flushing every chunk,
+// until the body is written or the client goes away.
+func writeSlowBody(w http.ResponseWriter, r *http.Request, response *bufferedResponse, bytesPerSecond int) {
+	if bytesPerSecond <= 0 {
+		bytesPerSecond = defaultBytesPerSecond
+	}
+
+	body := response.body.Bytes()
+
+	for name, values := range response.header {
+		w.Header()[name] = values
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(max(response.statusCode, http.StatusOK))
+
+	controller := http.NewResponseController(w)
+	chunkSize := max(bytesPerSecond/100, 1)
+	interval := time.Second * time.Duration(chunkSize) / time.Duration(bytesPerSecond)
+
+	for len(body) > 0 {
+		n := min(chunkSize, len(body))
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+
+		controller.Flush()
+		body = body[n:]
+
+		if len(body) == 0 {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
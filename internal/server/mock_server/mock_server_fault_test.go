@@ -0,0 +1,193 @@
+package mock_server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const faultTestBody = `{"id": 1, "name": "Ada"}`
+
+func faultTestConfig(fault *configReader.FaultConfig) *configReader.ServiceConfig {
+	return &configReader.ServiceConfig{
+		ServiceName: "faultService",
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /users": {{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       faultTestBody,
+				Fault:      fault,
+			}},
+		},
+	}
+}
+
+// fetch sends one request on a new connection and reads the whole body,
+// returning the first error the client sees.
+func fetch(client *http.Client, url string) (*http.Response, string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	return resp, string(body), err
+}
+
+func TestInjectFault(t *testing.T) {
+	tests := []struct {
+		fault  string
+		verify func(t *testing.T, resp *http.Response, body string, err error)
+	}{
+		{
+			fault: configReader.FaultConnectionReset,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorIs(t, err, syscall.ECONNRESET)
+			},
+		},
+		{
+			fault: configReader.FaultEmptyReply,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorIs(t, err, io.EOF)
+				assert.Nil(t, resp)
+			},
+		},
+		{
+			fault: configReader.FaultCloseMidBody,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+				require.NotNil(t, resp)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, faultTestBody[:len(faultTestBody)/2], body)
+			},
+		},
+		{
+			fault: configReader.FaultMalformedChunk,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorContains(t, err, "invalid byte in chunk length")
+				require.NotNil(t, resp)
+				assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+			},
+		},
+		{
+			fault: configReader.FaultRandomGarbage,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorContains(t, err, "malformed HTTP")
+				assert.Nil(t, resp)
+			},
+		},
+		{
+			fault: configReader.FaultNone,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, faultTestBody, body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fault, func(t *testing.T) {
+			server, httpServer := newAdminTestServer(t, faultTestConfig(&configReader.FaultConfig{Type: tt.fault}))
+			client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+			resp, body, err := fetch(client, httpServer.URL+"/users")
+			tt.verify(t, resp, body, err)
+
+			requests := server.GetRecordedRequests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, "GET /users", requests[0].EndpointKey)
+			if tt.fault != configReader.FaultNone {
+				assert.Equal(t, tt.fault, requests[0].Fault)
+			}
+		})
+	}
+}
+
+func TestInjectFaultSlowBody(t *testing.T) {
+	_, httpServer := newAdminTestServer(t, faultTestConfig(&configReader.FaultConfig{Type: configReader.FaultSlowBody, BytesPerSecond: 200}))
+
+	start := time.Now()
+	resp, body, err := fetch(http.DefaultClient, httpServer.URL+"/users")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, faultTestBody, body)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "%d bytes at 200 bytes per second", len(faultTestBody))
+}
+
+func TestInjectFaultProbability(t *testing.T) {
+	_, httpServer := newAdminTestServer(t, faultTestConfig(&configReader.FaultConfig{Type: configReader.FaultEmptyReply, Probability: 0.5}))
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	faulted, answered := 0, 0
+	for range 100 {
+		_, body, err := fetch(client, httpServer.URL+"/users")
+
+		switch {
+		case errors.Is(err, io.EOF):
+			faulted++
+		case err == nil && body == faultTestBody:
+			answered++
+		default:
+			t.Fatalf("unexpected result %q, %v", body, err)
+		}
+	}
+
+	assert.Positive(t, faulted)
+	assert.Positive(t, answered)
+}
+
+// HTTP/2 streams cannot be hijacked, so faults reset the stream instead of
+// the connection.
+func TestInjectFaultHTTP2(t *testing.T) {
+	tests := []struct {
+		fault  string
+		verify func(t *testing.T, resp *http.Response, body string, err error)
+	}{
+		{
+			fault: configReader.FaultConnectionReset,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorContains(t, err, "INTERNAL_ERROR")
+			},
+		},
+		{
+			fault: configReader.FaultCloseMidBody,
+			verify: func(t *testing.T, resp *http.Response, body string, err error) {
+				assert.ErrorContains(t, err, "INTERNAL_ERROR")
+				require.NotNil(t, resp)
+				assert.Equal(t, "HTTP/2.0", resp.Proto)
+				assert.Equal(t, faultTestBody[:len(faultTestBody)/2], body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fault, func(t *testing.T) {
+			cfg := faultTestConfig(&configReader.FaultConfig{Type: tt.fault})
+			server := NewHTTPMockServer(cfg.ServiceName, cfg, handlers.NewResponseHandler()).(*MockServerImpl)
+
+			httpServer := httptest.NewUnstartedServer(http.HandlerFunc(server.handleRequest))
+			httpServer.EnableHTTP2 = true
+			httpServer.StartTLS()
+			t.Cleanup(httpServer.Close)
+
+			resp, body, err := fetch(httpServer.Client(), httpServer.URL+"/users")
+			tt.verify(t, resp, body, err)
+
+			requests := server.GetRecordedRequests()
+			require.Len(t, requests, 1)
+			assert.Equal(t, tt.fault, requests[0].Fault)
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package mock_server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	callCounter "github.com/JTGlez/gockapi/internal/handlers/call_counter"
+	requestMatcher "github.com/JTGlez/gockapi/internal/handlers/request_matcher"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCMockServerImpl serves the gRPC methods of a service config, answering
+// calls with the responses configured for them. Unknown methods and calls no
+// response matches get an UNIMPLEMENTED status.
+type GRPCMockServerImpl struct {
+	serviceName  string
+	port         int
+	server       *grpc.Server
+	config       *configReader.ServiceConfig
+	methods      *grpcMethods
+	matcher      requestMatcher.RequestMatcher
+	counters     callCounter.CallCounter
+	journal      requestJournal.RequestJournal
+	mu           sync.RWMutex
+	running      bool
+	healthStatus HealthStatus
+}
+
+func NewGRPCMockServer(serviceName string, cfg *configReader.ServiceConfig) MockServer {
+	return &GRPCMockServerImpl{
+		serviceName: serviceName,
+		port:        cfg.Port,
+		config:      cfg,
+		matcher:     &requestMatcher.RequestMatcherImpl{},
+		counters:    callCounter.NewCallCounter(),
+		journal:     requestJournal.NewRequestJournal(maxJournalEntries),
+		healthStatus: HealthStatus{
+			Healthy:   false,
+			Service:   serviceName,
+			Port:      cfg.Port,
+			Message:   "Not started",
+			Timestamp: time.Now().Format(time.RFC3339),
+		},
+	}
+}
+
+func (m *GRPCMockServerImpl) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return fmt.Errorf("server %s is already running on port %d", m.serviceName, m.port)
+	}
+
+	if m.config.GRPC == nil {
+		return fmt.Errorf("service %s has no grpc configuration", m.serviceName)
+	}
+
+	methods, err := loadGRPCMethods(m.config.GRPC)
+	if err != nil {
+		return err
+	}
+
+	listener, port, err := listenPort(ctx, m.port)
+	if err != nil {
+		return fmt.Errorf("server %s failed to listen on port %d: %w", m.serviceName, m.port, err)
+	}
+
+	m.port = port
+	if m.config.Port != port {
+		updated := *m.config
+		updated.Port = port
+		m.config = &updated
+	}
+
+	m.methods = methods
+	m.server = grpc.NewServer(grpc.UnknownServiceHandler(m.handleStream))
+
+	go func() {
+		if err := m.server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			m.mu.Lock()
+			m.healthStatus = HealthStatus{
+				Healthy:   false,
+				Service:   m.serviceName,
+				Port:      m.port,
+				Message:   fmt.Sprintf("Server failed: %v", err),
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			m.running = false
+			m.mu.Unlock()
+		}
+	}()
+
+	m.running = true
+	m.healthStatus = HealthStatus{
+		Healthy: true,
+		Service: m.serviceName,
+		Port:    m.port,
+		Message: "Server running",
+		Details: map[string]string{
+			"methods": fmt.Sprintf("%d", len(methods.byName)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	return nil
+}
+
+func (m *GRPCMockServerImpl) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return fmt.Errorf("server %s is not running", m.serviceName)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		m.server.GracefulStop()
+		close(stopped)
+	}()
+
+	// Streams still open after the timeout are cancelled.
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		m.server.Stop()
+	}
+
+	m.running = false
+	m.healthStatus = HealthStatus{
+		Healthy:   false,
+		Service:   m.serviceName,
+		Port:      m.port,
+		Message:   "Server stopped",
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	return nil
+}
+
+func (m *GRPCMockServerImpl) Reload(config *configReader.ServiceConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return fmt.Errorf("server %s is not running, cannot reload", m.serviceName)
+	}
+
+	if config.ServiceName != m.serviceName {
+		return fmt.Errorf("config service name %s does not match server %s", config.ServiceName, m.serviceName)
+	}
+
+	if config.Port != m.port {
+		return fmt.Errorf("cannot change port from %d to %d via reload, restart required", m.port, config.Port)
+	}
+
+	if config.GRPC == nil {
+		return fmt.Errorf("cannot turn grpc service %s into an http service via reload, restart required", m.serviceName)
+	}
+
+	// The descriptor set is read again, so changed protos apply too.
+	methods, err := loadGRPCMethods(config.GRPC)
+	if err != nil {
+		return err
+	}
+
+	previous := len(m.methods.byName)
+	m.config = config
+	m.methods = methods
+
+	m.healthStatus = HealthStatus{
+		Healthy: true,
+		Service: m.serviceName,
+		Port:    m.port,
+		Message: "Configuration reloaded",
+		Details: map[string]string{
+			"methods":      fmt.Sprintf("%d", len(methods.byName)),
+			"last_reload":  time.Now().Format(time.RFC3339),
+			"prev_methods": fmt.Sprintf("%d", previous),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	return nil
+}
+
+func (m *GRPCMockServerImpl) IsHealthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.running && m.healthStatus.Healthy && m.isServerListening()
+}
+
+// GetURL returns the address gRPC clients dial, such as "localhost:55100",
+// which unlike the URL of HTTP servers has no scheme.
+func (m *GRPCMockServerImpl) GetURL() string {
+	return fmt.Sprintf("localhost:%d", m.port)
+}
+
+func (m *GRPCMockServerImpl) GetServiceName() string {
+	return m.serviceName
+}
+
+func (m *GRPCMockServerImpl) GetPort() int {
+	return m.port
+}
+
+func (m *GRPCMockServerImpl) GetCallCounts() map[string]int {
+	return m.counters.Counts()
+}
+
+func (m *GRPCMockServerImpl) ResetCounters(ids ...string) {
+	m.counters.Reset(ids...)
+}
+
+func (m *GRPCMockServerImpl) GetRecordedRequests() []requestJournal.RecordedRequest {
+	return m.journal.Entries()
+}
+
+func (m *GRPCMockServerImpl) CountCalls(endpointKey string) int {
+	return m.journal.Count(endpointKey)
+}
+
+func (m *GRPCMockServerImpl) ResetRecordedRequests() {
+	m.journal.Clear()
+}
+
+func (m *GRPCMockServerImpl) isServerListening() bool {
+	if !m.running {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", m.port), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	return true
+}
+
+// grpcCall is the journal entry of a call being served, with the messages
+// exchanged in their JSON form.
+type grpcCall struct {
+	entry    requestJournal.RecordedRequest
+	received []string
+	sent     []string
+	header   metadata.MD
+	trailer  metadata.MD
+}
+
+func (m *GRPCMockServerImpl) handleStream(_ any, stream grpc.ServerStream) error {
+	fullMethod, _ := grpc.MethodFromServerStream(stream)
+	incoming, _ := metadata.FromIncomingContext(stream.Context())
+
+	m.mu.RLock()
+	methods := m.methods
+	serviceDelay := m.config.Delay
+	m.mu.RUnlock()
+
+	call := &grpcCall{
+		entry: requestJournal.RecordedRequest{
+			Method:    http.MethodPost,
+			Host:      strings.Join(incoming.Get(":authority"), ""),
+			Path:      fullMethod,
+			Protocol:  "HTTP/2.0",
+			Headers:   metadataHeader(incoming),
+			Timestamp: time.Now(),
+		},
+		header:  metadata.MD{},
+		trailer: metadata.MD{},
+	}
+
+	err := m.serveCall(stream, methods, fullMethod, serviceDelay, call)
+
+	m.journal.Record(call.finish(err))
+
+	return err
+}
+
+func (m *GRPCMockServerImpl) serveCall(stream grpc.ServerStream, methods *grpcMethods, fullMethod string, serviceDelay *configReader.DelayConfig, call *grpcCall) error {
+	method, ok := methods.byName[fullMethod]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "method %s is not mocked by %s", fullMethod, m.serviceName)
+	}
+
+	descriptor := method.descriptor
+
+	if descriptor.IsStreamingClient() && descriptor.IsStreamingServer() {
+		return m.serveBidiStream(stream, methods, method, serviceDelay, call)
+	}
+
+	var body string
+	for {
+		request := dynamicpb.NewMessage(descriptor.Input())
+
+		err := stream.RecvMsg(request)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		encoded, err := methods.encode(request)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to encode request: %v", err)
+		}
+
+		call.received = append(call.received, encoded)
+		body = encoded
+
+		if !descriptor.IsStreamingClient() {
+			break
+		}
+	}
+
+	// Client streams are matched as the array of the messages received.
+	if descriptor.IsStreamingClient() {
+		body = "[" + strings.Join(call.received, ",") + "]"
+	}
+
+	response, err := m.matchResponse(stream.Context(), method, call.entry.Headers, body)
+	if err != nil {
+		return err
+	}
+
+	call.entry.EndpointKey = method.key
+	call.entry.EndpointID = method.key
+	m.counters.Increment(method.key)
+
+	return m.respond(stream, methods, descriptor.IsStreamingServer(), response, serviceDelay, call)
+}
+
+// serveBidiStream answers each message of a bidirectional stream on its own,
+// until the client closes its side or a response ends the call.
+func (m *GRPCMockServerImpl) serveBidiStream(stream grpc.ServerStream, methods *grpcMethods, method *grpcMethod, serviceDelay *configReader.DelayConfig, call *grpcCall) error {
+	for {
+		request := dynamicpb.NewMessage(method.descriptor.Input())
+
+		err := stream.RecvMsg(request)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		encoded, err := methods.encode(request)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to encode request: %v", err)
+		}
+
+		call.received = append(call.received, encoded)
+
+		response, err := m.matchResponse(stream.Context(), method, call.entry.Headers, encoded)
+		if err != nil {
+			return err
+		}
+
+		if call.entry.EndpointKey == "" {
+			call.entry.EndpointKey = method.key
+			call.entry.EndpointID = method.key
+			m.counters.Increment(method.key)
+		}
+
+		if err := m.respond(stream, methods, true, response, serviceDelay, call); err != nil {
+			return err
+		}
+	}
+}
+
+// matchResponse returns the first response of the method whose matchers
+// accept the call, with its metadata as headers and its request as JSON body.
+func (m *GRPCMockServerImpl) matchResponse(ctx context.Context, method *grpcMethod, header http.Header, body string) (*grpcResponse, error) {
+	for i := range method.responses {
+		response := &method.responses[i]
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/"+method.key, bytes.NewBufferString(body))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to match request: %v", err)
+		}
+
+		r.Header = header
+		r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/2.0", 2, 0
+
+		headerMatches, err := m.matcher.MatchHeaders(r, response.config.Match.Headers)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "method %s: %v", method.key, err)
+		}
+
+		if !headerMatches {
+			continue
+		}
+
+		bodyMatches, err := m.matcher.MatchBody(r, response.config.Match.Body)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "method %s: %v", method.key, err)
+		}
+
+		if bodyMatches {
+			return response, nil
+		}
+	}
+
+	return nil, status.Errorf(codes.Unimplemented, "no response of %s matches the request", method.key)
+}
+
+// respond sends the messages of response, after its delay or the service
+// delay, and returns its status. Unary calls failing with a status other than
+// OK send no message.
+func (m *GRPCMockServerImpl) respond(stream grpc.ServerStream, methods *grpcMethods, streaming bool, response *grpcResponse, serviceDelay *configReader.DelayConfig, call *grpcCall) error {
+	ctx := stream.Context()
+
+	delay := response.config.Delay
+	if delay == nil {
+		delay = serviceDelay
+	}
+
+	if !delay.Wait(ctx) {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	// Headers go out with the first message, so later responses of a
+	// bidirectional stream cannot change them.
+	if len(call.sent) == 0 {
+		header := metadata.New(response.config.Headers)
+		call.header = metadata.Join(call.header, header)
+
+		if err := stream.SetHeader(header); err != nil {
+			return err
+		}
+	}
+
+	if streaming || response.code == codes.OK {
+		for _, message := range response.messages {
+			if !message.delay.Wait(ctx) {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+
+			if err := stream.SendMsg(message.message); err != nil {
+				return err
+			}
+
+			encoded, _ := methods.encode(message.message)
+			call.sent = append(call.sent, encoded)
+		}
+	}
+
+	trailer := metadata.New(response.config.Trailers)
+	call.trailer = metadata.Join(call.trailer, trailer)
+	stream.SetTrailer(trailer)
+
+	if response.code != codes.OK {
+		return status.Error(response.code, response.config.Message)
+	}
+
+	return nil
+}
+
+// finish completes the journal entry with the outcome of the call. The
+// response carries the status and metadata as headers and the messages sent,
+// as a JSON array when the call sent several.
+func (c *grpcCall) finish(err error) requestJournal.RecordedRequest {
+	c.entry.Body = jsonMessages(c.received)
+
+	callStatus := status.Convert(err)
+
+	headers := metadataHeader(metadata.Join(c.header, c.trailer))
+	headers.Set("Content-Type", "application/grpc")
+	headers.Set("Grpc-Status", strconv.Itoa(int(callStatus.Code())))
+
+	if callStatus.Message() != "" {
+		headers.Set("Grpc-Message", callStatus.Message())
+	}
+
+	c.entry.Response = &requestJournal.RecordedResponse{
+		StatusCode: http.StatusOK,
+		Headers:    headers,
+		Body:       jsonMessages(c.sent),
+		Duration:   time.Since(c.entry.Timestamp),
+	}
+
+	return c.entry
+}
+
+// jsonMessages returns a single message as is and several as a JSON array.
+func jsonMessages(messages []string) string {
+	if len(messages) == 1 {
+		return messages[0]
+	}
+
+	if len(messages) == 0 {
+		return ""
+	}
+
+	return "[" + strings.Join(messages, ",") + "]"
+}
+
+// metadataHeader converts gRPC metadata into HTTP headers, leaving out
+// pseudo-headers such as ":authority".
+func metadataHeader(md metadata.MD) http.Header {
+	header := http.Header{}
+	for name, values := range md {
+		if strings.HasPrefix(name, ":") {
+			continue
+		}
+
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+
+	return header
+}
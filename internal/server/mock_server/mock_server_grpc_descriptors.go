@@ -0,0 +1,306 @@
+package mock_server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcMethod is a configured method with its responses converted to proto
+// messages.
+type grpcMethod struct {
+	key        string
+	descriptor protoreflect.MethodDescriptor
+	responses  []grpcResponse
+}
+
+type grpcResponse struct {
+	config   configReader.GRPCResponseConfig
+	code     codes.Code
+	messages []grpcMessage
+}
+
+type grpcMessage struct {
+	message proto.Message
+	delay   *configReader.DelayConfig
+}
+
+// grpcMethods are the methods of a gRPC service, keyed by their full name such
+// as "/payments.v1.Payments/Charge".
+type grpcMethods struct {
+	byName    map[string]*grpcMethod
+	marshaler protojson.MarshalOptions
+}
+
+// loadGRPCMethods reads the descriptor set of the service and converts the
+// configured responses into messages of the declared types, so configuration
+// errors surface when the service starts or reloads.
+func loadGRPCMethods(cfg *configReader.GRPCConfig) (*grpcMethods, error) {
+	files, err := loadDescriptorSet(cfg.DescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	types := dynamicpb.NewTypes(files)
+	unmarshaler := protojson.UnmarshalOptions{Resolver: types}
+
+	methods := &grpcMethods{
+		byName:    make(map[string]*grpcMethod),
+		marshaler: protojson.MarshalOptions{Resolver: types, UseProtoNames: true},
+	}
+
+	for key, responses := range cfg.Methods {
+		serviceName, methodName, ok := configReader.ParseGRPCMethod(key)
+		if !ok {
+			return nil, fmt.Errorf("invalid grpc method %s", key)
+		}
+
+		descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+		if err != nil {
+			return nil, fmt.Errorf("grpc service %s is not declared in %s", serviceName, cfg.DescriptorSet)
+		}
+
+		service, ok := descriptor.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a grpc service", serviceName)
+		}
+
+		methodDescriptor := service.Methods().ByName(protoreflect.Name(methodName))
+		if methodDescriptor == nil {
+			return nil, fmt.Errorf("grpc method %s is not declared by service %s", methodName, serviceName)
+		}
+
+		method := &grpcMethod{key: serviceName + "/" + methodName, descriptor: methodDescriptor}
+
+		for _, responseConfig := range responses {
+			response, err := newGRPCResponse(methodDescriptor, responseConfig, unmarshaler)
+			if err != nil {
+				return nil, fmt.Errorf("invalid grpc method %s: %w", key, err)
+			}
+
+			method.responses = append(method.responses, response)
+		}
+
+		methods.byName[fmt.Sprintf("/%s/%s", serviceName, methodName)] = method
+	}
+
+	return methods, nil
+}
+
+func newGRPCResponse(method protoreflect.MethodDescriptor, cfg configReader.GRPCResponseConfig, unmarshaler protojson.UnmarshalOptions) (grpcResponse, error) {
+	code, err := cfg.StatusCode()
+	if err != nil {
+		return grpcResponse{}, err
+	}
+
+	response := grpcResponse{config: cfg, code: code}
+
+	if len(cfg.Stream) > 0 && !method.IsStreamingServer() {
+		return grpcResponse{}, fmt.Errorf("stream is only supported by methods streaming responses")
+	}
+
+	if cfg.Response != nil {
+		message, err := newGRPCMessage(method.Output(), cfg.Response, unmarshaler)
+		if err != nil {
+			return grpcResponse{}, err
+		}
+
+		response.messages = append(response.messages, grpcMessage{message: message})
+	}
+
+	for _, streamed := range cfg.Stream {
+		message, err := newGRPCMessage(method.Output(), streamed.Message, unmarshaler)
+		if err != nil {
+			return grpcResponse{}, err
+		}
+
+		response.messages = append(response.messages, grpcMessage{message: message, delay: streamed.Delay})
+	}
+
+	// Unary responses always carry a message, empty unless configured.
+	if len(response.messages) == 0 && !method.IsStreamingServer() {
+		response.messages = append(response.messages, grpcMessage{message: dynamicpb.NewMessage(method.Output())})
+	}
+
+	return response, nil
+}
+
+// encode returns message in proto3 JSON with the field names of the .proto
+// file. 64-bit integers, which proto3 JSON writes as strings, are written as
+// numbers so body matchers can compare them.
+func (g *grpcMethods) encode(message proto.Message) (string, error) {
+	data, err := g.marshaler.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(int64Numbers(document, message.ProtoReflect().Descriptor()))
+	if err != nil {
+		return "", err
+	}
+
+	return string(normalized), nil
+}
+
+func int64Numbers(value any, descriptor protoreflect.MessageDescriptor) any {
+	object, ok := value.(map[string]any)
+	if !ok || strings.HasPrefix(string(descriptor.FullName()), "google.protobuf.") {
+		return value
+	}
+
+	for name, fieldValue := range object {
+		field := descriptor.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			continue
+		}
+
+		switch {
+		case field.IsMap():
+			if entries, ok := fieldValue.(map[string]any); ok {
+				for key, entry := range entries {
+					entries[key] = int64FieldValue(entry, field.MapValue())
+				}
+			}
+		case field.IsList():
+			if items, ok := fieldValue.([]any); ok {
+				for i, item := range items {
+					items[i] = int64FieldValue(item, field)
+				}
+			}
+		default:
+			object[name] = int64FieldValue(fieldValue, field)
+		}
+	}
+
+	return object
+}
+
+func int64FieldValue(value any, field protoreflect.FieldDescriptor) any {
+	switch field.Kind() {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if text, ok := value.(string); ok {
+			return json.Number(text)
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return int64Numbers(value, field.Message())
+	}
+
+	return value
+}
+
+func newGRPCMessage(descriptor protoreflect.MessageDescriptor, body any, unmarshaler protojson.UnmarshalOptions) (proto.Message, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s message: %w", descriptor.FullName(), err)
+	}
+
+	message := dynamicpb.NewMessage(descriptor)
+	if err := unmarshaler.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %w", descriptor.FullName(), err)
+	}
+
+	return message, nil
+}
+
+// loadDescriptorSet reads a FileDescriptorSet. Imports missing from the set,
+// such as the well-known types, are resolved from the types linked into the
+// binary.
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set %s: %w", path, err)
+	}
+
+	pending := make(map[string]*descriptorpb.FileDescriptorProto, len(set.GetFile()))
+	for _, file := range set.GetFile() {
+		pending[file.GetName()] = file
+	}
+
+	files := &protoregistry.Files{}
+	resolver := &descriptorResolver{local: files}
+
+	var register func(name string, seen map[string]bool) error
+	register = func(name string, seen map[string]bool) error {
+		file, ok := pending[name]
+		if !ok {
+			return nil
+		}
+
+		if seen[name] {
+			return fmt.Errorf("import cycle through %s in descriptor set %s", name, path)
+		}
+
+		seen[name] = true
+
+		for _, dependency := range file.GetDependency() {
+			if err := register(dependency, seen); err != nil {
+				return err
+			}
+		}
+
+		delete(pending, name)
+
+		descriptor, err := protodesc.NewFile(file, resolver)
+		if err != nil {
+			return fmt.Errorf("invalid file %s in descriptor set %s: %w", name, path, err)
+		}
+
+		return files.RegisterFile(descriptor)
+	}
+
+	for _, file := range set.GetFile() {
+		if err := register(file.GetName(), map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// descriptorResolver finds descriptors in the files of a descriptor set, then
+// in the global registry.
+type descriptorResolver struct {
+	local *protoregistry.Files
+}
+
+func (d *descriptorResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if file, err := d.local.FindFileByPath(path); err == nil {
+		return file, nil
+	}
+
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (d *descriptorResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if descriptor, err := d.local.FindDescriptorByName(name); err == nil {
+		return descriptor, nil
+	}
+
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
@@ -0,0 +1,282 @@
+package mock_server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// paymentsDescriptorSet declares testdata/payments.proto.
+const paymentsDescriptorSet = "testdata/payments.binpb"
+
+const chargeMethod = "/payments.v1.Payments/Charge"
+
+func grpcTestConfig(methods map[string][]configReader.GRPCResponseConfig) *configReader.ServiceConfig {
+	return &configReader.ServiceConfig{
+		ServiceName: "payments",
+		GRPC: &configReader.GRPCConfig{
+			DescriptorSet: paymentsDescriptorSet,
+			Methods:       methods,
+		},
+	}
+}
+
+func startGRPCServer(t *testing.T, cfg *configReader.ServiceConfig) (*GRPCMockServerImpl, *grpc.ClientConn) {
+	t.Helper()
+
+	server := NewGRPCMockServer(cfg.ServiceName, cfg).(*GRPCMockServerImpl)
+	require.NoError(t, server.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+
+	conn, err := grpc.NewClient(server.GetURL(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return server, conn
+}
+
+// paymentsMessage returns an empty message of the payments.v1 package.
+func paymentsMessage(t *testing.T, name string) *dynamicpb.Message {
+	t.Helper()
+
+	files, err := loadDescriptorSet(paymentsDescriptorSet)
+	require.NoError(t, err)
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName("payments.v1." + name))
+	require.NoError(t, err)
+
+	return dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+}
+
+func chargeRequest(t *testing.T, customer string, amount int64) *dynamicpb.Message {
+	t.Helper()
+
+	request := paymentsMessage(t, "ChargeRequest")
+	fields := request.Descriptor().Fields()
+	request.Set(fields.ByName("customer"), protoreflect.ValueOfString(customer))
+	request.Set(fields.ByName("amount"), protoreflect.ValueOfInt64(amount))
+
+	return request
+}
+
+func TestServeGRPC(t *testing.T) {
+	server, conn := startGRPCServer(t, grpcTestConfig(map[string][]configReader.GRPCResponseConfig{
+		"payments.v1.Payments/Charge": {
+			{
+				Match:   configReader.MatchConfig{Headers: map[string]configReader.ValueMatcher{"x-tenant": {EqualTo: "blocked"}}},
+				Code:    "PERMISSION_DENIED",
+				Message: "tenant blocked",
+			},
+			{
+				Match:   configReader.MatchConfig{Body: []configReader.BodyMatcher{{JSONPath: "$.amount > 1000"}}},
+				Code:    "failed_precondition",
+				Message: "amount too large",
+			},
+			{
+				Response: map[string]any{"id": "ch_1", "status": "succeeded"},
+				Headers:  map[string]string{"x-request-id": "req-1"},
+				Trailers: map[string]string{"x-charge": "ch_1"},
+			},
+		},
+	}))
+
+	assert.Equal(t, fmt.Sprintf("localhost:%d", server.GetPort()), server.GetURL())
+
+	tests := []struct {
+		name        string
+		metadata    metadata.MD
+		amount      int64
+		wantCode    codes.Code
+		wantMessage string
+		wantReply   map[string]string
+	}{
+		{
+			name:      "default response",
+			metadata:  metadata.Pairs("x-tenant", "acme"),
+			amount:    500,
+			wantCode:  codes.OK,
+			wantReply: map[string]string{"id": "ch_1", "status": "succeeded"},
+		},
+		{
+			name:        "metadata match",
+			metadata:    metadata.Pairs("x-tenant", "blocked"),
+			amount:      500,
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "tenant blocked",
+		},
+		{
+			name:        "body match",
+			metadata:    metadata.Pairs("x-tenant", "acme"),
+			amount:      5000,
+			wantCode:    codes.FailedPrecondition,
+			wantMessage: "amount too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewOutgoingContext(context.Background(), tt.metadata)
+			reply := paymentsMessage(t, "ChargeReply")
+
+			var header, trailer metadata.MD
+			err := conn.Invoke(ctx, chargeMethod, chargeRequest(t, "cus_1", tt.amount), reply, grpc.Header(&header), grpc.Trailer(&trailer))
+
+			callStatus := status.Convert(err)
+			assert.Equal(t, tt.wantCode, callStatus.Code())
+			assert.Equal(t, tt.wantMessage, callStatus.Message())
+
+			if tt.wantReply == nil {
+				return
+			}
+
+			fields := reply.Descriptor().Fields()
+			for name, want := range tt.wantReply {
+				assert.Equal(t, want, reply.Get(fields.ByName(protoreflect.Name(name))).String(), name)
+			}
+
+			assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+			assert.Equal(t, []string{"ch_1"}, trailer.Get("x-charge"))
+		})
+	}
+
+	assert.Equal(t, 3, server.CountCalls("payments.v1.Payments/Charge"))
+	assert.Equal(t, map[string]int{"payments.v1.Payments/Charge": 3}, server.GetCallCounts())
+
+	entries := server.GetRecordedRequests()
+	require.Len(t, entries, 3)
+	assert.Equal(t, chargeMethod, entries[0].Path)
+	assert.JSONEq(t, `{"customer": "cus_1", "amount": 500}`, entries[0].Body)
+	assert.JSONEq(t, `{"id": "ch_1", "status": "succeeded"}`, entries[0].Response.Body)
+	assert.Equal(t, "0", entries[0].Response.Headers.Get("Grpc-Status"))
+	assert.Equal(t, "7", entries[1].Response.Headers.Get("Grpc-Status"))
+	assert.Equal(t, "tenant blocked", entries[1].Response.Headers.Get("Grpc-Message"))
+}
+
+func TestServeGRPCUnimplemented(t *testing.T) {
+	server, conn := startGRPCServer(t, grpcTestConfig(map[string][]configReader.GRPCResponseConfig{
+		"payments.v1.Payments/Charge": {{
+			Match:    configReader.MatchConfig{Headers: map[string]configReader.ValueMatcher{"x-tenant": {EqualTo: "acme"}}},
+			Response: map[string]any{"id": "ch_1"},
+		}},
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		wantMessage string
+	}{
+		{
+			name:        "unknown method",
+			method:      "/payments.v1.Payments/Refund",
+			wantMessage: "method /payments.v1.Payments/Refund is not mocked by payments",
+		},
+		{
+			name:        "no matching response",
+			method:      chargeMethod,
+			wantMessage: "no response of payments.v1.Payments/Charge matches the request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := conn.Invoke(context.Background(), tt.method, chargeRequest(t, "cus_1", 500), paymentsMessage(t, "ChargeReply"))
+
+			callStatus := status.Convert(err)
+			assert.Equal(t, codes.Unimplemented, callStatus.Code())
+			assert.Equal(t, tt.wantMessage, callStatus.Message())
+		})
+	}
+
+	assert.Empty(t, server.GetCallCounts())
+	assert.Len(t, server.GetRecordedRequests(), 2)
+}
+
+func TestLoadGRPCMethods(t *testing.T) {
+	tests := []struct {
+		name          string
+		descriptorSet string
+		methods       map[string][]configReader.GRPCResponseConfig
+		wantErr       string
+	}{
+		{
+			name:          "valid",
+			descriptorSet: paymentsDescriptorSet,
+			methods: map[string][]configReader.GRPCResponseConfig{
+				"/payments.v1.Payments/Charge": {{Response: map[string]any{"id": "ch_1"}}},
+			},
+		},
+		{
+			name:          "missing descriptor set",
+			descriptorSet: "testdata/missing.binpb",
+			wantErr:       "failed to read descriptor set",
+		},
+		{
+			name:          "unknown service",
+			descriptorSet: paymentsDescriptorSet,
+			methods: map[string][]configReader.GRPCResponseConfig{
+				"payments.v1.Refunds/Refund": {{}},
+			},
+			wantErr: "grpc service payments.v1.Refunds is not declared in testdata/payments.binpb",
+		},
+		{
+			name:          "unknown method",
+			descriptorSet: paymentsDescriptorSet,
+			methods: map[string][]configReader.GRPCResponseConfig{
+				"payments.v1.Payments/Refund": {{}},
+			},
+			wantErr: "grpc method Refund is not declared by service payments.v1.Payments",
+		},
+		{
+			name:          "unknown field",
+			descriptorSet: paymentsDescriptorSet,
+			methods: map[string][]configReader.GRPCResponseConfig{
+				"payments.v1.Payments/Charge": {{Response: map[string]any{"total": 1}}},
+			},
+			wantErr: "invalid payments.v1.ChargeReply message",
+		},
+		{
+			name:          "stream on unary method",
+			descriptorSet: paymentsDescriptorSet,
+			methods: map[string][]configReader.GRPCResponseConfig{
+				"payments.v1.Payments/Charge": {{Stream: []configReader.GRPCMessageConfig{{Message: map[string]any{"id": "ch_1"}}}}},
+			},
+			wantErr: "stream is only supported by methods streaming responses",
+		},
+		{
+			name:          "invalid code",
+			descriptorSet: paymentsDescriptorSet,
+			methods: map[string][]configReader.GRPCResponseConfig{
+				"payments.v1.Payments/Charge": {{Code: "BROKEN"}},
+			},
+			wantErr: "invalid grpc status code: BROKEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods, err := loadGRPCMethods(&configReader.GRPCConfig{DescriptorSet: tt.descriptorSet, Methods: tt.methods})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, methods.byName, chargeMethod)
+		})
+	}
+}
@@ -2,23 +2,46 @@ package mock_server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"reflect"
+	"slices"
 	"sync"
 	"time"
 
 	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	callCounter "github.com/JTGlez/gockapi/internal/handlers/call_counter"
+	handlerRegistry "github.com/JTGlez/gockapi/internal/handlers/handler_registry"
+	requestMatcher "github.com/JTGlez/gockapi/internal/handlers/request_matcher"
 	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	scenarioStore "github.com/JTGlez/gockapi/internal/handlers/scenario_store"
+	"github.com/JTGlez/gockapi/internal/openapi"
+	certAuthority "github.com/JTGlez/gockapi/internal/server/cert_authority"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
 )
 
+const maxJournalEntries = 1000
+
 type MockServerImpl struct {
 	serviceName     string
 	port            int
 	server          *http.Server
 	config          *configReader.ServiceConfig
 	responseHandler handlers.ResponseHandler
+	scenarios       scenarioStore.ScenarioStore
+	counters        callCounter.CallCounter
+	handlers        handlerRegistry.HandlerRegistry
+	journal         requestJournal.RequestJournal
+	validator       *openapi.RequestValidator
+	recording       *recording
+	useTLS          bool
+	ca              certAuthority.CertificateAuthority
+	caPEM           []byte
+	clientCAs       *x509.CertPool
 	mu              sync.RWMutex
 	running         bool
 	healthStatus    HealthStatus
@@ -30,6 +53,11 @@ func NewHTTPMockServer(serviceName string, cfg *configReader.ServiceConfig, hand
 		port:            cfg.Port,
 		config:          cfg,
 		responseHandler: handler,
+		scenarios:       scenarioStore.NewScenarioStore(cfg.Scenarios),
+		counters:        callCounter.NewCallCounter(),
+		handlers:        handlerRegistry.NewHandlerRegistry(),
+		journal:         requestJournal.NewRequestJournal(maxJournalEntries),
+		useTLS:          cfg.TLS != nil,
 		healthStatus: HealthStatus{
 			Healthy:   false,
 			Service:   serviceName,
@@ -48,22 +76,64 @@ func (m *MockServerImpl) Start(ctx context.Context) error {
 		return fmt.Errorf("server %s is already running on port %d", m.serviceName, m.port)
 	}
 
+	validator, err := loadRequestValidator(m.config)
+	if err != nil {
+		return err
+	}
+
+	m.validator = validator
+
+	var tlsConfig *tls.Config
+	if m.config.TLS != nil {
+		tlsConfig, m.caPEM, err = m.loadTLSConfig(m.config.TLS)
+		if err != nil {
+			return err
+		}
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/", m.handleRequest)
 
+	// Health and admin routes skip the client certificate check of
+	// handleRequest, so probes and the CLI reach mTLS services without one.
 	mux.HandleFunc("/_health", m.handleHealthCheck)
 
+	m.registerAdminRoutes(mux)
+
+	listener, port, err := listenPort(ctx, m.port)
+	if err != nil {
+		return fmt.Errorf("server %s failed to listen on port %d: %w", m.serviceName, m.port, err)
+	}
+
+	m.port = port
+	if m.config.Port != port {
+		updated := *m.config
+		updated.Port = port
+		m.config = &updated
+	}
+
 	m.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", m.port),
-		Handler: mux,
+		Addr:      fmt.Sprintf(":%d", m.port),
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+		Protocols: httpProtocols(m.config.Protocols),
+	}
+
+	serve := func() error {
+		return m.server.Serve(listener)
+	}
+	if tlsConfig != nil {
+		serve = func() error {
+			return m.server.ServeTLS(listener, "", "")
+		}
 	}
 
 	// Channel to signal when server is ready
 	ready := make(chan error, 1)
 
 	go func() {
-		if err := m.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			m.mu.Lock()
 			m.healthStatus = HealthStatus{
 				Healthy:   false,
@@ -81,7 +151,7 @@ func (m *MockServerImpl) Start(ctx context.Context) error {
 	// Wait for server to actually start listening
 	go func() {
 		for i := 0; i < 50; i++ { // Try for up to 5 seconds
-			conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", m.port), 100*time.Millisecond)
+			conn, err := m.dial(100 * time.Millisecond)
 			if err == nil {
 				conn.Close()
 				ready <- nil
@@ -117,6 +187,18 @@ func (m *MockServerImpl) Start(ctx context.Context) error {
 	return nil
 }
 
+// listenPort binds port, or a free port picked by the system when it is 0, and
+// returns the port actually bound. Servers are handed the bound listener, so
+// another process cannot take an ephemeral port before they serve on it.
+func listenPort(ctx context.Context, port int) (net.Listener, int, error) {
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return listener, listener.Addr().(*net.TCPAddr).Port, nil
+}
+
 func (m *MockServerImpl) Stop() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -161,8 +243,23 @@ func (m *MockServerImpl) Reload(config *configReader.ServiceConfig) error {
 		return fmt.Errorf("cannot change port from %d to %d via reload, restart required", m.port, config.Port)
 	}
 
+	if !reflect.DeepEqual(config.TLS, m.config.TLS) {
+		return fmt.Errorf("cannot change tls settings via reload, restart required")
+	}
+
+	if !slices.Equal(config.Protocols, m.config.Protocols) {
+		return fmt.Errorf("cannot change protocols via reload, restart required")
+	}
+
+	validator, err := loadRequestValidator(config)
+	if err != nil {
+		return err
+	}
+
 	oldConfig := m.config
 	m.config = config
+	m.validator = validator
+	m.scenarios.Configure(config.Scenarios)
 
 	m.healthStatus = HealthStatus{
 		Healthy: true,
@@ -188,6 +285,10 @@ func (m *MockServerImpl) IsHealthy() bool {
 }
 
 func (m *MockServerImpl) GetURL() string {
+	if m.useTLS {
+		return fmt.Sprintf("https://localhost:%d", m.port)
+	}
+
 	return fmt.Sprintf("http://localhost:%d", m.port)
 }
 
@@ -221,14 +322,131 @@ func (m *MockServerImpl) GetHealthEndpoint() string {
 func (m *MockServerImpl) handleRequest(w http.ResponseWriter, r *http.Request) {
 	m.mu.RLock()
 	currentConfig := m.config
+	validator := m.validator
+	active := m.recording
 	m.mu.RUnlock()
 
-	err := m.responseHandler.HandleRequest(w, r, currentConfig)
+	entry := requestJournal.RecordedRequest{
+		Method:    r.Method,
+		Host:      r.Host,
+		Path:      r.URL.Path,
+		Query:     r.URL.RawQuery,
+		Protocol:  r.Proto,
+		Headers:   r.Header.Clone(),
+		Timestamp: time.Now(),
+	}
+
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		entry.ClientCert = r.TLS.PeerCertificates[0].Subject.String()
+	}
+
+	recorder := requestJournal.NewResponseRecorder(w)
+	defer func() {
+		if !recorder.Written() && r.Context().Err() != nil {
+			entry.Cancelled = true
+		} else {
+			entry.Response = recorder.Response(time.Since(entry.Timestamp))
+		}
+
+		m.journal.Record(entry)
+	}()
+
+	if clientAuth := currentConfig.TLS.GetClientAuth(); clientAuth != nil {
+		err := m.verifyClientCertificate(r)
+		if err != nil && !(clientAuth.Optional && errors.Is(err, errMissingClientCert)) {
+			entry.ClientCertError = err.Error()
+
+			if err := m.rejectClientCertificate(recorder, clientAuth, err); err != nil {
+				http.Error(recorder, "Internal Server Error", http.StatusInternalServerError)
+			}
+
+			return
+		}
+	}
+
+	body, err := requestMatcher.ReadBody(r)
+	if err == nil {
+		entry.Body = string(body)
+	}
+
+	violations, reject := m.validateRequest(r, body, currentConfig, validator)
+	for _, violation := range violations {
+		entry.Violations = append(entry.Violations, violation.String())
+	}
+
+	if reject {
+		if err := m.writeValidationError(recorder, currentConfig, violations); err != nil {
+			http.Error(recorder, "Internal Server Error", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	state := &handlers.RequestState{
+		Scenarios: m.scenarios,
+		Counters:  m.counters,
+		Handlers:  m.handlers,
+		InjectFault: func(w http.ResponseWriter, r *http.Request, route *configReader.Route, fault *configReader.FaultConfig, write func(http.ResponseWriter) error) error {
+			// HTTP/2 faults abort the handler, so the endpoint is recorded first.
+			entry.EndpointKey = route.Key
+			entry.EndpointID = route.ID()
+			entry.Fault = fault.Type
+			return m.injectFault(w, r, fault, write)
+		},
+	}
+
+	fallback := currentConfig.Fallback
+
+	switch {
+	case active != nil:
+		state.Unmatched = m.recordUnmatched(active)
+	case fallback != nil && fallback.Proxy != nil:
+		state.Unmatched = m.proxyUnmatched(fallback.Proxy)
+	}
+
+	route, err := m.responseHandler.HandleRequest(recorder, r, currentConfig, state)
+	if route != nil {
+		entry.EndpointKey = route.Key
+		entry.EndpointID = route.ID()
+	}
+
+	entry.Fallback = route == nil && active == nil && fallback != nil
+
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		http.Error(recorder, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
+// httpProtocols returns the protocols the server accepts, nil for the net/http
+// defaults: HTTP/1.1, plus HTTP/2 over TLS.
+func httpProtocols(protocols []string) *http.Protocols {
+	if len(protocols) == 0 {
+		return nil
+	}
+
+	accepted := &http.Protocols{}
+	for _, protocol := range protocols {
+		switch protocol {
+		case configReader.ProtocolHTTP1:
+			accepted.SetHTTP1(true)
+		case configReader.ProtocolHTTP2:
+			accepted.SetHTTP2(true)
+		case configReader.ProtocolH2C:
+			accepted.SetUnencryptedHTTP2(true)
+		}
+	}
+
+	return accepted
+}
+
+func (m *MockServerImpl) HandleFunc(method, path string, handler http.HandlerFunc) error {
+	return m.handlers.Register(method, path, handler)
+}
+
+func (m *MockServerImpl) RemoveHandler(method, path string) bool {
+	return m.handlers.Remove(method, path)
+}
+
 func (m *MockServerImpl) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
@@ -260,7 +478,7 @@ func (m *MockServerImpl) isServerListening() bool {
 		return false
 	}
 
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", m.port), 500*time.Millisecond)
+	conn, err := m.dial(500 * time.Millisecond)
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,173 @@
+package mock_server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	certAuthority "github.com/JTGlez/gockapi/internal/server/cert_authority"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRequestRecordsCancelledDelay(t *testing.T) {
+	cfg := &configReader.ServiceConfig{
+		ServiceName: "slowService",
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /slow": {{StatusCode: http.StatusOK, Delay: configReader.FixedDelay(time.Minute)}},
+			"GET /fast": {{StatusCode: http.StatusOK}},
+		},
+	}
+
+	server := NewHTTPMockServer("slowService", cfg, handlers.NewResponseHandler()).(*MockServerImpl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	server.handleRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+	server.handleRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	entries := server.GetRecordedRequests()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "GET /slow", entries[0].EndpointKey)
+	assert.True(t, entries[0].Cancelled)
+	assert.Nil(t, entries[0].Response)
+
+	assert.False(t, entries[1].Cancelled)
+	require.NotNil(t, entries[1].Response)
+	assert.Equal(t, http.StatusOK, entries[1].Response.StatusCode)
+}
+
+// protocolClient speaks only the given protocol, trusting ca over TLS.
+func protocolClient(ca certAuthority.CertificateAuthority, protocol string) *http.Client {
+	protocols := &http.Protocols{}
+	switch protocol {
+	case configReader.ProtocolHTTP1:
+		protocols.SetHTTP1(true)
+	case configReader.ProtocolHTTP2:
+		protocols.SetHTTP2(true)
+	case configReader.ProtocolH2C:
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: ca.CertPool()},
+			Protocols:         protocols,
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+func TestServeProtocols(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		tls       bool
+		protocols []string
+		accepted  map[string]string
+	}{
+		{name: "plain defaults", accepted: map[string]string{configReader.ProtocolHTTP1: "HTTP/1.1"}},
+		{name: "h2c only", protocols: []string{configReader.ProtocolH2C}, accepted: map[string]string{configReader.ProtocolH2C: "HTTP/2.0"}},
+		{
+			name:      "http1 and h2c",
+			protocols: []string{configReader.ProtocolHTTP1, configReader.ProtocolH2C},
+			accepted:  map[string]string{configReader.ProtocolHTTP1: "HTTP/1.1", configReader.ProtocolH2C: "HTTP/2.0"},
+		},
+		{
+			name:     "tls defaults",
+			tls:      true,
+			accepted: map[string]string{configReader.ProtocolHTTP1: "HTTP/1.1", configReader.ProtocolHTTP2: "HTTP/2.0"},
+		},
+		{name: "tls http2 only", tls: true, protocols: []string{configReader.ProtocolHTTP2}, accepted: map[string]string{configReader.ProtocolHTTP2: "HTTP/2.0"}},
+		{name: "tls http1 only", tls: true, protocols: []string{configReader.ProtocolHTTP1}, accepted: map[string]string{configReader.ProtocolHTTP1: "HTTP/1.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configReader.ServiceConfig{
+				ServiceName: "protocolService",
+				Protocols:   tt.protocols,
+				Endpoints: map[string]configReader.EndpointList{
+					"GET /proto": {{StatusCode: http.StatusOK}},
+				},
+			}
+			if tt.tls {
+				cfg.TLS = &configReader.TLSConfig{}
+			}
+
+			server := startServer(t, cfg, ca)
+
+			clients := []string{configReader.ProtocolHTTP1, configReader.ProtocolH2C}
+			if tt.tls {
+				clients = []string{configReader.ProtocolHTTP1, configReader.ProtocolHTTP2}
+			}
+
+			for _, protocol := range clients {
+				server.ResetRecordedRequests()
+
+				resp, err := protocolClient(ca, protocol).Get(server.GetURL() + "/proto")
+
+				expected, accepted := tt.accepted[protocol]
+				if !accepted {
+					assert.Error(t, err, "%s client must be refused", protocol)
+					continue
+				}
+
+				require.NoError(t, err, protocol)
+				_ = resp.Body.Close()
+				assert.Equal(t, expected, resp.Proto, protocol)
+
+				requests := server.GetRecordedRequests()
+				require.Len(t, requests, 1, protocol)
+				assert.Equal(t, expected, requests[0].Protocol, "the journal records the protocol")
+			}
+		})
+	}
+}
+
+func TestServeProtocolMatchers(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	cfg := &configReader.ServiceConfig{
+		ServiceName: "protocolService",
+		TLS:         &configReader.TLSConfig{},
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /proto": {
+				{Match: configReader.MatchConfig{Protocol: &configReader.ValueMatcher{EqualTo: "HTTP/2"}}, StatusCode: http.StatusOK, Body: "h2"},
+				{Match: configReader.MatchConfig{Protocol: &configReader.ValueMatcher{Matches: "^HTTP/1"}}, StatusCode: http.StatusOK, Body: "h1"},
+			},
+		},
+	}
+
+	server := startServer(t, cfg, ca)
+
+	for protocol, expected := range map[string]string{configReader.ProtocolHTTP1: "h1", configReader.ProtocolHTTP2: "h2"} {
+		_, body := fetchWith(t, protocolClient(ca, protocol), server.GetURL()+"/proto")
+		assert.Equal(t, expected, body, protocol)
+	}
+}
+
+func TestHTTPProtocols(t *testing.T) {
+	assert.Nil(t, httpProtocols(nil))
+
+	protocols := httpProtocols([]string{configReader.ProtocolHTTP1, configReader.ProtocolH2C})
+	require.NotNil(t, protocols)
+	assert.True(t, protocols.HTTP1())
+	assert.False(t, protocols.HTTP2())
+	assert.True(t, protocols.UnencryptedHTTP2())
+
+	protocols = httpProtocols([]string{configReader.ProtocolHTTP2})
+	assert.False(t, protocols.HTTP1())
+	assert.True(t, protocols.HTTP2())
+}
@@ -0,0 +1,84 @@
+package mock_server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+// forwardRequest proxies the request to target, joining the target path with
+// the request path, and answers with a 502 when the upstream cannot be
+// reached. rewrite, when set, adjusts each outgoing request.
+func (m *MockServerImpl) forwardRequest(w http.ResponseWriter, r *http.Request, target *url.URL, rewrite func(*http.Request)) error {
+	var forwardErr error
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(request *httputil.ProxyRequest) {
+			request.SetURL(target)
+
+			if rewrite != nil {
+				rewrite(request.Out)
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			forwardErr = err
+
+			m.responseHandler.WriteResponse(w, &configReader.EndpointConfig{
+				StatusCode: http.StatusBadGateway,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body: map[string]string{
+					"error":   "Bad Gateway",
+					"message": fmt.Sprintf("upstream %s is unavailable: %v", target.Host, err),
+				},
+			})
+		},
+	}
+
+	proxy.ServeHTTP(w, r)
+
+	return forwardErr
+}
+
+// proxyUnmatched forwards the requests no endpoint matches to the fallback
+// upstream, rewriting the request headers as configured.
+func (m *MockServerImpl) proxyUnmatched(proxy *configReader.ProxyConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target, err := parseUpstreamURL(proxy.URL)
+		if err != nil {
+			http.Error(w, "Bad Gateway", http.StatusBadGateway)
+			return
+		}
+
+		m.forwardRequest(w, r, target, func(out *http.Request) {
+			if proxy.PreserveHost {
+				out.Host = r.Host
+			}
+
+			for _, name := range proxy.RemoveHeaders {
+				out.Header.Del(name)
+			}
+
+			for name, value := range proxy.SetHeaders {
+				if strings.EqualFold(name, "Host") {
+					out.Host = value
+					continue
+				}
+
+				out.Header.Set(name, value)
+			}
+		})
+	}
+}
+
+func parseUpstreamURL(rawURL string) (*url.URL, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid upstream URL %q, expected http(s)://host[:port][/path]", rawURL)
+	}
+
+	return target, nil
+}
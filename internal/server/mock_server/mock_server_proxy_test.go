@@ -0,0 +1,153 @@
+package mock_server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoUpstream answers with the request it received.
+func echoUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+			"host":   r.Host,
+			"token":  r.Header.Get("Authorization"),
+			"env":    r.Header.Get("X-Env"),
+			"cookie": r.Header.Get("Cookie"),
+		})
+	}))
+	t.Cleanup(upstream.Close)
+
+	return upstream
+}
+
+func proxyTestConfig(proxy *configReader.ProxyConfig) *configReader.ServiceConfig {
+	return &configReader.ServiceConfig{
+		ServiceName: "proxyService",
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /users": {{StatusCode: http.StatusOK, Body: "mocked"}},
+		},
+		Fallback: &configReader.FallbackConfig{Proxy: proxy},
+	}
+}
+
+func TestProxyUnmatched(t *testing.T) {
+	upstream := echoUpstream(t)
+
+	server, httpServer := newAdminTestServer(t, proxyTestConfig(&configReader.ProxyConfig{
+		URL:           upstream.URL + "/api",
+		SetHeaders:    map[string]string{"X-Env": "mock"},
+		RemoveHeaders: []string{"Cookie"},
+	}))
+
+	status, body := adminRequest(t, http.MethodGet, httpServer.URL+"/users", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "mocked", body, "matched requests are answered by the mock")
+
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/orders/1?expand=items", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("Cookie", "session=1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var echoed map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&echoed))
+
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, map[string]string{
+		"path":   "/api/orders/1",
+		"query":  "expand=items",
+		"host":   upstream.Listener.Addr().String(),
+		"token":  "Bearer abc",
+		"env":    "mock",
+		"cookie": "",
+	}, echoed)
+
+	requests := server.GetRecordedRequests()
+	require.Len(t, requests, 2)
+	assert.False(t, requests[0].Fallback)
+	assert.Equal(t, "GET /users", requests[0].EndpointKey)
+	assert.True(t, requests[1].Fallback)
+	assert.Empty(t, requests[1].EndpointKey)
+	assert.Equal(t, http.StatusAccepted, requests[1].Response.StatusCode)
+}
+
+func TestProxyUnmatchedHost(t *testing.T) {
+	upstream := echoUpstream(t)
+
+	tests := []struct {
+		name     string
+		proxy    configReader.ProxyConfig
+		expected func(mockHost string) string
+	}{
+		{name: "upstream host", proxy: configReader.ProxyConfig{}, expected: func(string) string { return upstream.Listener.Addr().String() }},
+		{name: "preserved host", proxy: configReader.ProxyConfig{PreserveHost: true}, expected: func(mockHost string) string { return mockHost }},
+		{name: "set host", proxy: configReader.ProxyConfig{SetHeaders: map[string]string{"host": "api.example.com"}}, expected: func(string) string { return "api.example.com" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := tt.proxy
+			proxy.URL = upstream.URL
+
+			_, httpServer := newAdminTestServer(t, proxyTestConfig(&proxy))
+
+			status, body := adminRequest(t, http.MethodGet, httpServer.URL+"/orders", "")
+			require.Equal(t, http.StatusAccepted, status)
+
+			var echoed map[string]string
+			require.NoError(t, json.Unmarshal([]byte(body), &echoed))
+			assert.Equal(t, tt.expected(httpServer.Listener.Addr().String()), echoed["host"])
+		})
+	}
+}
+
+func TestProxyUnmatchedUnavailableUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL := upstream.URL
+	upstream.Close()
+
+	server, httpServer := newAdminTestServer(t, proxyTestConfig(&configReader.ProxyConfig{URL: upstreamURL}))
+
+	status, body := adminRequest(t, http.MethodGet, httpServer.URL+"/orders", "")
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Contains(t, body, `"error":"Bad Gateway"`)
+	assert.Contains(t, body, "is unavailable")
+
+	requests := server.GetRecordedRequests()
+	require.Len(t, requests, 1)
+	assert.True(t, requests[0].Fallback)
+}
+
+func TestParseUpstreamURL(t *testing.T) {
+	tests := []struct {
+		rawURL  string
+		wantErr bool
+	}{
+		{rawURL: "http://localhost:8080"},
+		{rawURL: "https://api.example.com/v1"},
+		{rawURL: "localhost:8080", wantErr: true},
+		{rawURL: "ws://localhost:8080", wantErr: true},
+		{rawURL: "http://", wantErr: true},
+		{rawURL: "http://[::1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := parseUpstreamURL(tt.rawURL)
+		assert.Equal(t, tt.wantErr, err != nil, tt.rawURL)
+	}
+}
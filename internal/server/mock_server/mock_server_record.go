@@ -0,0 +1,133 @@
+package mock_server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	requestMatcher "github.com/JTGlez/gockapi/internal/handlers/request_matcher"
+	"github.com/JTGlez/gockapi/internal/har"
+	requestJournal "github.com/JTGlez/gockapi/internal/server/request_journal"
+)
+
+type recording struct {
+	target   *url.URL
+	onRecord func(endpointKey string, endpoint configReader.EndpointConfig)
+}
+
+// StartRecording forwards the requests no endpoint matches to target and adds
+// every exchange to the service as an endpoint variant, so the next identical
+// request is answered by the mock. onRecord is called with each new variant.
+func (m *MockServerImpl) StartRecording(target string, onRecord func(endpointKey string, endpoint configReader.EndpointConfig)) error {
+	targetURL, err := parseUpstreamURL(target)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recording = &recording{target: targetURL, onRecord: onRecord}
+
+	return nil
+}
+
+func (m *MockServerImpl) StopRecording() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recording = nil
+}
+
+func (m *MockServerImpl) IsRecording() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.recording != nil
+}
+
+func (m *MockServerImpl) recordUnmatched(active *recording) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Decoded paths holding "?", "{" or "}", such as "/a%3Fb", would be read
+		// back from the endpoint key as a query or a path parameter.
+		recordable := !strings.ContainsAny(r.URL.Path, "?{}")
+
+		body, err := requestMatcher.ReadBody(r)
+		if err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		recorder := requestJournal.NewResponseRecorder(w)
+
+		// Without Accept-Encoding the transport negotiates compression itself
+		// and hands back plain bodies, which is what the config should hold.
+		err = m.forwardRequest(recorder, r, active.target, func(out *http.Request) {
+			out.Header.Del("Accept-Encoding")
+		})
+		if err != nil {
+			log.Printf("Recording %s: %s %s was not recorded: %v\n", m.serviceName, r.Method, r.URL.Path, err)
+			return
+		}
+
+		exchange := requestJournal.RecordedRequest{
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Query:    r.URL.RawQuery,
+			Headers:  r.Header,
+			Body:     string(body),
+			Response: recorder.Response(0),
+		}
+
+		if exchange.Response.Truncated {
+			log.Printf("Recording %s: %s %s was not recorded: the response is larger than %d bytes\n", m.serviceName, r.Method, r.URL.Path, requestJournal.MaxRecordedBodySize)
+			return
+		}
+
+		if !recordable {
+			log.Printf("Recording %s: %s %s was not recorded: its path cannot be written as an endpoint key\n", m.serviceName, r.Method, r.URL.EscapedPath())
+			return
+		}
+
+		endpointKey, endpoint := har.RecordedEndpoint(exchange)
+
+		added := false
+		err = m.updateEndpoints(func(endpoints map[string]configReader.EndpointList) error {
+			// Concurrent identical requests are all forwarded before the first
+			// one is recorded; only one of them adds the variant.
+			if slices.ContainsFunc(endpoints[endpointKey], func(existing configReader.EndpointConfig) bool {
+				return SameEndpoint(existing, endpoint)
+			}) {
+				return nil
+			}
+
+			endpoints[endpointKey] = append(append(configReader.EndpointList{}, endpoints[endpointKey]...), endpoint)
+			added = true
+
+			return nil
+		})
+		if err != nil {
+			log.Printf("Recording %s: %s %s was not recorded: %v\n", m.serviceName, r.Method, r.URL.Path, err)
+			return
+		}
+
+		if added && active.onRecord != nil {
+			active.onRecord(endpointKey, endpoint)
+		}
+	}
+}
+
+// SameEndpoint reports whether two endpoint variants have the same
+// configuration, comparing their JSON form so variants read back from a config
+// file compare equal to the ones they were written from.
+func SameEndpoint(a, b configReader.EndpointConfig) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
@@ -0,0 +1,66 @@
+package mock_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordUnmatched(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer upstream.Close()
+
+	cfg := &configReader.ServiceConfig{
+		ServiceName: "recorded",
+		Endpoints:   map[string]configReader.EndpointList{},
+	}
+	server := NewHTTPMockServer("recorded", cfg, handlers.NewResponseHandler()).(*MockServerImpl)
+
+	var mu sync.Mutex
+	var recorded []string
+	require.NoError(t, server.StartRecording(upstream.URL, func(endpointKey string, endpoint configReader.EndpointConfig) {
+		mu.Lock()
+		defer mu.Unlock()
+		recorded = append(recorded, endpointKey)
+	}))
+
+	handler := server.recordUnmatched(server.recording)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+		}()
+	}
+	wg.Wait()
+
+	for _, path := range []string{"/a%3Fb", "/x%7Bid%7D"} {
+		response := httptest.NewRecorder()
+		handler(response, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, response.Code, path)
+	}
+
+	assert.Equal(t, []string{"GET /items"}, recorded)
+	assert.Len(t, server.config.Endpoints, 1)
+	assert.Len(t, server.config.Endpoints["GET /items"], 1)
+}
+
+func TestSameEndpoint(t *testing.T) {
+	a := configReader.EndpointConfig{StatusCode: http.StatusOK, Body: map[string]any{"id": 1}}
+	b := configReader.EndpointConfig{StatusCode: http.StatusOK, Body: map[string]any{"id": float64(1)}}
+	c := configReader.EndpointConfig{StatusCode: http.StatusCreated, Body: map[string]any{"id": 1}}
+
+	assert.True(t, SameEndpoint(a, b))
+	assert.False(t, SameEndpoint(a, c))
+}
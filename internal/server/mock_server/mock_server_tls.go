@@ -0,0 +1,183 @@
+package mock_server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	certAuthority "github.com/JTGlez/gockapi/internal/server/cert_authority"
+)
+
+var errMissingClientCert = errors.New("client certificate required")
+
+// SetCertificateAuthority sets the CA that issues the server certificate when
+// the TLS config names no certificate files.
+func (m *MockServerImpl) SetCertificateAuthority(ca certAuthority.CertificateAuthority) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ca = ca
+}
+
+// CACertificate returns the PEM certificate clients should trust, or nil when
+// the server does not serve HTTPS.
+func (m *MockServerImpl) CACertificate() []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.caPEM
+}
+
+// loadTLSConfig returns the server TLS config and the PEM certificate clients
+// should trust.
+func (m *MockServerImpl) loadTLSConfig(cfg *configReader.TLSConfig) (*tls.Config, []byte, error) {
+	tlsConfig, caPEM, err := m.loadServerCertificate(cfg)
+	if err != nil || cfg.ClientAuth == nil {
+		return tlsConfig, caPEM, err
+	}
+
+	clientCAs, err := m.loadClientCAs(cfg.ClientAuth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.clientCAs = clientCAs
+
+	// Client certificates are verified per request, so that rejected clients
+	// get an HTTP error, unless the handshake itself must fail. Advertising no
+	// CA lets clients send certificates from any issuer.
+	tlsConfig.ClientAuth = tls.RequestClientCert
+	if cfg.ClientAuth.RejectHandshake {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if cfg.ClientAuth.Optional {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+	}
+
+	return tlsConfig, caPEM, nil
+}
+
+func (m *MockServerImpl) loadServerCertificate(cfg *configReader.TLSConfig) (*tls.Config, []byte, error) {
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load certificate for %s: %w", m.serviceName, err)
+		}
+
+		caFile := cfg.CAFile
+		if caFile == "" {
+			caFile = cfg.CertFile
+		}
+
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CA certificate for %s: %w", m.serviceName, err)
+		}
+
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, caPEM, nil
+	}
+
+	if m.ca == nil {
+		return nil, nil, fmt.Errorf("no certificate authority to issue a certificate for %s", m.serviceName)
+	}
+
+	cert, err := m.ca.IssueServerCertificate(cfg.Hosts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to issue certificate for %s: %w", m.serviceName, err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{*cert}}, m.ca.CertPEM(), nil
+}
+
+func (m *MockServerImpl) loadClientCAs(clientAuth *configReader.ClientAuthConfig) (*x509.CertPool, error) {
+	if clientAuth.CAFile == "" {
+		if m.ca == nil {
+			return nil, fmt.Errorf("no certificate authority to verify the client certificates of %s", m.serviceName)
+		}
+
+		return m.ca.CertPool(), nil
+	}
+
+	caPEM, err := os.ReadFile(clientAuth.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA certificate for %s: %w", m.serviceName, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in client CA file %s", clientAuth.CAFile)
+	}
+
+	return pool, nil
+}
+
+// verifyClientCertificate checks the client certificate of the request
+// against the client CAs. It returns errMissingClientCert when the client sent
+// none.
+func (m *MockServerImpl) verifyClientCertificate(r *http.Request) error {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return errMissingClientCert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range r.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := r.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         m.clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	return err
+}
+
+// rejectClientCertificate answers a request whose client certificate is
+// missing or invalid with the configured error response.
+func (m *MockServerImpl) rejectClientCertificate(w http.ResponseWriter, clientAuth *configReader.ClientAuthConfig, err error) error {
+	response := &configReader.ErrorResponseConfig{
+		StatusCode: http.StatusForbidden,
+		Body:       map[string]string{"error": "Forbidden", "message": err.Error()},
+	}
+
+	if clientAuth.InvalidResponse != nil {
+		response = clientAuth.InvalidResponse
+	}
+
+	if errors.Is(err, errMissingClientCert) {
+		response = &configReader.ErrorResponseConfig{
+			StatusCode: http.StatusUnauthorized,
+			Body:       map[string]string{"error": "Unauthorized", "message": err.Error()},
+		}
+
+		if clientAuth.MissingResponse != nil {
+			response = clientAuth.MissingResponse
+		}
+	}
+
+	return m.responseHandler.WriteResponse(w, &configReader.EndpointConfig{
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Body:       response.Body,
+	})
+}
+
+// dial connects to the server, completing the handshake when it serves HTTPS
+// so probes are not logged as failed handshakes.
+func (m *MockServerImpl) dial(timeout time.Duration) (net.Conn, error) {
+	address := fmt.Sprintf("localhost:%d", m.port)
+	if !m.useTLS {
+		return net.DialTimeout("tcp", address, timeout)
+	}
+
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+}
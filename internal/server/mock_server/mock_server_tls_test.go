@@ -0,0 +1,311 @@
+package mock_server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	handlers "github.com/JTGlez/gockapi/internal/handlers/response_handler"
+	certAuthority "github.com/JTGlez/gockapi/internal/server/cert_authority"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTLSServer starts the service on a free port, with certificates issued
+// by ca when the TLS config names no certificate files.
+func startTLSServer(t *testing.T, tlsConfig *configReader.TLSConfig, ca certAuthority.CertificateAuthority) *MockServerImpl {
+	t.Helper()
+
+	return startServer(t, &configReader.ServiceConfig{
+		ServiceName: "secureService",
+		TLS:         tlsConfig,
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /users": {{StatusCode: http.StatusOK, Body: "users"}},
+		},
+	}, ca)
+}
+
+func startServer(t *testing.T, cfg *configReader.ServiceConfig, ca certAuthority.CertificateAuthority) *MockServerImpl {
+	t.Helper()
+
+	server := NewHTTPMockServer(cfg.ServiceName, cfg, handlers.NewResponseHandler()).(*MockServerImpl)
+	server.SetCertificateAuthority(ca)
+	require.NoError(t, server.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+
+	return server
+}
+
+func tlsClient(roots *x509.CertPool, certs ...tls.Certificate) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs},
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+func TestServeTLS(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	server := startTLSServer(t, &configReader.TLSConfig{Hosts: []string{"payments.local"}}, ca)
+
+	assert.True(t, strings.HasPrefix(server.GetURL(), "https://localhost:"), server.GetURL())
+	assert.Equal(t, ca.CertPEM(), server.CACertificate())
+	assert.True(t, server.IsHealthy())
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(server.CACertificate()))
+
+	resp, err := tlsClient(roots).Get(server.GetURL() + "/users")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "users", string(body))
+	assert.Equal(t, "HTTP/2.0", resp.Proto, "HTTPS services negotiate HTTP/2")
+	assert.Equal(t, []string{"localhost", "payments.local"}, resp.TLS.PeerCertificates[0].DNSNames)
+
+	_, err = tlsClient(x509.NewCertPool()).Get(server.GetURL() + "/users")
+	var unknownAuthority x509.UnknownAuthorityError
+	assert.ErrorAs(t, err, &unknownAuthority)
+}
+
+func TestServeTLSCertificateFiles(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("file CA")
+	require.NoError(t, err)
+
+	cert, err := ca.IssueServerCertificate(nil)
+	require.NoError(t, err)
+
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	caFile := filepath.Join(dir, "ca.pem")
+
+	var chain []byte
+	for _, der := range cert.Certificate {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	require.NoError(t, os.WriteFile(certFile, chain, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600))
+	require.NoError(t, os.WriteFile(caFile, ca.CertPEM(), 0o600))
+
+	server := startTLSServer(t, &configReader.TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}, nil)
+	assert.Equal(t, ca.CertPEM(), server.CACertificate())
+
+	resp, err := tlsClient(ca.CertPool()).Get(server.GetURL() + "/users")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServeTLSErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		tlsConfig *configReader.TLSConfig
+		wantErr   string
+	}{
+		{name: "no CA", tlsConfig: &configReader.TLSConfig{}, wantErr: "no certificate authority"},
+		{name: "missing files", tlsConfig: &configReader.TLSConfig{CertFile: "missing.pem", KeyFile: "missing.key"}, wantErr: "failed to load certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configReader.ServiceConfig{
+				ServiceName: "secureService",
+				TLS:         tt.tlsConfig,
+				Endpoints:   map[string]configReader.EndpointList{"GET /": {{StatusCode: http.StatusOK}}},
+			}
+
+			server := NewHTTPMockServer(cfg.ServiceName, cfg, handlers.NewResponseHandler())
+			err := server.Start(context.Background())
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func mtlsTestConfig(clientAuth *configReader.ClientAuthConfig) *configReader.ServiceConfig {
+	return &configReader.ServiceConfig{
+		ServiceName: "partnerGateway",
+		TLS:         &configReader.TLSConfig{ClientAuth: clientAuth},
+		Endpoints: map[string]configReader.EndpointList{
+			"GET /partner": {
+				{Match: configReader.MatchConfig{ClientCert: map[string]configReader.ValueMatcher{"common_name": {EqualTo: "acme"}}}, StatusCode: http.StatusCreated, Body: "acme"},
+				{Match: configReader.MatchConfig{ClientCert: map[string]configReader.ValueMatcher{"san": {Matches: "^spiffe://corp/"}}}, StatusCode: http.StatusAccepted, Body: "corp"},
+				{StatusCode: http.StatusOK, Body: "unknown"},
+			},
+		},
+	}
+}
+
+func issueClientCert(t *testing.T, ca certAuthority.CertificateAuthority, commonName string, sans ...string) tls.Certificate {
+	t.Helper()
+
+	cert, err := ca.IssueClientCertificate(commonName, sans)
+	require.NoError(t, err)
+
+	return *cert
+}
+
+func fetchWith(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	untrusted, err := certAuthority.NewCertificateAuthority("untrusted CA")
+	require.NoError(t, err)
+
+	server := startServer(t, mtlsTestConfig(&configReader.ClientAuthConfig{}), ca)
+	url := server.GetURL() + "/partner"
+
+	tests := []struct {
+		name      string
+		certs     []tls.Certificate
+		status    int
+		body      string
+		certError string
+	}{
+		{name: "no certificate", status: http.StatusUnauthorized, body: "client certificate required", certError: "client certificate required"},
+		{name: "untrusted CA", certs: []tls.Certificate{issueClientCert(t, untrusted, "acme")}, status: http.StatusForbidden, body: "unknown authority", certError: "unknown authority"},
+		{name: "common name matches", certs: []tls.Certificate{issueClientCert(t, ca, "acme")}, status: http.StatusCreated, body: "acme"},
+		{name: "SAN matches", certs: []tls.Certificate{issueClientCert(t, ca, "billing", "spiffe://corp/billing")}, status: http.StatusAccepted, body: "corp"},
+		{name: "no matcher matches", certs: []tls.Certificate{issueClientCert(t, ca, "globex", "spiffe://other/globex")}, status: http.StatusOK, body: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.ResetRecordedRequests()
+
+			status, body := fetchWith(t, tlsClient(ca.CertPool(), tt.certs...), url)
+			assert.Equal(t, tt.status, status)
+			assert.Contains(t, body, tt.body)
+
+			requests := server.GetRecordedRequests()
+			require.Len(t, requests, 1)
+			assert.Contains(t, requests[0].ClientCertError, tt.certError)
+			if tt.certError != "" {
+				assert.Empty(t, requests[0].EndpointKey)
+			}
+			if len(tt.certs) > 0 {
+				assert.Equal(t, tt.certs[0].Leaf.Subject.String(), requests[0].ClientCert)
+			}
+		})
+	}
+}
+
+func TestMutualTLSExemptsAdminRoutes(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	server := startServer(t, mtlsTestConfig(&configReader.ClientAuthConfig{}), ca)
+	client := tlsClient(ca.CertPool())
+
+	status, _ := fetchWith(t, client, server.GetURL()+"/partner")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	for _, path := range []string{"/_health", "/_admin/config", "/_admin/requests"} {
+		status, _ := fetchWith(t, client, server.GetURL()+path)
+		assert.Equal(t, http.StatusOK, status, path)
+	}
+}
+
+func TestMutualTLSResponses(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	untrusted, err := certAuthority.NewCertificateAuthority("untrusted CA")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		clientAuth configReader.ClientAuthConfig
+		certs      []tls.Certificate
+		status     int
+		body       string
+	}{
+		{
+			name:       "custom missing response",
+			clientAuth: configReader.ClientAuthConfig{MissingResponse: &configReader.ErrorResponseConfig{StatusCode: http.StatusTeapot, Body: "certificate_required"}},
+			status:     http.StatusTeapot,
+			body:       "certificate_required",
+		},
+		{
+			name:       "custom invalid response",
+			clientAuth: configReader.ClientAuthConfig{InvalidResponse: &configReader.ErrorResponseConfig{StatusCode: http.StatusNotAcceptable, Body: "certificate_rejected"}},
+			certs:      []tls.Certificate{issueClientCert(t, untrusted, "acme")},
+			status:     http.StatusNotAcceptable,
+			body:       "certificate_rejected",
+		},
+		{name: "optional without certificate", clientAuth: configReader.ClientAuthConfig{Optional: true}, status: http.StatusOK, body: "unknown"},
+		{
+			name:       "optional verifies sent certificates",
+			clientAuth: configReader.ClientAuthConfig{Optional: true},
+			certs:      []tls.Certificate{issueClientCert(t, untrusted, "acme")},
+			status:     http.StatusForbidden,
+			body:       "unknown authority",
+		},
+		{
+			name:       "handshake with trusted certificate",
+			clientAuth: configReader.ClientAuthConfig{RejectHandshake: true},
+			certs:      []tls.Certificate{issueClientCert(t, ca, "acme")},
+			status:     http.StatusCreated,
+			body:       "acme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientAuth := tt.clientAuth
+			server := startServer(t, mtlsTestConfig(&clientAuth), ca)
+
+			status, body := fetchWith(t, tlsClient(ca.CertPool(), tt.certs...), server.GetURL()+"/partner")
+			assert.Equal(t, tt.status, status)
+			assert.Contains(t, body, tt.body)
+		})
+	}
+}
+
+func TestMutualTLSRejectHandshake(t *testing.T) {
+	ca, err := certAuthority.NewCertificateAuthority("gockapi test CA")
+	require.NoError(t, err)
+
+	untrusted, err := certAuthority.NewCertificateAuthority("untrusted CA")
+	require.NoError(t, err)
+
+	server := startServer(t, mtlsTestConfig(&configReader.ClientAuthConfig{RejectHandshake: true}), ca)
+
+	for name, certs := range map[string][]tls.Certificate{
+		"no certificate": nil,
+		"untrusted CA":   {issueClientCert(t, untrusted, "acme")},
+	} {
+		_, err := tlsClient(ca.CertPool(), certs...).Get(server.GetURL() + "/_health")
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,61 @@
+package mock_server
+
+import (
+	"fmt"
+	"net/http"
+
+	configReader "github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/JTGlez/gockapi/internal/openapi"
+)
+
+const defaultValidationStatus = http.StatusBadRequest
+
+func loadRequestValidator(cfg *configReader.ServiceConfig) (*openapi.RequestValidator, error) {
+	if cfg.RequestValidation == nil {
+		return nil, nil
+	}
+
+	document, err := openapi.Load(cfg.RequestValidation.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load request validation spec for %s: %w", cfg.ServiceName, err)
+	}
+
+	validator, err := openapi.NewRequestValidator(document, cfg.RequestValidation.BasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load request validation spec for %s: %w", cfg.ServiceName, err)
+	}
+
+	return validator, nil
+}
+
+// validateRequest checks the request against the service's OpenAPI document.
+// It returns the violations and whether the request must be rejected.
+func (m *MockServerImpl) validateRequest(r *http.Request, body []byte, cfg *configReader.ServiceConfig, validator *openapi.RequestValidator) ([]openapi.Violation, bool) {
+	if validator == nil || cfg.RequestValidation == nil {
+		return nil, false
+	}
+
+	violations := validator.Validate(r, body)
+	if len(violations) == 0 {
+		return nil, false
+	}
+
+	return violations, cfg.RequestValidation.Mode != configReader.ValidationReport
+}
+
+func (m *MockServerImpl) writeValidationError(w http.ResponseWriter, cfg *configReader.ServiceConfig, violations []openapi.Violation) error {
+	statusCode := cfg.RequestValidation.StatusCode
+	if statusCode == 0 {
+		statusCode = defaultValidationStatus
+	}
+
+	return m.responseHandler.WriteResponse(w, &configReader.EndpointConfig{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body: map[string]any{
+			"error":      "Request validation failed",
+			"message":    fmt.Sprintf("request violates the API contract in %d place(s)", len(violations)),
+			"violations": violations,
+		},
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 type PortManager interface {
 	AllocatePort(serviceName string, preferredPort int) (int, error)
+	ReservePort(serviceName string, port int) error
 	ReleasePort(serviceName string) error
 	IsPortAvailable(port int) bool
 	GetAllocatedPort(serviceName string) (int, bool)
@@ -32,8 +33,8 @@ func (p *PortManagerImpl) AllocatePort(serviceName string, preferredPort int) (i
 	defer p.mu.Unlock()
 
 	// Validar puerto
-	if preferredPort <= 0 {
-		return 0, fmt.Errorf("invalid port %d: must be positive", preferredPort)
+	if preferredPort < 0 {
+		return 0, fmt.Errorf("invalid port %d: cannot be negative", preferredPort)
 	}
 
 	// Si el servicio ya tiene un puerto asignado, devolverlo
@@ -41,6 +42,11 @@ func (p *PortManagerImpl) AllocatePort(serviceName string, preferredPort int) (i
 		return existingPort, nil
 	}
 
+	// Puerto 0: el servidor enlaza un puerto libre y lo registra con ReservePort
+	if preferredPort == 0 {
+		return 0, fmt.Errorf("port 0 for service %s must be bound by its server and reserved with ReservePort", serviceName)
+	}
+
 	// Si el puerto preferido está disponible, usarlo
 	if p.isPortAvailableInternal(preferredPort) {
 		p.allocatedPorts[serviceName] = preferredPort
@@ -52,6 +58,30 @@ func (p *PortManagerImpl) AllocatePort(serviceName string, preferredPort int) (i
 	return 0, fmt.Errorf("preferred port %d is not available for service %s", preferredPort, serviceName)
 }
 
+// ReservePort records a port the service already bound, such as one the
+// system picked for port 0, so it is not handed out to another service.
+func (p *PortManagerImpl) ReservePort(serviceName string, port int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if port <= 0 {
+		return fmt.Errorf("invalid port %d for service %s", port, serviceName)
+	}
+
+	if existingPort, exists := p.allocatedPorts[serviceName]; exists && existingPort != port {
+		return fmt.Errorf("service %s already has port %d", serviceName, existingPort)
+	}
+
+	if p.reservedPorts[port] && p.allocatedPorts[serviceName] != port {
+		return fmt.Errorf("port %d is already reserved", port)
+	}
+
+	p.allocatedPorts[serviceName] = port
+	p.reservedPorts[port] = true
+
+	return nil
+}
+
 func (p *PortManagerImpl) ReleasePort(serviceName string) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
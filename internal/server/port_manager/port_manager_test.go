@@ -0,0 +1,72 @@
+package port_manager
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocatePort(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	pm := NewPortManager()
+
+	allocated, err := pm.AllocatePort("users", port)
+	require.NoError(t, err)
+	assert.Equal(t, port, allocated)
+
+	again, err := pm.AllocatePort("users", port+1)
+	require.NoError(t, err)
+	assert.Equal(t, port, again, "a service keeps its port")
+
+	_, err = pm.AllocatePort("orders", port)
+	assert.Error(t, err, "a reserved port is not handed out twice")
+	assert.False(t, pm.IsPortAvailable(port))
+
+	_, err = pm.AllocatePort("orders", -1)
+	assert.Error(t, err)
+
+	_, err = pm.AllocatePort("orders", 0)
+	assert.Error(t, err, "port 0 is bound by the server")
+
+	require.NoError(t, pm.ReleasePort("users"))
+	assert.Error(t, pm.ReleasePort("users"))
+	assert.Empty(t, pm.GetAllocatedPorts())
+}
+
+func TestAllocatePortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	_, err = NewPortManager().AllocatePort("users", listener.Addr().(*net.TCPAddr).Port)
+	assert.Error(t, err)
+}
+
+func TestReservePort(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	pm := NewPortManager()
+
+	require.NoError(t, pm.ReservePort("users", port), "the port is reserved while its listener is open")
+	require.NoError(t, pm.ReservePort("users", port))
+
+	allocated, exists := pm.GetAllocatedPort("users")
+	assert.True(t, exists)
+	assert.Equal(t, port, allocated)
+
+	assert.Error(t, pm.ReservePort("orders", port))
+	assert.Error(t, pm.ReservePort("users", port+1))
+	assert.Error(t, pm.ReservePort("orders", 0))
+
+	_, err = pm.AllocatePort("orders", port)
+	assert.Error(t, err)
+}
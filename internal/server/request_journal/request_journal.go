@@ -0,0 +1,59 @@
+package request_journal
+
+import (
+	"net/http"
+	"time"
+)
+
+type RequestJournal interface {
+	Record(entry RecordedRequest)
+	Entries() []RecordedRequest
+	Count(endpointKey string) int
+	Clear()
+}
+
+// RecordedRequest is a request received by a mock server. Protocol is the
+// negotiated protocol, such as "HTTP/1.1" or "HTTP/2.0". EndpointKey is empty
+// when no endpoint matched; EndpointID names the variant that did, such as
+// "POST /charge#1", and equals EndpointKey for gRPC methods. Fallback tells
+// whether the service's fallback answered it. Violations lists how the request broke the service's OpenAPI
+// contract when request validation is enabled. Fault names the fault injected
+// into the response; Response is nil when the fault took the connection over.
+// ClientCert is the subject of the client certificate, and ClientCertError why
+// it was rejected. Cancelled tells that the client went away before a response
+// was sent, such as during the delay; such requests have no Response.
+type RecordedRequest struct {
+	Method          string            `json:"method"`
+	Host            string            `json:"host,omitempty"`
+	Path            string            `json:"path"`
+	Query           string            `json:"query,omitempty"`
+	Protocol        string            `json:"protocol,omitempty"`
+	Headers         http.Header       `json:"headers"`
+	Body            string            `json:"body,omitempty"`
+	EndpointKey     string            `json:"endpoint_key,omitempty"`
+	EndpointID      string            `json:"endpoint_id,omitempty"`
+	Fallback        bool              `json:"fallback,omitempty"`
+	Violations      []string          `json:"violations,omitempty"`
+	Fault           string            `json:"fault,omitempty"`
+	ClientCert      string            `json:"client_cert,omitempty"`
+	ClientCertError string            `json:"client_cert_error,omitempty"`
+	Cancelled       bool              `json:"cancelled,omitempty"`
+	Response        *RecordedResponse `json:"response,omitempty"`
+	Timestamp       time.Time         `json:"timestamp"`
+}
+
+// RecordedResponse is the response sent for a recorded request. Body holds at
+// most the first MaxRecordedBodySize bytes.
+type RecordedResponse struct {
+	StatusCode int           `json:"status_code"`
+	Headers    http.Header   `json:"headers"`
+	Body       string        `json:"body,omitempty"`
+	Truncated  bool          `json:"truncated,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+const MaxRecordedBodySize = 1 << 20
+
+func (r RecordedRequest) Matched() bool {
+	return r.EndpointKey != ""
+}
@@ -0,0 +1,60 @@
+package request_journal
+
+import "sync"
+
+type RequestJournalImpl struct {
+	mu         sync.RWMutex
+	entries    []RecordedRequest
+	maxEntries int
+}
+
+// NewRequestJournal creates a journal that keeps the most recent maxEntries
+// requests. A non-positive maxEntries keeps every request.
+func NewRequestJournal(maxEntries int) RequestJournal {
+	return &RequestJournalImpl{
+		entries:    []RecordedRequest{},
+		maxEntries: maxEntries,
+	}
+}
+
+func (j *RequestJournalImpl) Record(entry RecordedRequest) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = append(j.entries, entry)
+
+	if j.maxEntries > 0 && len(j.entries) > j.maxEntries {
+		j.entries = j.entries[len(j.entries)-j.maxEntries:]
+	}
+}
+
+func (j *RequestJournalImpl) Entries() []RecordedRequest {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	result := make([]RecordedRequest, len(j.entries))
+	copy(result, j.entries)
+
+	return result
+}
+
+func (j *RequestJournalImpl) Count(endpointKey string) int {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	count := 0
+	for _, entry := range j.entries {
+		if entry.EndpointKey == endpointKey {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (j *RequestJournalImpl) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = []RecordedRequest{}
+}
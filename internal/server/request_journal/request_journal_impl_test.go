@@ -0,0 +1,45 @@
+package request_journal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestJournal(t *testing.T) {
+	journal := NewRequestJournal(0)
+
+	journal.Record(RecordedRequest{Method: "GET", Path: "/users", EndpointKey: "GET /users"})
+	journal.Record(RecordedRequest{Method: "GET", Path: "/missing"})
+	journal.Record(RecordedRequest{Method: "GET", Path: "/users", EndpointKey: "GET /users"})
+
+	entries := journal.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "/missing", entries[1].Path)
+	assert.False(t, entries[1].Matched())
+	assert.True(t, entries[0].Matched())
+
+	assert.Equal(t, 2, journal.Count("GET /users"))
+	assert.Equal(t, 1, journal.Count(""), "unmatched requests are counted under the empty key")
+	assert.Equal(t, 0, journal.Count("POST /users"))
+
+	entries[0].Path = "/changed"
+	assert.Equal(t, "/users", journal.Entries()[0].Path, "Entries returns a copy")
+
+	journal.Clear()
+	assert.Empty(t, journal.Entries())
+	assert.Equal(t, 0, journal.Count("GET /users"))
+}
+
+func TestRequestJournalKeepsMostRecent(t *testing.T) {
+	journal := NewRequestJournal(2)
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		journal.Record(RecordedRequest{Method: "GET", Path: path})
+	}
+
+	entries := journal.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "/b", entries[0].Path)
+	assert.Equal(t, "/c", entries[1].Path)
+}
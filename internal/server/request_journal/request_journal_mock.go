@@ -0,0 +1,25 @@
+package request_journal
+
+import "github.com/stretchr/testify/mock"
+
+type MockRequestJournal struct {
+	mock.Mock
+}
+
+func (m *MockRequestJournal) Record(entry RecordedRequest) {
+	m.Called(entry)
+}
+
+func (m *MockRequestJournal) Entries() []RecordedRequest {
+	args := m.Called()
+	return args.Get(0).([]RecordedRequest)
+}
+
+func (m *MockRequestJournal) Count(endpointKey string) int {
+	args := m.Called(endpointKey)
+	return args.Int(0)
+}
+
+func (m *MockRequestJournal) Clear() {
+	m.Called()
+}
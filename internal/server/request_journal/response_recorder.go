@@ -0,0 +1,93 @@
+package request_journal
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseRecorder wraps a ResponseWriter and keeps a copy of the response for
+// the journal. Unwrap exposes the original writer to http.ResponseController.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	headers    http.Header
+	body       bytes.Buffer
+	truncated  bool
+	hijacked   bool
+}
+
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w}
+}
+
+func (r *ResponseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+		r.headers = r.Header().Clone()
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *ResponseRecorder) Write(data []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	remaining := MaxRecordedBodySize - r.body.Len()
+	if len(data) > remaining {
+		r.truncated = true
+	}
+
+	r.body.Write(data[:min(len(data), max(remaining, 0))])
+
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *ResponseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Written reports whether a response was started or the connection taken over.
+func (r *ResponseRecorder) Written() bool {
+	return r.statusCode != 0 || r.hijacked
+}
+
+// Response returns the response written so far. A handler that wrote nothing
+// produced an empty 200 response, and a hijacked connection has no response.
+func (r *ResponseRecorder) Response(duration time.Duration) *RecordedResponse {
+	if r.hijacked {
+		return nil
+	}
+
+	statusCode, headers := r.statusCode, r.headers
+	if statusCode == 0 {
+		statusCode, headers = http.StatusOK, r.Header().Clone()
+	}
+
+	return &RecordedResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       r.body.String(),
+		Truncated:  r.truncated,
+		Duration:   duration,
+	}
+}
@@ -0,0 +1,87 @@
+package request_journal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	target := httptest.NewRecorder()
+	recorder := NewResponseRecorder(target)
+
+	assert.False(t, recorder.Written())
+
+	recorder.Header().Set("Content-Type", "application/json")
+	recorder.WriteHeader(http.StatusCreated)
+	recorder.Header().Set("X-Late", "ignored")
+	recorder.WriteHeader(http.StatusInternalServerError)
+	_, err := recorder.Write([]byte(`{"id": 1}`))
+	require.NoError(t, err)
+
+	assert.True(t, recorder.Written())
+
+	response := recorder.Response(time.Second)
+	require.NotNil(t, response)
+	assert.Equal(t, http.StatusCreated, response.StatusCode, "only the first status is kept")
+	assert.Equal(t, "application/json", response.Headers.Get("Content-Type"))
+	assert.Empty(t, response.Headers.Get("X-Late"), "headers are captured with the status")
+	assert.Equal(t, `{"id": 1}`, response.Body)
+	assert.Equal(t, time.Second, response.Duration)
+	assert.False(t, response.Truncated)
+
+	assert.Equal(t, `{"id": 1}`, target.Body.String(), "the response still reaches the client")
+}
+
+func TestResponseRecorderDefaultsToOK(t *testing.T) {
+	recorder := NewResponseRecorder(httptest.NewRecorder())
+
+	response := recorder.Response(0)
+	require.NotNil(t, response)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, response.Body)
+
+	_, err := recorder.Write([]byte("text"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Response(0).StatusCode)
+}
+
+func TestResponseRecorderTruncatesLargeBodies(t *testing.T) {
+	target := httptest.NewRecorder()
+	recorder := NewResponseRecorder(target)
+
+	chunk := strings.Repeat("x", MaxRecordedBodySize/2+1)
+	for range 3 {
+		_, err := recorder.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+
+	response := recorder.Response(0)
+	assert.True(t, response.Truncated)
+	assert.Len(t, response.Body, MaxRecordedBodySize)
+	assert.Equal(t, 3*len(chunk), target.Body.Len())
+}
+
+func TestResponseRecorderHijack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := NewResponseRecorder(w)
+
+		conn, _, err := recorder.Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.True(t, recorder.Written())
+		assert.Nil(t, recorder.Response(0))
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,442 @@
+package gockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JTGlez/gockapi/internal/config_reader"
+)
+
+// ServiceBuilder describes a mock service in code instead of a JSON file.
+//
+//	payments := gockapi.NewService("payments").
+//	    On("POST", "/charge").
+//	    WithHeader("Authorization", "Bearer test").
+//	    Reply(201).
+//	    JSON(map[string]any{"id": "ch_1", "status": "succeeded"}).
+//	    On("POST", "/charge").
+//	    Reply(401).
+//	    Service()
+//
+//	err := mgr.Serve(ctx, payments)
+//
+// Stubs registered for the same method and path become variants of one
+// endpoint, tried in the usual matching order.
+type ServiceBuilder struct {
+	name      string
+	port      int
+	stubs     []*StubBuilder
+	scenarios map[string]ScenarioConfig
+	fallback  *FallbackConfig
+	fault     *FaultConfig
+	delay     *DelayConfig
+	tls       *TLSConfig
+	protocols []string
+	grpc      *GRPCConfig
+}
+
+// StubBuilder describes the requests an endpoint matches. Call Reply to
+// describe its response.
+type StubBuilder struct {
+	service     *ServiceBuilder
+	method      string
+	path        string
+	endpoint    EndpointConfig
+	expectation *expectation
+	err         error
+}
+
+// ResponseBuilder describes the response of a stub. On continues with the next
+// stub of the service and Service returns the service, so a whole service can
+// be described in one expression.
+type ResponseBuilder struct {
+	stub *StubBuilder
+}
+
+// NewService starts describing a mock service. It listens on a free port
+// picked by the system unless Port is called; use Manager.URL to reach it.
+func NewService(name string) *ServiceBuilder {
+	return &ServiceBuilder{name: name}
+}
+
+// Port sets the port the service listens on.
+func (s *ServiceBuilder) Port(port int) *ServiceBuilder {
+	s.port = port
+	return s
+}
+
+// Scenario declares a stateful scenario for the service's stubs. When no
+// states are listed any state name is accepted.
+func (s *ServiceBuilder) Scenario(name, initialState string, states ...string) *ServiceBuilder {
+	if s.scenarios == nil {
+		s.scenarios = make(map[string]ScenarioConfig)
+	}
+
+	s.scenarios[name] = ScenarioConfig{InitialState: initialState, States: states}
+
+	return s
+}
+
+// Fallback answers the requests no stub matches instead of the default 404,
+// e.g. by forwarding them to a local stand-in of the real service:
+//
+//	svc.Fallback(gockapi.FallbackConfig{Proxy: &gockapi.ProxyConfig{URL: "http://localhost:9000"}})
+func (s *ServiceBuilder) Fallback(fallback FallbackConfig) *ServiceBuilder {
+	s.fallback = &fallback
+	return s
+}
+
+// Fault injects the fault into the responses of every stub that does not set
+// its own, e.g. to reset a third of the connections:
+//
+//	svc.Fault(gockapi.FaultConfig{Type: gockapi.FaultConnectionReset, Probability: 0.3})
+func (s *ServiceBuilder) Fault(fault FaultConfig) *ServiceBuilder {
+	s.fault = &fault
+	return s
+}
+
+// Delay delays the responses of every stub that does not set its own delay.
+func (s *ServiceBuilder) Delay(delay DelayConfig) *ServiceBuilder {
+	s.delay = &delay
+	return s
+}
+
+// TLS serves the service over HTTPS. An empty TLSConfig uses a certificate
+// issued by the CA of the Manager; reach the service with Manager.HTTPClient.
+func (s *ServiceBuilder) TLS(tlsConfig TLSConfig) *ServiceBuilder {
+	s.tls = &tlsConfig
+	return s
+}
+
+// Protocols sets the protocols the service accepts, e.g. HTTP/2 over
+// cleartext only, reachable with Manager.HTTP2Client:
+//
+//	svc.Protocols(gockapi.ProtocolH2C)
+func (s *ServiceBuilder) Protocols(protocols ...string) *ServiceBuilder {
+	s.protocols = protocols
+	return s
+}
+
+// GRPC serves the service over gRPC instead of HTTP; URL then returns the
+// address to dial:
+//
+//	svc := gockapi.NewService("payments").GRPC(gockapi.GRPCConfig{
+//		DescriptorSet: "testdata/payments.pb",
+//		Methods: map[string][]gockapi.GRPCResponseConfig{
+//			"payments.v1.Payments/Charge": {{Response: map[string]any{"id": "ch_1"}}},
+//		},
+//	})
+//	conn, err := grpc.NewClient(mgr.URL("payments"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+func (s *ServiceBuilder) GRPC(grpcConfig GRPCConfig) *ServiceBuilder {
+	s.grpc = &grpcConfig
+	return s
+}
+
+// On adds a stub for the given method and path. The path may contain
+// parameters such as "/users/{id}".
+func (s *ServiceBuilder) On(method, path string) *StubBuilder {
+	stub := &StubBuilder{
+		service:  s,
+		method:   strings.ToUpper(method),
+		path:     path,
+		endpoint: EndpointConfig{StatusCode: http.StatusOK},
+	}
+
+	s.stubs = append(s.stubs, stub)
+
+	return stub
+}
+
+// Build returns the service configuration described so far.
+func (s *ServiceBuilder) Build() (*ServiceConfig, error) {
+	cfg, _, err := s.build()
+	return cfg, err
+}
+
+func (s *ServiceBuilder) build() (*ServiceConfig, []expectation, error) {
+	cfg := &ServiceConfig{
+		ServiceName: s.name,
+		Port:        s.port,
+		Endpoints:   make(map[string]config_reader.EndpointList),
+		Scenarios:   s.scenarios,
+		Fallback:    s.fallback,
+		Fault:       s.fault,
+		Delay:       s.delay,
+		TLS:         s.tls,
+		Protocols:   s.protocols,
+		GRPC:        s.grpc,
+	}
+
+	expectations := []expectation{}
+
+	for _, stub := range s.stubs {
+		key := stub.method + " " + stub.path
+		if stub.err != nil {
+			return nil, nil, fmt.Errorf("stub %s: %w", key, stub.err)
+		}
+
+		if stub.expectation != nil {
+			route := config_reader.Route{Key: key, Index: len(cfg.Endpoints[key])}
+
+			expected := *stub.expectation
+			expected.service = s.name
+			expected.target = route.ID()
+			expectations = append(expectations, expected)
+		}
+
+		cfg.Endpoints[key] = append(cfg.Endpoints[key], stub.endpoint)
+	}
+
+	return cfg, expectations, nil
+}
+
+// WithQuery requires a query parameter with the exact value.
+func (b *StubBuilder) WithQuery(name, value string) *StubBuilder {
+	return b.withQuery(name, config_reader.ValueMatcher{EqualTo: value})
+}
+
+// WithQueryMatching requires a query parameter matching the regular expression.
+func (b *StubBuilder) WithQueryMatching(name, pattern string) *StubBuilder {
+	return b.withQuery(name, config_reader.ValueMatcher{Matches: pattern})
+}
+
+// WithHeader requires a request header with the exact value.
+func (b *StubBuilder) WithHeader(name, value string) *StubBuilder {
+	return b.withHeader(name, config_reader.ValueMatcher{EqualTo: value})
+}
+
+// WithHeaderMatching requires a request header matching the regular expression.
+func (b *StubBuilder) WithHeaderMatching(name, pattern string) *StubBuilder {
+	return b.withHeader(name, config_reader.ValueMatcher{Matches: pattern})
+}
+
+// WithoutHeader requires the request header to be absent.
+func (b *StubBuilder) WithoutHeader(name string) *StubBuilder {
+	return b.withHeader(name, absentMatcher())
+}
+
+// WithClientCert requires a client certificate field with the exact value. The
+// fields are listed in ClientCertFields, e.g. "common_name" or "san".
+func (b *StubBuilder) WithClientCert(field, value string) *StubBuilder {
+	return b.withClientCert(field, config_reader.ValueMatcher{EqualTo: value})
+}
+
+// WithClientCertMatching requires a client certificate field matching the
+// regular expression.
+func (b *StubBuilder) WithClientCertMatching(field, pattern string) *StubBuilder {
+	return b.withClientCert(field, config_reader.ValueMatcher{Matches: pattern})
+}
+
+// WithoutClientCert requires the request to carry no client certificate.
+func (b *StubBuilder) WithoutClientCert() *StubBuilder {
+	return b.withClientCert("subject", absentMatcher())
+}
+
+// WithProtocol requires the request protocol, such as "HTTP/1.1" or "HTTP/2".
+func (b *StubBuilder) WithProtocol(protocol string) *StubBuilder {
+	b.endpoint.Match.Protocol = &config_reader.ValueMatcher{EqualTo: protocol}
+	return b
+}
+
+// WithBodyJSON requires the request body to be JSON equal to body, which is
+// marshaled with encoding/json.
+func (b *StubBuilder) WithBodyJSON(body any) *StubBuilder {
+	value, err := toJSONValue(body)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	return b.withBody(config_reader.BodyMatcher{EqualToJSON: value})
+}
+
+// WithBodyContainingJSON requires the request body to be JSON containing every
+// field of body.
+func (b *StubBuilder) WithBodyContainingJSON(body any) *StubBuilder {
+	value, err := toJSONValue(body)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	return b.withBody(config_reader.BodyMatcher{ContainsJSON: value})
+}
+
+// WithBodyJSONPath requires the JSON request body to satisfy the expression,
+// such as "$.amount > 1000".
+func (b *StubBuilder) WithBodyJSONPath(expression string) *StubBuilder {
+	return b.withBody(config_reader.BodyMatcher{JSONPath: expression})
+}
+
+// WithBodyMatching requires the raw request body to match the regular expression.
+func (b *StubBuilder) WithBodyMatching(pattern string) *StubBuilder {
+	return b.withBody(config_reader.BodyMatcher{Matches: pattern})
+}
+
+// Priority sets the stub priority. Higher priorities are tried first.
+func (b *StubBuilder) Priority(priority int) *StubBuilder {
+	b.endpoint.Priority = priority
+	return b
+}
+
+// InScenario makes the stub match only while the scenario is in the given
+// state. Pass an empty state to match in any state.
+func (b *StubBuilder) InScenario(scenario, requiredState string) *StubBuilder {
+	b.endpoint.Scenario = scenario
+	b.endpoint.RequiredState = requiredState
+	return b
+}
+
+// WillSetStateTo moves the stub's scenario to the given state after it matches.
+func (b *StubBuilder) WillSetStateTo(state string) *StubBuilder {
+	b.endpoint.NewState = state
+	return b
+}
+
+// Times expects the stub to match exactly n requests. Expectations are
+// checked by Manager.AssertExpectations and at the end of tests using Start.
+func (b *StubBuilder) Times(n int) *StubBuilder {
+	b.expectation = &expectation{min: n, max: n}
+	return b
+}
+
+// AtLeast expects the stub to match n requests or more.
+func (b *StubBuilder) AtLeast(n int) *StubBuilder {
+	b.expectation = &expectation{min: n, max: -1}
+	return b
+}
+
+// Reply sets the response status code and returns a builder for the rest of
+// the response.
+func (b *StubBuilder) Reply(statusCode int) *ResponseBuilder {
+	b.endpoint.StatusCode = statusCode
+	return &ResponseBuilder{stub: b}
+}
+
+func (b *StubBuilder) withQuery(name string, matcher config_reader.ValueMatcher) *StubBuilder {
+	if b.endpoint.Query == nil {
+		b.endpoint.Query = make(map[string]config_reader.ValueMatcher)
+	}
+
+	b.endpoint.Query[name] = matcher
+
+	return b
+}
+
+func (b *StubBuilder) withHeader(name string, matcher config_reader.ValueMatcher) *StubBuilder {
+	if b.endpoint.Match.Headers == nil {
+		b.endpoint.Match.Headers = make(map[string]config_reader.ValueMatcher)
+	}
+
+	b.endpoint.Match.Headers[name] = matcher
+
+	return b
+}
+
+func absentMatcher() config_reader.ValueMatcher {
+	present := false
+	return config_reader.ValueMatcher{Present: &present}
+}
+
+func (b *StubBuilder) withClientCert(field string, matcher config_reader.ValueMatcher) *StubBuilder {
+	if b.endpoint.Match.ClientCert == nil {
+		b.endpoint.Match.ClientCert = make(map[string]config_reader.ValueMatcher)
+	}
+
+	b.endpoint.Match.ClientCert[field] = matcher
+
+	return b
+}
+
+func (b *StubBuilder) withBody(matcher config_reader.BodyMatcher) *StubBuilder {
+	b.endpoint.Match.Body = append(b.endpoint.Match.Body, matcher)
+	return b
+}
+
+// Header sets a response header.
+func (r *ResponseBuilder) Header(name, value string) *ResponseBuilder {
+	if r.stub.endpoint.Headers == nil {
+		r.stub.endpoint.Headers = make(map[string]string)
+	}
+
+	r.stub.endpoint.Headers[name] = value
+
+	return r
+}
+
+// JSON sets the response body to body marshaled with encoding/json.
+func (r *ResponseBuilder) JSON(body any) *ResponseBuilder {
+	value, err := toJSONValue(body)
+	if err != nil {
+		r.stub.err = err
+		return r
+	}
+
+	r.stub.endpoint.Body = value
+
+	return r.Header("Content-Type", "application/json")
+}
+
+// Text sets a plain text response body.
+func (r *ResponseBuilder) Text(body string) *ResponseBuilder {
+	r.stub.endpoint.Body = body
+	return r.Header("Content-Type", "text/plain")
+}
+
+// Delay waits the given duration before responding.
+func (r *ResponseBuilder) Delay(delay time.Duration) *ResponseBuilder {
+	r.stub.endpoint.Delay = config_reader.FixedDelay(delay)
+	return r
+}
+
+// DelayDistribution waits a delay drawn from the distribution before each
+// response, e.g. between 100 and 300 milliseconds:
+//
+//	Reply(200).DelayDistribution(gockapi.DelayConfig{Distribution: gockapi.DelayUniform, Min: 100 * time.Millisecond, Max: 300 * time.Millisecond})
+func (r *ResponseBuilder) DelayDistribution(delay DelayConfig) *ResponseBuilder {
+	r.stub.endpoint.Delay = &delay
+	return r
+}
+
+// Fault injects the fault instead of sending the response. Use FaultNone to
+// opt out of the service's fault.
+func (r *ResponseBuilder) Fault(fault FaultConfig) *ResponseBuilder {
+	r.stub.endpoint.Fault = &fault
+	return r
+}
+
+// Template renders the response headers and body as Go templates with the
+// request data, like "template": true in a config file.
+func (r *ResponseBuilder) Template() *ResponseBuilder {
+	r.stub.endpoint.Template = true
+	return r
+}
+
+// On adds another stub to the service of this response.
+func (r *ResponseBuilder) On(method, path string) *StubBuilder {
+	return r.stub.service.On(method, path)
+}
+
+// Service returns the service the stub belongs to, e.g. to pass it to Serve.
+func (r *ResponseBuilder) Service() *ServiceBuilder {
+	return r.stub.service
+}
+
+// toJSONValue converts a Go value into the generic form produced by decoding
+// JSON, so builders and config files are matched and rendered the same way.
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,34 @@
+package gockapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceBuilderChain(t *testing.T) {
+	payments := NewService("payments").
+		On("POST", "/charge").
+		WithHeader("Authorization", "Bearer test").
+		Reply(201).
+		JSON(map[string]any{"id": "ch_1"}).
+		On("post", "/charge").
+		Reply(401).
+		On("GET", "/charge/{id}").
+		Reply(200).
+		Text("ok").
+		Service()
+
+	cfg, err := payments.Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "payments", cfg.ServiceName)
+	require.Len(t, cfg.Endpoints["POST /charge"], 2)
+	assert.Equal(t, 201, cfg.Endpoints["POST /charge"][0].StatusCode)
+	assert.Equal(t, map[string]any{"id": "ch_1"}, cfg.Endpoints["POST /charge"][0].Body)
+	assert.Contains(t, cfg.Endpoints["POST /charge"][0].Match.Headers, "Authorization")
+	assert.Equal(t, 401, cfg.Endpoints["POST /charge"][1].StatusCode)
+	require.Len(t, cfg.Endpoints["GET /charge/{id}"], 1)
+	assert.Equal(t, "ok", cfg.Endpoints["GET /charge/{id}"][0].Body)
+}
@@ -0,0 +1,109 @@
+package gockapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expectation is a call count a service endpoint must reach. target is either
+// an endpoint key such as "POST /charge", covering all its variants, the ID of
+// a single variant such as "POST /charge#1", or a gRPC method such as
+// "payments.v1.Payments/Charge". A negative max means no upper bound.
+type expectation struct {
+	service string
+	target  string
+	min     int
+	max     int
+}
+
+func (e expectation) count(counts map[string]int) int {
+	if strings.Contains(e.target, "#") {
+		return counts[e.target]
+	}
+
+	total := 0
+	for id, count := range counts {
+		// gRPC methods are counted under their name, without a variant index.
+		key := id
+		if index := strings.LastIndex(id, "#"); index >= 0 {
+			key = id[:index]
+		}
+
+		if key == e.target {
+			total += count
+		}
+	}
+
+	return total
+}
+
+func (e expectation) describe() string {
+	if e.max < 0 {
+		return fmt.Sprintf("at least %d call(s)", e.min)
+	}
+
+	return fmt.Sprintf("%d call(s)", e.min)
+}
+
+// Expect records that a service must match the endpoint key, e.g.
+// "POST /api/users", or the gRPC method, e.g. "payments.v1.Payments/Charge",
+// exactly times times. Use AssertExpectations to check it;
+// tests using Start check it automatically.
+func (m *Manager) Expect(name, endpointKey string, times int) {
+	m.addExpectations(expectation{service: name, target: endpointKey, min: times, max: times})
+}
+
+// AssertExpectations reports a test error for every expectation set with
+// Expect, StubBuilder.Times or StubBuilder.AtLeast that was not met.
+// It returns whether all of them held.
+//
+// Calls are counted from the request journal, like AssertCalled, so
+// ResetRequests clears them while ResetCounters does not.
+func (m *Manager) AssertExpectations(t TestingT) bool {
+	t.Helper()
+
+	m.mu.Lock()
+	expectations := append([]expectation(nil), m.expectations...)
+	m.mu.Unlock()
+
+	met := true
+
+	for _, expected := range expectations {
+		requests, err := m.mgr.GetRecordedRequests(expected.service)
+		if err != nil {
+			t.Errorf("expected %s to receive %s %s: %v", expected.service, expected.target, expected.describe(), err)
+			met = false
+			continue
+		}
+
+		count := expected.count(journalCounts(requests))
+		if count >= expected.min && (expected.max < 0 || count <= expected.max) {
+			continue
+		}
+
+		t.Errorf("expected %s to receive %s %s, got %d:%s", expected.service, expected.target, expected.describe(), count, formatRequests(m.Requests(expected.service)))
+		met = false
+	}
+
+	return met
+}
+
+// journalCounts returns how many recorded requests each endpoint variant
+// matched, by variant ID.
+func journalCounts(requests []RecordedRequest) map[string]int {
+	counts := map[string]int{}
+	for _, request := range requests {
+		if request.Matched() {
+			counts[request.EndpointID]++
+		}
+	}
+
+	return counts
+}
+
+func (m *Manager) addExpectations(expectations ...expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expectations = append(m.expectations, expectations...)
+}
@@ -0,0 +1,95 @@
+package gockapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectationCount(t *testing.T) {
+	counts := map[string]int{
+		"POST /charge#0":              2,
+		"POST /charge#1":              1,
+		"GET /charge/{id}#handler":    4,
+		"GET /charge/{id}#0":          1,
+		"payments.v1.Payments/Charge": 3,
+		"payments.v1.Payments/Refund": 5,
+	}
+
+	tests := []struct {
+		target   string
+		expected int
+	}{
+		{target: "POST /charge", expected: 3},
+		{target: "POST /charge#1", expected: 1},
+		{target: "GET /charge/{id}", expected: 5},
+		{target: "GET /charge/{id}#handler", expected: 4},
+		{target: "payments.v1.Payments/Charge", expected: 3},
+		{target: "payments.v1.Payments/Missing", expected: 0},
+		{target: "DELETE /charge", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expectation{target: tt.target}.count(counts))
+		})
+	}
+}
+
+func TestJournalCounts(t *testing.T) {
+	counts := journalCounts([]RecordedRequest{
+		{EndpointKey: "POST /charge", EndpointID: "POST /charge#0"},
+		{EndpointKey: "POST /charge", EndpointID: "POST /charge#1"},
+		{EndpointKey: "POST /charge", EndpointID: "POST /charge#0", Cancelled: true},
+		{EndpointKey: "payments.v1.Payments/Charge", EndpointID: "payments.v1.Payments/Charge"},
+		{Path: "/unmatched"},
+	})
+
+	assert.Equal(t, map[string]int{
+		"POST /charge#0":              2,
+		"POST /charge#1":              1,
+		"payments.v1.Payments/Charge": 1,
+	}, counts)
+}
+
+func TestAssertExpectationsCountsTheJournal(t *testing.T) {
+	payments := NewService("payments").
+		On("POST", "/charge").WithHeader("X-Retry", "1").Times(1).Reply(409).
+		On("POST", "/charge").AtLeast(1).Reply(201).
+		Service()
+
+	m := serve(t, payments)
+	m.Expect("payments", "POST /charge", 2)
+
+	postCharge(t, m, "")
+	postCharge(t, m, "1")
+
+	recorder := &recordingT{}
+	assert.True(t, m.AssertExpectations(recorder), recorder.errors)
+
+	require.NoError(t, m.ResetCounters("payments"))
+	assert.True(t, m.AssertExpectations(recorder), "resetting the sequence counters keeps the calls")
+	assert.Empty(t, recorder.errors)
+
+	require.NoError(t, m.ResetRequests("payments"))
+	assert.False(t, m.AssertExpectations(recorder))
+	assert.Len(t, recorder.errors, 3)
+	assert.False(t, m.AssertCalled(&recordingT{}, "payments", "POST /charge"), "both assertions agree")
+}
+
+func postCharge(t *testing.T, m *Manager, retry string) {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", m.URL("payments")+"/charge", nil)
+	require.NoError(t, err)
+
+	if retry != "" {
+		req.Header.Set("X-Retry", retry)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
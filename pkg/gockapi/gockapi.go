@@ -18,6 +18,9 @@ package gockapi
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/JTGlez/gockapi/internal/config_reader"
 	"github.com/JTGlez/gockapi/internal/manager"
@@ -25,14 +28,51 @@ import (
 
 type ServiceConfig = config_reader.ServiceConfig
 
+// ConfigDecoder parses service files in another configuration format.
+// Extensions lists the lowercase file extensions it handles, including the dot.
+type ConfigDecoder = config_reader.ConfigDecoder
+
+// ConfigEncoder is implemented by decoders that can also write their format,
+// which recording needs to save new endpoints to a service file.
+type ConfigEncoder = config_reader.ConfigEncoder
+
 type Manager struct {
-	mgr *manager.MockManager
+	mgr          *manager.MockManager
+	expectations []expectation
+	mu           sync.Mutex
+}
+
+// Option customizes a Manager created with NewManager.
+type Option func(*Manager)
+
+// WithEphemeralPorts makes every service listen on a free port picked by the
+// system, ignoring the port in its configuration. Use URL to reach it.
+// This lets parallel tests and CI jobs start the same services without colliding.
+func WithEphemeralPorts() Option {
+	return func(m *Manager) {
+		m.mgr.SetEphemeralPorts(true)
+	}
 }
 
 // NewManager creates a new mock server manager for attached mode.
-// The configPath should point to a directory containing JSON config files.
-func NewManager(configPath string) *Manager {
-	return &Manager{mgr: manager.NewMockManager(configPath)}
+// The configPath should point to a directory of service config files in JSON,
+// YAML (.yaml, .yml) or TOML format.
+// It may be empty when every service is described in code with NewService.
+func NewManager(configPath string, opts ...Option) *Manager {
+	m := &Manager{mgr: manager.NewMockManager(configPath)}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// RegisterDecoder adds support for another configuration format in the config
+// directory. It takes precedence over the built-in formats for the extensions
+// they share. Register decoders before starting services.
+func (m *Manager) RegisterDecoder(decoder ConfigDecoder) {
+	m.mgr.RegisterDecoder(decoder)
 }
 
 // StartAll starts all mock servers from the config directory.
@@ -49,6 +89,32 @@ func (m *Manager) StartService(ctx context.Context, name string) error {
 	return m.mgr.StartService(ctx, name)
 }
 
+// Serve starts mock servers described in code with NewService.
+// It blocks until every server is ready and stops at the first error.
+// Services started this way are not reloaded from disk.
+func (m *Manager) Serve(ctx context.Context, services ...*ServiceBuilder) error {
+	for _, service := range services {
+		cfg, expectations, err := service.build()
+		if err != nil {
+			return fmt.Errorf("invalid service %s: %w", service.name, err)
+		}
+
+		err = m.mgr.StartServiceConfig(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
+		m.addExpectations(expectations...)
+	}
+
+	return nil
+}
+
+// StartServiceConfig starts a mock server from a configuration built in memory.
+func (m *Manager) StartServiceConfig(ctx context.Context, cfg *ServiceConfig) error {
+	return m.mgr.StartServiceConfig(ctx, cfg)
+}
+
 // StopAll stops all running mock servers.
 // This provides clean shutdown and port cleanup.
 func (m *Manager) StopAll() error {
@@ -64,3 +130,98 @@ func (m *Manager) StopService(name string) error {
 func (m *Manager) GetRunningServices() []string {
 	return m.mgr.GetRunningServices()
 }
+
+// URL returns the base URL of a running service, such as
+// "http://localhost:55001", or the address to dial for gRPC services, such as
+// "localhost:55100". It returns an empty string when the service is not running.
+func (m *Manager) URL(name string) string {
+	url, err := m.mgr.GetServiceURL(name)
+	if err != nil {
+		return ""
+	}
+
+	return url
+}
+
+// ResetCounters resets the per-endpoint call counters of a running service,
+// restarting every response sequence from its first entry.
+func (m *Manager) ResetCounters(name string) error {
+	return m.mgr.ResetCounters(name)
+}
+
+// HandleFunc answers requests to method and path on a running service with a
+// Go handler, for responses a config file cannot express such as signed
+// tokens or checksums of the request body. Path parameters such as {id} are
+// available through r.PathValue("id"), and the request body can be read even
+// though it was already recorded.
+//
+// Handlers are tried before the endpoints of the service configuration, even
+// more specific ones: a handler on "/users/{id}" also answers "/users/me" when
+// the configuration declares it. Among handlers the most specific path wins.
+// Handlers survive config reloads. Registering a handler again for the same
+// method and path replaces it. Calls count towards CountCalls and Expect like
+// any endpoint.
+func (m *Manager) HandleFunc(name, method, path string, handler http.HandlerFunc) error {
+	return m.mgr.HandleFunc(name, method, path, handler)
+}
+
+// RemoveHandler removes the Go handler registered for method and path.
+func (m *Manager) RemoveHandler(name, method, path string) error {
+	return m.mgr.RemoveHandler(name, method, path)
+}
+
+// Requests returns the requests received by a running service, oldest first.
+// It returns nil when the service is not running.
+func (m *Manager) Requests(name string) []RecordedRequest {
+	requests, err := m.mgr.GetRecordedRequests(name)
+	if err != nil {
+		return nil
+	}
+
+	return requests
+}
+
+// CountCalls returns how many requests a running service matched against the
+// given endpoint key, e.g. "POST /api/users" or "GET /api/users/{id}".
+func (m *Manager) CountCalls(name, endpointKey string) int {
+	count, err := m.mgr.CountCalls(name, endpointKey)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
+// ResetRequests clears the request journal of a running service.
+func (m *Manager) ResetRequests(name string) error {
+	return m.mgr.ResetRecordedRequests(name)
+}
+
+// AssertCalled reports a test error when the service did not receive any
+// request matching the endpoint key. It returns whether the assertion held.
+func (m *Manager) AssertCalled(t TestingT, name, endpointKey string) bool {
+	t.Helper()
+
+	if m.CountCalls(name, endpointKey) > 0 {
+		return true
+	}
+
+	t.Errorf("expected %s to receive %s, got:%s", name, endpointKey, formatRequests(m.Requests(name)))
+
+	return false
+}
+
+// AssertNotCalled reports a test error when the service received a request
+// matching the endpoint key. It returns whether the assertion held.
+func (m *Manager) AssertNotCalled(t TestingT, name, endpointKey string) bool {
+	t.Helper()
+
+	count := m.CountCalls(name, endpointKey)
+	if count == 0 {
+		return true
+	}
+
+	t.Errorf("expected %s not to receive %s, got it %d time(s)", name, endpointKey, count)
+
+	return false
+}
@@ -0,0 +1,80 @@
+package gockapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT collects the errors reported by assertions under test.
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func serve(t *testing.T, services ...*ServiceBuilder) *Manager {
+	t.Helper()
+
+	m := NewManager("", WithEphemeralPorts())
+	require.NoError(t, m.Serve(context.Background(), services...))
+	t.Cleanup(func() {
+		_ = m.StopAll()
+	})
+
+	return m
+}
+
+func get(t *testing.T, url string) *http.Response {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	return resp
+}
+
+func TestRequestVerification(t *testing.T) {
+	m := serve(t, NewService("users").On("GET", "/users/{id}").Reply(200).JSON(map[string]any{"id": 1}).Service())
+
+	get(t, m.URL("users")+"/users/1?fields=name")
+	get(t, m.URL("users")+"/users/2")
+	get(t, m.URL("users")+"/missing")
+
+	requests := m.Requests("users")
+	require.Len(t, requests, 3)
+	assert.Equal(t, "/users/1", requests[0].Path)
+	assert.Equal(t, "fields=name", requests[0].Query)
+	assert.Equal(t, "GET /users/{id}", requests[0].EndpointKey)
+	require.NotNil(t, requests[0].Response)
+	assert.Equal(t, 200, requests[0].Response.StatusCode)
+	assert.False(t, requests[2].Matched())
+
+	assert.Equal(t, 2, m.CountCalls("users", "GET /users/{id}"))
+	assert.Equal(t, 0, m.CountCalls("users", "POST /users"))
+	assert.Nil(t, m.Requests("unknown"))
+
+	recorder := &recordingT{}
+	assert.True(t, m.AssertCalled(recorder, "users", "GET /users/{id}"))
+	assert.True(t, m.AssertNotCalled(recorder, "users", "POST /users"))
+	assert.Empty(t, recorder.errors)
+
+	assert.False(t, m.AssertCalled(recorder, "users", "POST /users"))
+	assert.False(t, m.AssertNotCalled(recorder, "users", "GET /users/{id}"))
+	require.Len(t, recorder.errors, 2)
+	assert.Contains(t, recorder.errors[0], "GET /missing -> unmatched")
+	assert.Contains(t, recorder.errors[1], "got it 2 time(s)")
+
+	require.NoError(t, m.ResetRequests("users"))
+	assert.Empty(t, m.Requests("users"))
+	assert.Equal(t, 0, m.CountCalls("users", "GET /users/{id}"))
+}
@@ -0,0 +1,63 @@
+package gockapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/JTGlez/gockapi/internal/har"
+)
+
+// HAROptions controls how a HAR file becomes mock services: the hosts to
+// import (every host by default) and the first of the consecutive ports given
+// to the services (0 picks free ports).
+type HAROptions = har.ImportOptions
+
+// ImportHAR builds one service configuration per host from the entries of a
+// HAR file, as saved by browser devtools or a proxy. Services are named after
+// their host, e.g. "api-example-com". Requests to the same method and path
+// become one endpoint, with a variant per distinct query and body, and
+// repeated requests replay their responses in order.
+func ImportHAR(path string, opts HAROptions) ([]*ServiceConfig, error) {
+	return har.Import(path, opts)
+}
+
+// StartHAR serves the hosts recorded in a HAR file without writing config
+// files and returns the names of the started services.
+func (m *Manager) StartHAR(ctx context.Context, path string, opts HAROptions) ([]string, error) {
+	configs, err := ImportHAR(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, cfg := range configs {
+		if err := m.mgr.StartServiceConfig(ctx, cfg); err != nil {
+			return names, err
+		}
+
+		names = append(names, cfg.ServiceName)
+	}
+
+	return names, nil
+}
+
+// ExportHAR writes the request journal of a running service to w as a HAR
+// file, which browser devtools can open. Each entry's comment names the
+// endpoint that answered it, "fallback" or "unmatched".
+func (m *Manager) ExportHAR(name string, w io.Writer) error {
+	archive, err := m.mgr.ExportHAR(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(archive); err != nil {
+		return fmt.Errorf("failed to write HAR for %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package gockapi
+
+import (
+	"context"
+
+	"github.com/JTGlez/gockapi/internal/openapi"
+)
+
+// OpenAPIOptions controls how an OpenAPI document becomes a mock service:
+// the service name (derived from info.title by default), the port (0 picks a
+// free one), the base path (the path of the first server URL by default) and
+// whether incoming requests are validated against the document.
+type OpenAPIOptions = openapi.ImportOptions
+
+// ImportOpenAPI builds a service configuration from an OpenAPI 3 document in
+// YAML or JSON. Every operation answers with its lowest 2xx response, using
+// the response examples or values generated from the schema. Other responses
+// and named examples are selected with a "Prefer: code=404" or
+// "Prefer: example=name" request header.
+//
+// With ValidateRequests set, requests are checked against the document and
+// invalid ones are answered with a 400 listing the violations, which are also
+// recorded in the journal.
+func ImportOpenAPI(specPath string, opts OpenAPIOptions) (*ServiceConfig, error) {
+	return openapi.Import(specPath, opts)
+}
+
+// StartOpenAPI serves an OpenAPI 3 document directly, without writing a config
+// file, and returns the name of the started service.
+func (m *Manager) StartOpenAPI(ctx context.Context, specPath string, opts OpenAPIOptions) (string, error) {
+	cfg, err := ImportOpenAPI(specPath, opts)
+	if err != nil {
+		return "", err
+	}
+
+	err = m.mgr.StartServiceConfig(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	return cfg.ServiceName, nil
+}
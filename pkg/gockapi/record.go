@@ -0,0 +1,29 @@
+package gockapi
+
+import (
+	"context"
+
+	"github.com/JTGlez/gockapi/internal/manager"
+)
+
+// RecordOptions configures a recording session: Target is the base URL of the
+// real service, such as "http://localhost:9000", and Port the port of a new
+// service that has no config file yet (0 picks a free one).
+type RecordOptions = manager.RecordOptions
+
+// Record forwards the requests the service does not match to opts.Target and
+// turns every exchange into an endpoint variant matching the same method,
+// path, query and body, so the next identical request is answered by the mock.
+//
+// Services started from the config directory also get the new endpoints
+// written to their config file, which is created with the first exchange when
+// the service has none. A service that is not running is started first.
+func (m *Manager) Record(ctx context.Context, name string, opts RecordOptions) error {
+	return m.mgr.StartRecording(ctx, name, opts)
+}
+
+// StopRecording stops forwarding unmatched requests; they are answered with a
+// 404 again.
+func (m *Manager) StopRecording(name string) error {
+	return m.mgr.StopRecording(name)
+}
@@ -0,0 +1,202 @@
+package gockapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StartOption configures the services started by Start.
+type StartOption func(*startConfig)
+
+type startConfig struct {
+	configPath     string
+	configServices []string
+	builders       []*ServiceBuilder
+	managerOptions []Option
+	allowUnmatched bool
+}
+
+// FromConfigDir starts services from the JSON, YAML or TOML files in a config
+// directory.
+// When no service names are given every service in the directory is started.
+func FromConfigDir(configPath string, services ...string) StartOption {
+	return func(c *startConfig) {
+		c.configPath = configPath
+		c.configServices = services
+	}
+}
+
+// WithServices starts services described in code with NewService.
+func WithServices(services ...*ServiceBuilder) StartOption {
+	return func(c *startConfig) {
+		c.builders = append(c.builders, services...)
+	}
+}
+
+// WithOptions applies Manager options, such as WithEphemeralPorts.
+func WithOptions(opts ...Option) StartOption {
+	return func(c *startConfig) {
+		c.managerOptions = append(c.managerOptions, opts...)
+	}
+}
+
+// AllowUnmatched stops Start from failing the test when a service receives
+// requests that match none of its endpoints.
+func AllowUnmatched() StartOption {
+	return func(c *startConfig) {
+		c.allowUnmatched = true
+	}
+}
+
+// Start creates a Manager for a test, starts the requested services and
+// registers a cleanup that stops them when the test ends.
+//
+//	payments := gockapi.NewService("payments")
+//	payments.On("POST", "/charge").Times(1).Reply(201).JSON(charge)
+//
+//	mgr := gockapi.Start(t,
+//	    gockapi.FromConfigDir("./test-configs", "userService"),
+//	    gockapi.WithServices(payments),
+//	)
+//
+// A service that fails to start fails the test immediately. Before stopping
+// the services, the cleanup reports unmet expectations, unmatched requests and
+// requests violating a service's OpenAPI contract as test errors, and logs
+// every service's request journal if the test failed. Requests answered by a
+// service's fallback are not reported as unmatched.
+func Start(t TestingTB, opts ...StartOption) *Manager {
+	t.Helper()
+
+	cfg := &startConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	m := NewManager(cfg.configPath, cfg.managerOptions...)
+
+	t.Cleanup(func() {
+		m.finishTest(t, cfg.allowUnmatched)
+	})
+
+	ctx := context.Background()
+
+	switch {
+	case len(cfg.configServices) > 0:
+		for _, name := range cfg.configServices {
+			if err := m.StartService(ctx, name); err != nil {
+				t.Fatalf("gockapi: failed to start service %s from %s: %v", name, cfg.configPath, err)
+			}
+		}
+	case cfg.configPath != "":
+		if err := m.StartAll(ctx); err != nil {
+			t.Fatalf("gockapi: failed to start services from %s: %v", cfg.configPath, err)
+		}
+	}
+
+	if err := m.Serve(ctx, cfg.builders...); err != nil {
+		t.Fatalf("gockapi: %v", err)
+	}
+
+	return m
+}
+
+func (m *Manager) finishTest(t TestingTB, allowUnmatched bool) {
+	t.Helper()
+
+	services := m.GetRunningServices()
+	if len(services) == 0 {
+		return
+	}
+
+	slices.Sort(services)
+
+	m.AssertExpectations(t)
+
+	for _, name := range services {
+		unmatched := []RecordedRequest{}
+		invalid := []RecordedRequest{}
+
+		for _, request := range m.Requests(name) {
+			switch {
+			case len(request.Violations) > 0:
+				invalid = append(invalid, request)
+			case !request.Matched() && !request.Fallback && request.ClientCertError == "":
+				unmatched = append(unmatched, request)
+			}
+		}
+
+		if len(invalid) > 0 {
+			t.Errorf("gockapi: %s received %d request(s) violating its OpenAPI contract:%s", name, len(invalid), formatRequests(invalid))
+		}
+
+		if len(unmatched) > 0 && !allowUnmatched {
+			t.Errorf("gockapi: %s received %d unmatched request(s):%s", name, len(unmatched), formatRequests(unmatched))
+		}
+	}
+
+	if t.Failed() {
+		for _, name := range services {
+			t.Logf("gockapi: request journal of %s:%s", name, formatRequests(m.Requests(name)))
+		}
+	}
+
+	if err := m.StopAll(); err != nil {
+		t.Errorf("gockapi: failed to stop services: %v", err)
+	}
+}
+
+func requestOutcome(request RecordedRequest) string {
+	switch {
+	case request.Matched() && request.Cancelled:
+		return request.EndpointKey + " (cancelled)"
+	case request.Matched():
+		return request.EndpointKey
+	case request.Fallback:
+		return "fallback"
+	case request.ClientCertError != "":
+		return "rejected: " + request.ClientCertError
+	}
+
+	return "unmatched"
+}
+
+// formatRequests lists the requests with their outcome, headers, body and
+// contract violations, for test failures and journal logs.
+func formatRequests(requests []RecordedRequest) string {
+	if len(requests) == 0 {
+		return " no requests"
+	}
+
+	var builder strings.Builder
+	for i, request := range requests {
+		target := request.Path
+		if request.Query != "" {
+			target += "?" + request.Query
+		}
+
+		fmt.Fprintf(&builder, "\n  #%d %s %s %s -> %s", i+1, request.Timestamp.Format("15:04:05.000"), request.Method, target, requestOutcome(request))
+
+		headerNames := make([]string, 0, len(request.Headers))
+		for name := range request.Headers {
+			headerNames = append(headerNames, name)
+		}
+
+		slices.Sort(headerNames)
+
+		for _, name := range headerNames {
+			fmt.Fprintf(&builder, "\n      %s: %s", name, strings.Join(request.Headers[name], ", "))
+		}
+
+		if request.Body != "" {
+			fmt.Fprintf(&builder, "\n      %s", request.Body)
+		}
+
+		for _, violation := range request.Violations {
+			fmt.Fprintf(&builder, "\n      ! %s", violation)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,86 @@
+package gockapi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CACertPEM returns the PEM certificate clients should trust to reach a
+// running HTTPS service, or nil when the service is not running or serves
+// plain HTTP.
+func (m *Manager) CACertPEM(name string) []byte {
+	caPEM, err := m.mgr.GetCACertificate(name)
+	if err != nil {
+		return nil
+	}
+
+	return caPEM
+}
+
+// TLSConfig returns a client TLS config trusting the CA that issues the
+// certificates of HTTPS services without certificate files, and the
+// certificates of the HTTPS services running when it is called. The client
+// certificates, if any, are presented to services asking for one. It fails
+// when the CA cannot be generated.
+func (m *Manager) TLSConfig(clientCerts ...tls.Certificate) (*tls.Config, error) {
+	config, err := m.mgr.TLSConfig(clientCerts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tls config: %w", err)
+	}
+
+	return config, nil
+}
+
+// HTTPClient returns a client for the mock services, trusting their
+// certificates and presenting the client certificates like TLSConfig:
+//
+//	svc := gockapi.NewService("payments").TLS(gockapi.TLSConfig{})
+//	mgr.Serve(ctx, svc)
+//	client, err := mgr.HTTPClient()
+//	resp, err := client.Get(mgr.URL("payments") + "/charges")
+func (m *Manager) HTTPClient(clientCerts ...tls.Certificate) (*http.Client, error) {
+	config, err := m.TLSConfig(clientCerts...)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
+
+// HTTP2Client returns a client like HTTPClient that speaks only HTTP/2: over
+// TLS to HTTPS services, and over cleartext with prior knowledge (h2c) to the
+// others.
+func (m *Manager) HTTP2Client(clientCerts ...tls.Certificate) (*http.Client, error) {
+	client, err := m.HTTPClient(clientCerts...)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := client.Transport.(*http.Transport)
+	transport.Protocols = &http.Protocols{}
+	transport.Protocols.SetHTTP2(true)
+	transport.Protocols.SetUnencryptedHTTP2(true)
+
+	return client, nil
+}
+
+// ClientCertificate issues a client certificate signed by the generated CA,
+// which services with a client_auth block and no CA file accept. Each SAN is
+// stored as an IP address, email address, URI or DNS name depending on its
+// form:
+//
+//	cert, err := mgr.ClientCertificate("billing", "billing.internal", "spiffe://corp/billing")
+//	client, err := mgr.HTTPClient(cert)
+func (m *Manager) ClientCertificate(commonName string, sans ...string) (tls.Certificate, error) {
+	cert, err := m.mgr.IssueClientCertificate(commonName, sans)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return *cert, nil
+}
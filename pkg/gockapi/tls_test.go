@@ -0,0 +1,88 @@
+package gockapi
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClientTrustsTLSServices(t *testing.T) {
+	m := serve(t,
+		NewService("payments").TLS(TLSConfig{}).On("GET", "/charges").Reply(200).Text("charges").Service(),
+		NewService("users").On("GET", "/users").Reply(200).Service(),
+	)
+
+	assert.Contains(t, m.URL("payments"), "https://")
+
+	client, err := m.HTTPClient()
+	require.NoError(t, err)
+
+	resp, err := client.Get(m.URL("payments") + "/charges")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "HTTP/2.0", resp.Proto)
+
+	resp, err = client.Get(m.URL("users") + "/users")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = http.Get(m.URL("payments") + "/charges")
+	assert.Error(t, err, "the default client does not trust the generated CA")
+}
+
+func TestTLSConfig(t *testing.T) {
+	m := serve(t, NewService("payments").TLS(TLSConfig{}).On("GET", "/charges").Reply(200).Service())
+
+	caPEM := m.CACertPEM("payments")
+	require.NotNil(t, caPEM)
+	assert.Nil(t, m.CACertPEM("missing"))
+
+	block, _ := pem.Decode(caPEM)
+	require.NotNil(t, block)
+	ca, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	cert, err := m.ClientCertificate("billing", "spiffe://corp/billing")
+	require.NoError(t, err)
+
+	config, err := m.TLSConfig(cert)
+	require.NoError(t, err)
+	assert.Len(t, config.Certificates, 1)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	assert.True(t, config.RootCAs.Equal(pool), "the config trusts the generated CA")
+
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{Roots: config.RootCAs, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+}
+
+func TestHTTP2Client(t *testing.T) {
+	m := serve(t,
+		NewService("secure").TLS(TLSConfig{}).On("GET", "/proto").Reply(200).Service(),
+		NewService("cleartext").Protocols(ProtocolH2C).On("GET", "/proto").Reply(200).Service(),
+	)
+
+	client, err := m.HTTP2Client()
+	require.NoError(t, err)
+
+	for _, name := range []string{"secure", "cleartext"} {
+		resp, err := client.Get(m.URL(name) + "/proto")
+		require.NoError(t, err, name)
+		_ = resp.Body.Close()
+		assert.Equal(t, "HTTP/2.0", resp.Proto, name)
+
+		requests := m.Requests(name)
+		require.Len(t, requests, 1, name)
+		assert.Equal(t, "HTTP/2.0", requests[0].Protocol, name)
+	}
+
+	_, err = http.Get(m.URL("cleartext") + "/proto")
+	assert.Error(t, err, "h2c only services refuse HTTP/1.1")
+}
@@ -1,5 +1,107 @@
 package gockapi
 
-import "github.com/JTGlez/gockapi/internal/config_reader"
+import (
+	"time"
+
+	"github.com/JTGlez/gockapi/internal/config_reader"
+	"github.com/JTGlez/gockapi/internal/server/request_journal"
+)
 
 type EndpointConfig = config_reader.EndpointConfig
+
+type ScenarioConfig = config_reader.ScenarioConfig
+
+// FallbackConfig answers the requests no endpoint of a service matches, with
+// a fixed response or by forwarding them to an upstream through Proxy.
+type FallbackConfig = config_reader.FallbackConfig
+
+// ProxyConfig forwards requests to an upstream, rewriting their headers.
+type ProxyConfig = config_reader.ProxyConfig
+
+// FaultConfig makes a response fail at the connection level, on every request
+// or on the share of requests given by Probability. A zero Probability fires
+// on every request, like 1.
+type FaultConfig = config_reader.FaultConfig
+
+// Fault types for FaultConfig.Type.
+const (
+	FaultNone            = config_reader.FaultNone
+	FaultConnectionReset = config_reader.FaultConnectionReset
+	FaultEmptyReply      = config_reader.FaultEmptyReply
+	FaultCloseMidBody    = config_reader.FaultCloseMidBody
+	FaultMalformedChunk  = config_reader.FaultMalformedChunk
+	FaultRandomGarbage   = config_reader.FaultRandomGarbage
+	FaultSlowBody        = config_reader.FaultSlowBody
+)
+
+// DelayConfig is a fixed response delay or a distribution delays are drawn
+// from.
+type DelayConfig = config_reader.DelayConfig
+
+// FixedDelay returns a delay of exactly d, e.g. for EndpointConfig.Delay.
+func FixedDelay(d time.Duration) *DelayConfig {
+	return config_reader.FixedDelay(d)
+}
+
+// Delay distributions for DelayConfig.Distribution.
+const (
+	DelayFixed       = config_reader.DelayFixed
+	DelayUniform     = config_reader.DelayUniform
+	DelayNormal      = config_reader.DelayNormal
+	DelayLogNormal   = config_reader.DelayLogNormal
+	DelayPercentiles = config_reader.DelayPercentiles
+)
+
+// TLSConfig serves a service over HTTPS, with certificate files or with a
+// certificate issued at startup by a generated CA.
+type TLSConfig = config_reader.TLSConfig
+
+// ClientAuthConfig asks HTTPS clients for a certificate and sets the errors
+// returned when it is missing or invalid.
+type ClientAuthConfig = config_reader.ClientAuthConfig
+
+// ErrorResponseConfig is a fixed error response.
+type ErrorResponseConfig = config_reader.ErrorResponseConfig
+
+// ClientCertFields are the client certificate fields stubs can match.
+var ClientCertFields = config_reader.ClientCertFields
+
+// Protocols for ServiceBuilder.Protocols.
+const (
+	ProtocolHTTP1 = config_reader.ProtocolHTTP1
+	ProtocolHTTP2 = config_reader.ProtocolHTTP2
+	ProtocolH2C   = config_reader.ProtocolH2C
+)
+
+// GRPCConfig serves a service over gRPC, answering the methods declared in a
+// protobuf descriptor set with the responses configured for them.
+type GRPCConfig = config_reader.GRPCConfig
+
+// GRPCResponseConfig answers a gRPC call with a message or a stream of
+// messages, a status and metadata.
+type GRPCResponseConfig = config_reader.GRPCResponseConfig
+
+// GRPCMessageConfig is a message of a server stream.
+type GRPCMessageConfig = config_reader.GRPCMessageConfig
+
+// RecordedRequest is a request received by a running mock server.
+type RecordedRequest = request_journal.RecordedRequest
+
+// RecordedResponse is the response a running mock server sent for a recorded
+// request.
+type RecordedResponse = request_journal.RecordedResponse
+
+// TestingT is the subset of testing.TB used by the assertion helpers.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// TestingTB is the subset of testing.TB used by Start.
+type TestingTB interface {
+	TestingT
+	Cleanup(func())
+	Fatalf(format string, args ...any)
+	Logf(format string, args ...any)
+	Failed() bool
+}